@@ -15,13 +15,17 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"time"
 
 	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"k8s.io/client-go/rest"
 
 	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
 	"github.com/operator-framework/operator-sdk/internal/scorecard"
@@ -54,22 +58,12 @@ func main() {
 		log.Fatal(err.Error())
 	}
 
-	var result scapiv1alpha3.TestStatus
-
-	switch entrypoint[0] {
-	case tests.OLMBundleValidationTest:
-		result = tests.BundleValidationTest(scorecard.PodBundleRoot, metadata)
-	case tests.OLMCRDsHaveValidationTest:
-		result = tests.CRDsHaveValidationTest(bundle)
-	case tests.OLMCRDsHaveResourcesTest:
-		result = tests.CRDsHaveResourcesTest(bundle)
-	case tests.OLMSpecDescriptorsTest:
-		result = tests.SpecDescriptorsTest(bundle)
-	case tests.OLMStatusDescriptorsTest:
-		result = tests.StatusDescriptorsTest(bundle)
-	case tests.BasicCheckSpecTest:
-		result = tests.CheckSpecTest(bundle)
-	default:
+	result, ok := tests.Run(entrypoint[0], scorecard.PodBundleRoot, bundle, metadata)
+	if !ok && isClusterTest(entrypoint[0]) {
+		result = runClusterTest(entrypoint[0], bundle)
+		ok = true
+	}
+	if !ok {
 		result = printValidTests()
 	}
 
@@ -81,6 +75,39 @@ func main() {
 
 }
 
+// isClusterTest returns true if name is the name of a test that deploys CRs to the cluster.
+func isClusterTest(name string) bool {
+	for _, clusterTest := range tests.ClusterNames {
+		if name == clusterTest {
+			return true
+		}
+	}
+	return false
+}
+
+// runClusterTest runs the test named name against the cluster the pod runs in,
+// waiting for each CR for SCORECARD_CR_TIMEOUT if set.
+func runClusterTest(name string, bundle *apimanifests.Bundle) scapiv1alpha3.TestStatus {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+
+	timeout := tests.DefaultCRTimeout
+	if value := os.Getenv("SCORECARD_CR_TIMEOUT"); value != "" {
+		if timeout, err = time.ParseDuration(value); err != nil {
+			log.Fatalf("invalid SCORECARD_CR_TIMEOUT: %v", err)
+		}
+	}
+
+	cluster, err := tests.NewCluster(cfg, os.Getenv("SCORECARD_NAMESPACE"), timeout)
+	if err != nil {
+		log.Fatal(err.Error())
+	}
+	result, _ := tests.RunCluster(context.Background(), name, *cluster, bundle)
+	return result
+}
+
 // printValidTests will print out full list of test names to give a hint to the end user on what the valid tests are
 func printValidTests() scapiv1alpha3.TestStatus {
 	result := scapiv1alpha3.TestResult{}
@@ -88,13 +115,7 @@ func printValidTests() scapiv1alpha3.TestStatus {
 	result.Errors = make([]string, 0)
 	result.Suggestions = make([]string, 0)
 
-	str := fmt.Sprintf("Valid tests for this image include: %s, %s, %s, %s, %s, %s",
-		tests.OLMBundleValidationTest,
-		tests.OLMCRDsHaveValidationTest,
-		tests.OLMCRDsHaveResourcesTest,
-		tests.OLMSpecDescriptorsTest,
-		tests.OLMStatusDescriptorsTest,
-		tests.BasicCheckSpecTest)
+	str := fmt.Sprintf("Valid tests for this image include: %s", strings.Join(append(tests.Names, tests.ClusterNames...), ", "))
 	result.Errors = append(result.Errors, str)
 	return scapiv1alpha3.TestStatus{
 		Results: []scapiv1alpha3.TestResult{result},
@@ -35,6 +35,7 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/ansible/events"
 	"github.com/operator-framework/operator-sdk/internal/ansible/predicate"
 	"github.com/operator-framework/operator-sdk/internal/ansible/runner"
+	"github.com/operator-framework/operator-sdk/internal/drain"
 )
 
 var log = logf.Log.WithName("ansible-controller")
@@ -52,6 +53,7 @@ type Options struct {
 	WatchClusterScopedResources bool
 	MaxConcurrentReconciles     int
 	Selector                    metav1.LabelSelector
+	Drain                       *drain.Tracker
 }
 
 // Add - Creates a new ansible operator controller and adds it to the manager
@@ -63,6 +65,7 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 	}
 	eventHandlers := append(options.EventHandlers, events.NewLoggingEventHandler(options.LoggingLevel))
 
+	controllerName := fmt.Sprintf("%v-controller", strings.ToLower(options.GVK.Kind))
 	aor := &AnsibleOperatorReconciler{
 		Client:           mgr.GetClient(),
 		GVK:              options.GVK,
@@ -72,6 +75,8 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 		ManageStatus:     options.ManageStatus,
 		AnsibleDebugLogs: options.AnsibleDebugLogs,
 		APIReader:        mgr.GetAPIReader(),
+		EventRecorder:    mgr.GetEventRecorderFor(controllerName),
+		Drain:            options.Drain,
 	}
 
 	scheme := mgr.GetScheme()
@@ -89,7 +94,7 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 	}
 
 	//Create new controller runtime controller and set the controller to watch GVK.
-	c, err := controller.New(fmt.Sprintf("%v-controller", strings.ToLower(options.GVK.Kind)), mgr,
+	c, err := controller.New(controllerName, mgr,
 		controller.Options{
 			Reconciler:              aor,
 			MaxConcurrentReconciles: options.MaxConcurrentReconciles,
@@ -101,7 +106,8 @@ func Add(mgr manager.Manager, options Options) *controller.Controller {
 
 	// Set up predicates.
 	predicates := []ctrlpredicate.Predicate{
-		ctrlpredicate.Or(ctrlpredicate.GenerationChangedPredicate{}, libpredicate.NoGenerationPredicate{}),
+		ctrlpredicate.Or(ctrlpredicate.GenerationChangedPredicate{}, libpredicate.NoGenerationPredicate{},
+			predicate.NewAnnotationChangedPredicate(PausedAnnotation)),
 	}
 	filterPredicate, err := predicate.NewResourceFilterPredicate(options.Selector)
 	if err != nil {
@@ -31,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -41,6 +42,7 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/kubeconfig"
 	"github.com/operator-framework/operator-sdk/internal/ansible/runner"
 	"github.com/operator-framework/operator-sdk/internal/ansible/runner/eventapi"
+	"github.com/operator-framework/operator-sdk/internal/drain"
 )
 
 const (
@@ -48,6 +50,11 @@ const (
 	// To use create a CR with an annotation "ansible.sdk.operatorframework.io/reconcile-period: 30s" or some other valid
 	// Duration. This will override the operators/or controllers reconcile period for that particular CR.
 	ReconcilePeriodAnnotation = "ansible.sdk.operatorframework.io/reconcile-period"
+
+	// PausedAnnotation - annotation used by a user to pause the reconciliation of the CR, e.g. while fixing it by hand.
+	// To use set the annotation "ansible.sdk.operatorframework.io/paused: true" on a CR. While paused, the playbook or
+	// role is only run to handle the CR's finalizer. Removing the annotation resumes reconciliation immediately.
+	PausedAnnotation = "ansible.sdk.operatorframework.io/paused"
 )
 
 // AnsibleOperatorReconciler - object to reconcile runner requests
@@ -60,6 +67,9 @@ type AnsibleOperatorReconciler struct {
 	ReconcilePeriod  time.Duration
 	ManageStatus     bool
 	AnsibleDebugLogs bool
+	EventRecorder    record.EventRecorder
+	// Drain tracks runs so that they can finish when the operator stops.
+	Drain *drain.Tracker
 }
 
 // Reconcile - handle the event.
@@ -117,6 +127,39 @@ func (r *AnsibleOperatorReconciler) Reconcile(ctx context.Context, request recon
 		return reconcile.Result{}, nil
 	}
 
+	if !deleted && isPaused(u) {
+		logger.Info("Reconciliation is paused, skipping reconciliation")
+		// The Event and the condition are only recorded when reconciliation
+		// becomes paused, not on every later reconcile while it is.
+		if !hasPausedCondition(u) {
+			r.EventRecorder.Event(u, "Normal", ansiblestatus.PausedReason, ansiblestatus.PausedMessage)
+			if r.ManageStatus {
+				if errmark := r.markPaused(u, request.NamespacedName); errmark != nil {
+					logger.Error(errmark, "Unable to update the status to mark cr as paused")
+					return reconcile.Result{}, errmark
+				}
+			}
+		}
+		// The CR is requeued when the annotation is removed.
+		return reconcile.Result{}, nil
+	}
+
+	// The run is drained rather than killed when the operator stops, so it uses
+	// runCtx in place of ctx from here on.
+	runCtx, endRun, started := r.Drain.Begin(ctx, func(ctx context.Context) {
+		logger.Info("Interrupted run that did not finish before the operator stopped")
+		if r.ManageStatus {
+			if errmark := r.markInterrupted(ctx, request.NamespacedName); errmark != nil {
+				logger.Error(errmark, "Unable to update the status to mark cr as interrupted")
+			}
+		}
+	})
+	if !started {
+		logger.Info("Operator is stopping, skipping reconciliation")
+		return reconcile.Result{Requeue: true}, nil
+	}
+	defer endRun()
+
 	spec := u.Object["spec"]
 	_, ok := spec.(map[string]interface{})
 	// Need to handle cases where there is no spec.
@@ -157,7 +200,7 @@ func (r *AnsibleOperatorReconciler) Reconcile(ctx context.Context, request recon
 			logger.Error(err, "Failed to remove generated kubeconfig file")
 		}
 	}()
-	result, err := r.Runner.Run(ident, u, kc.Name())
+	result, err := r.Runner.Run(runCtx, ident, u, kc.Name())
 	if err != nil {
 		errmark := r.markError(u, request.NamespacedName, "Unable to run reconciliation")
 		if errmark != nil {
@@ -209,6 +252,12 @@ func (r *AnsibleOperatorReconciler) Reconcile(ctx context.Context, request recon
 		}
 	}
 
+	// The status was marked by the interrupt, which may have raced with the
+	// last events of the run.
+	if runCtx.Err() != nil {
+		return reconcile.Result{}, errors.New("run was interrupted by the operator stopping")
+	}
+
 	// To print the stats of the task
 	printEventStats(statusEvent)
 
@@ -232,7 +281,7 @@ func (r *AnsibleOperatorReconciler) Reconcile(ctx context.Context, request recon
 
 	// Need to get the unstructured object after the Ansible runner finishes.
 	// This needs to hit the API server to retrieve updates.
-	err = r.APIReader.Get(ctx, request.NamespacedName, u)
+	err = r.APIReader.Get(runCtx, request.NamespacedName, u)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			return reconcile.Result{}, nil
@@ -299,6 +348,72 @@ func (r *AnsibleOperatorReconciler) printAnsibleResult(result runner.RunResult)
 	}
 }
 
+// isPaused returns the boolean representation of the paused annotation, which
+// is false if the annotation is not set or cannot be parsed.
+func isPaused(u *unstructured.Unstructured) bool {
+	paused, ok := u.GetAnnotations()[PausedAnnotation]
+	if !ok {
+		return false
+	}
+	value, err := strconv.ParseBool(paused)
+	if err != nil {
+		logf.Log.WithName("reconciler").Info("Could not parse annotation as a boolean",
+			"annotation", PausedAnnotation, "value", paused)
+		return false
+	}
+	return value
+}
+
+// hasPausedCondition returns true if the status of u records that reconciliation is paused.
+func hasPausedCondition(u *unstructured.Unstructured) bool {
+	c := ansiblestatus.GetCondition(getStatus(u), ansiblestatus.PausedConditionType)
+	return c != nil && c.Status == v1.ConditionTrue
+}
+
+// markPaused - used to alert the user that reconciliation of the CR is paused.
+func (r *AnsibleOperatorReconciler) markPaused(u *unstructured.Unstructured,
+	namespacedName types.NamespacedName) error {
+
+	// Get the latest resource to prevent updating a stale status.
+	if err := r.APIReader.Get(context.TODO(), namespacedName, u); err != nil {
+		return err
+	}
+	crStatus := getStatus(u)
+	c := ansiblestatus.NewCondition(
+		ansiblestatus.PausedConditionType,
+		v1.ConditionTrue,
+		nil,
+		ansiblestatus.PausedReason,
+		ansiblestatus.PausedMessage,
+	)
+	ansiblestatus.SetCondition(&crStatus, *c)
+	u.Object["status"] = crStatus.GetJSONMap()
+
+	return r.Client.Status().Update(context.TODO(), u)
+}
+
+// markInterrupted - used to alert the user that the run was interrupted by the operator stopping. It gets its own copy
+// of the resource, since the interrupted run may still be using u.
+func (r *AnsibleOperatorReconciler) markInterrupted(ctx context.Context, namespacedName types.NamespacedName) error {
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(r.GVK)
+	if err := r.APIReader.Get(ctx, namespacedName, u); err != nil {
+		return err
+	}
+	crStatus := getStatus(u)
+	c := ansiblestatus.NewCondition(
+		ansiblestatus.RunningConditionType,
+		v1.ConditionFalse,
+		nil,
+		ansiblestatus.InterruptedReason,
+		ansiblestatus.InterruptedMessage,
+	)
+	ansiblestatus.SetCondition(&crStatus, *c)
+	u.Object["status"] = crStatus.GetJSONMap()
+
+	return r.Client.Status().Update(ctx, u)
+}
+
 func (r *AnsibleOperatorReconciler) markRunning(u *unstructured.Unstructured,
 	namespacedName types.NamespacedName) error {
 
@@ -314,6 +429,8 @@ func (r *AnsibleOperatorReconciler) markRunning(u *unstructured.Unstructured,
 		errCond.Status = v1.ConditionFalse
 		ansiblestatus.SetCondition(&crStatus, *errCond)
 	}
+	// Reconciliation is no longer paused if it is running.
+	ansiblestatus.RemoveCondition(&crStatus, ansiblestatus.PausedConditionType)
 	// If the condition is currently running, making sure that the values are correct.
 	// If they are the same a no-op, if they are different then it is a good thing we
 	// are updating it.
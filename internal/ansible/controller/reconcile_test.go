@@ -23,6 +23,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	fakeclient "sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
@@ -33,6 +34,7 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/ansible/runner"
 	"github.com/operator-framework/operator-sdk/internal/ansible/runner/eventapi"
 	"github.com/operator-framework/operator-sdk/internal/ansible/runner/fake"
+	"github.com/operator-framework/operator-sdk/internal/drain"
 )
 
 func TestReconcile(t *testing.T) {
@@ -487,6 +489,65 @@ func TestReconcile(t *testing.T) {
 			},
 			ShouldError: true,
 		},
+		{
+			Name:            "paused",
+			GVK:             gvk,
+			ReconcilePeriod: 5 * time.Second,
+			ManageStatus:    true,
+			Runner: &fake.Runner{
+				JobEvents: []eventapi.JobEvent{
+					eventapi.JobEvent{
+						Event:   eventapi.EventPlaybookOnStats,
+						Created: eventapi.EventTime{Time: eventTime},
+					},
+				},
+			},
+			Client: fakeclient.NewClientBuilder().WithObjects(&unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":      "reconcile",
+						"namespace": "default",
+						"annotations": map[string]interface{}{
+							controller.PausedAnnotation: "true",
+						},
+					},
+					"apiVersion": "operator-sdk/v1beta1",
+					"kind":       "Testing",
+					"spec":       map[string]interface{}{},
+				},
+			}).Build(),
+			Result: reconcile.Result{},
+			Request: reconcile.Request{
+				NamespacedName: types.NamespacedName{
+					Name:      "reconcile",
+					Namespace: "default",
+				},
+			},
+			ExpectedObject: &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"metadata": map[string]interface{}{
+						"name":      "reconcile",
+						"namespace": "default",
+						"annotations": map[string]interface{}{
+							controller.PausedAnnotation: "true",
+						},
+					},
+					"apiVersion": "operator-sdk/v1beta1",
+					"kind":       "Testing",
+					"spec":       map[string]interface{}{},
+					"status": map[string]interface{}{
+						"conditions": []interface{}{
+							map[string]interface{}{
+								"status":  "True",
+								"type":    "Paused",
+								"message": "Reconciliation is paused until the paused annotation is removed",
+								"reason":  "Paused",
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			Name:            "no manage status",
 			GVK:             gvk,
@@ -544,6 +605,7 @@ func TestReconcile(t *testing.T) {
 				EventHandlers:   tc.EventHandlers,
 				ReconcilePeriod: tc.ReconcilePeriod,
 				ManageStatus:    tc.ManageStatus,
+				EventRecorder:   record.NewFakeRecorder(10),
 			}
 			result, err := aor.Reconcile(context.TODO(), tc.Request)
 			if err != nil && !tc.ShouldError {
@@ -600,3 +662,124 @@ func TestReconcile(t *testing.T) {
 		})
 	}
 }
+
+func TestReconcilePaused(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "operator-sdk", Version: "v1beta1", Kind: "Testing"}
+	c := fakeclient.NewClientBuilder().WithObjects(&unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"name":      "reconcile",
+				"namespace": "default",
+				"annotations": map[string]interface{}{
+					controller.PausedAnnotation: "true",
+				},
+			},
+			"apiVersion": "operator-sdk/v1beta1",
+			"kind":       "Testing",
+			"spec":       map[string]interface{}{},
+		},
+	}).Build()
+	recorder := record.NewFakeRecorder(10)
+	aor := &controller.AnsibleOperatorReconciler{
+		GVK:           gvk,
+		Runner:        &fake.Runner{},
+		Client:        c,
+		APIReader:     c,
+		ManageStatus:  true,
+		EventRecorder: recorder,
+	}
+
+	// The Event and the condition are only recorded once while paused.
+	request := reconcile.Request{NamespacedName: types.NamespacedName{Name: "reconcile", Namespace: "default"}}
+	for i := 0; i < 2; i++ {
+		if _, err := aor.Reconcile(context.TODO(), request); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if len(recorder.Events) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(recorder.Events))
+	}
+}
+
+// blockingRunner runs until it is interrupted.
+type blockingRunner struct {
+	started chan struct{}
+}
+
+func (r *blockingRunner) Run(ctx context.Context, _ string, _ *unstructured.Unstructured, _ string) (runner.RunResult,
+	error) {
+	events := make(chan eventapi.JobEvent)
+	go func() {
+		<-ctx.Done()
+		close(events)
+	}()
+	close(r.started)
+	return blockingResult(events), nil
+}
+
+type blockingResult <-chan eventapi.JobEvent
+
+func (r blockingResult) Events() <-chan eventapi.JobEvent { return r }
+func (r blockingResult) Stdout() (string, error)          { return "", nil }
+
+func (r *blockingRunner) GetFinalizer() (string, bool) {
+	return "", false
+}
+
+func TestReconcileInterrupted(t *testing.T) {
+	gvk := schema.GroupVersionKind{
+		Kind:    "Testing",
+		Group:   "operator-sdk",
+		Version: "v1beta1",
+	}
+	key := types.NamespacedName{Name: "interrupted", Namespace: "default"}
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	cr.SetName(key.Name)
+	cr.SetNamespace(key.Namespace)
+	c := fakeclient.NewClientBuilder().WithObjects(cr).Build()
+
+	tracker := drain.NewTracker(10 * time.Millisecond)
+	r := &blockingRunner{started: make(chan struct{})}
+	aor := &controller.AnsibleOperatorReconciler{
+		GVK:           gvk,
+		Runner:        r,
+		Client:        c,
+		APIReader:     c,
+		ManageStatus:  true,
+		EventRecorder: record.NewFakeRecorder(10),
+		Drain:         tracker,
+	}
+	reconciled := make(chan error)
+	go func() {
+		_, err := aor.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		reconciled <- err
+	}()
+	<-r.started
+
+	stopped, stop := context.WithCancel(context.TODO())
+	stop()
+	if err := tracker.Start(stopped); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if err := <-reconciled; err == nil {
+		t.Fatal("Expected an error from the interrupted reconcile")
+	}
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(gvk)
+	if err := c.Get(context.TODO(), key, actual); err != nil {
+		t.Fatalf("Failed to get object: (%v)", err)
+	}
+	sMap, _ := actual.Object["status"].(map[string]interface{})
+	cond := ansiblestatus.GetCondition(ansiblestatus.CreateFromMap(sMap), ansiblestatus.RunningConditionType)
+	if cond == nil || cond.Status != "False" || cond.Reason != ansiblestatus.InterruptedReason {
+		t.Fatalf("Expected Running condition to be interrupted, got %v", cond)
+	}
+
+	// Runs are not started once the operator is stopping.
+	result, err := aor.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	if err != nil || !result.Requeue {
+		t.Fatalf("Expected requeue without error, got %v, %v", result, err)
+	}
+}
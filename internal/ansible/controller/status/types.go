@@ -93,6 +93,8 @@ const (
 	RunningConditionType ConditionType = "Running"
 	// FailureConditionType - condition type of failure.
 	FailureConditionType ConditionType = "Failure"
+	// PausedConditionType - condition type of paused.
+	PausedConditionType ConditionType = "Paused"
 )
 
 // Condition - the condition for the ansible operator.
@@ -28,6 +28,10 @@ const (
 	FailedReason = "Failed"
 	// UnknownFailedReason - Condition is unknown
 	UnknownFailedReason = "Unknown"
+	// PausedReason - Condition is paused due to the paused annotation
+	PausedReason = "Paused"
+	// InterruptedReason - Condition is not running because the run was interrupted by the operator stopping
+	InterruptedReason = "Interrupted"
 )
 
 const (
@@ -35,6 +39,10 @@ const (
 	RunningMessage = "Running reconciliation"
 	// SuccessfulMessage - message for successful reason.
 	SuccessfulMessage = "Awaiting next reconciliation"
+	// PausedMessage - message for paused reason.
+	PausedMessage = "Reconciliation is paused until the paused annotation is removed"
+	// InterruptedMessage - message for interrupted reason.
+	InterruptedMessage = "Reconciliation was interrupted by the operator stopping, and will be retried"
 )
 
 // NewCondition -  condition
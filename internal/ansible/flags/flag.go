@@ -33,6 +33,8 @@ type Flags struct {
 	AnsibleCollectionsPath  string
 	MetricsAddress          string
 	ProbeAddr               string
+	ReconcileStallThreshold time.Duration
+	GracefulShutdownTimeout time.Duration
 	LeaderElectionID        string
 	LeaderElectionNamespace string
 	AnsibleArgs             string
@@ -91,6 +93,17 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 		":6789",
 		"The address the probe endpoint binds to.",
 	)
+	flagSet.DurationVar(&f.ReconcileStallThreshold,
+		"reconcile-stall-threshold",
+		10*time.Minute,
+		"How long a single reconcile may run before the readiness probe fails. Set to 0 to disable the check.",
+	)
+	flagSet.DurationVar(&f.GracefulShutdownTimeout,
+		"graceful-shutdown-timeout",
+		20*time.Second,
+		"How long to wait for in-flight reconciles to finish when the operator stops, before they are interrupted. "+
+			"The pod's termination grace period must be at least 10s longer.",
+	)
 	flagSet.BoolVar(&f.EnableLeaderElection,
 		"enable-leader-election",
 		false,
@@ -54,3 +54,35 @@ func (r resourceFilterPredicate) Delete(e event.DeleteEvent) bool {
 func (r resourceFilterPredicate) Generic(e event.GenericEvent) bool {
 	return r.eventFilter(e.Object.GetLabels())
 }
+
+type annotationChangedPredicate struct {
+	predicate.Funcs
+	key string
+}
+
+// NewAnnotationChangedPredicate returns a predicate that passes update events
+// that set, change or remove the annotation key.
+func NewAnnotationChangedPredicate(key string) predicate.Predicate {
+	return annotationChangedPredicate{key: key}
+}
+
+func (p annotationChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil || e.ObjectNew == nil {
+		return false
+	}
+	oldValue, oldOk := e.ObjectOld.GetAnnotations()[p.key]
+	newValue, newOk := e.ObjectNew.GetAnnotations()[p.key]
+	return oldOk != newOk || oldValue != newValue
+}
+
+func (p annotationChangedPredicate) Create(e event.CreateEvent) bool {
+	return false
+}
+
+func (p annotationChangedPredicate) Delete(e event.DeleteEvent) bool {
+	return false
+}
+
+func (p annotationChangedPredicate) Generic(e event.GenericEvent) bool {
+	return false
+}
@@ -47,7 +47,7 @@ type cacheResponseHandler struct {
 	next              http.Handler
 	informerCache     cache.Cache
 	restMapper        meta.RESTMapper
-	watchedNamespaces map[string]interface{}
+	watchedNamespaces *NamespaceSet
 	cMap              *controllermap.ControllerMap
 	injectOwnerRef    bool
 	apiResources      *apiResources
@@ -186,11 +186,9 @@ func (c *cacheResponseHandler) skipCacheLookup(r *k8sRequest.RequestInfo, gvk sc
 		}
 	}
 	// check if resource doesn't exist in watched namespaces
-	// if watchedNamespaces[""] exists then we are watching all namespaces
+	// if watchedNamespaces contains "" then we are watching all namespaces
 	// and want to continue
-	_, allNsPresent := c.watchedNamespaces[metav1.NamespaceAll]
-	_, reqNsPresent := c.watchedNamespaces[r.Namespace]
-	if !allNsPresent && !reqNsPresent {
+	if !c.watchedNamespaces.Contains(r.Namespace) {
 		return true
 	}
 
@@ -0,0 +1,56 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// healthCheckTimeout is how long the proxy and API server have to respond to
+// a health check.
+const healthCheckTimeout = 5 * time.Second
+
+// HealthChecker returns a check that the proxy listening on address and port
+// can reach the API server, by requesting the API server's version through it.
+func HealthChecker(address string, port int) healthz.Checker {
+	client := &http.Client{Timeout: healthCheckTimeout}
+	url := fmt.Sprintf("http://%s/version", net.JoinHostPort(address, strconv.Itoa(port)))
+	return func(req *http.Request) error {
+		versionReq, err := http.NewRequestWithContext(req.Context(), http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(versionReq)
+		if err != nil {
+			return fmt.Errorf("proxy is not reachable: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+			return fmt.Errorf("proxy failed to reach the API server: %s: %s", resp.Status,
+				strings.TrimSpace(string(body)))
+		}
+		return nil
+	}
+}
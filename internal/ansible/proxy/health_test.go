@@ -0,0 +1,55 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthChecker(t *testing.T) {
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/version", r.URL.Path)
+		w.WriteHeader(status)
+		fmt.Fprint(w, "upstream unavailable")
+	}))
+	defer server.Close()
+	u, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	port, err := strconv.Atoi(u.Port())
+	require.NoError(t, err)
+	check := HealthChecker(u.Hostname(), port)
+	req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+
+	assert.NoError(t, check(req))
+
+	status = http.StatusBadGateway
+	err = check(req)
+	require.Error(t, err)
+	assert.Equal(t, "proxy failed to reach the API server: 502 Bad Gateway: upstream unavailable", err.Error())
+
+	server.Close()
+	err = check(req)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "proxy is not reachable")
+}
@@ -24,7 +24,6 @@ import (
 
 	"github.com/operator-framework/operator-lib/handler"
 	"k8s.io/apimachinery/pkg/api/meta"
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -41,7 +40,7 @@ type injectOwnerReferenceHandler struct {
 	next              http.Handler
 	cMap              *controllermap.ControllerMap
 	restMapper        meta.RESTMapper
-	watchedNamespaces map[string]interface{}
+	watchedNamespaces *NamespaceSet
 	apiResources      *apiResources
 }
 
@@ -167,13 +166,11 @@ func (i *injectOwnerReferenceHandler) ServeHTTP(w http.ResponseWriter, req *http
 
 			// add watch for resource
 			// check if resource doesn't exist in watched namespaces
-			// if watchedNamespaces[""] exists then we are watching all namespaces
+			// if watchedNamespaces contains "" then we are watching all namespaces
 			// and want to continue
 			// This is making sure we are not attempting to watch a resource outside of the
 			// namespaces that the cache can watch.
-			_, allNsPresent := i.watchedNamespaces[metav1.NamespaceAll]
-			_, reqNsPresent := i.watchedNamespaces[r.Namespace]
-			if allNsPresent || reqNsPresent {
+			if i.watchedNamespaces.Contains(r.Namespace) {
 				err = addWatchToController(*owner, i.cMap, data, i.restMapper, addOwnerRef)
 				if err != nil {
 					m := "could not add watch to controller"
@@ -0,0 +1,61 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceSet is a set of watched namespaces that can be updated while the
+// proxy is running.
+type NamespaceSet struct {
+	mu         sync.RWMutex
+	namespaces map[string]struct{}
+}
+
+// NewNamespaceSet returns a set of the given namespaces. A set that contains
+// metav1.NamespaceAll contains every namespace.
+func NewNamespaceSet(namespaces ...string) *NamespaceSet {
+	s := &NamespaceSet{namespaces: map[string]struct{}{}}
+	for _, ns := range namespaces {
+		s.namespaces[ns] = struct{}{}
+	}
+	return s
+}
+
+// Add adds namespace to the set.
+func (s *NamespaceSet) Add(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.namespaces[namespace] = struct{}{}
+}
+
+// Remove removes namespace from the set.
+func (s *NamespaceSet) Remove(namespace string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.namespaces, namespace)
+}
+
+// Contains returns true if namespace, or every namespace, is in the set.
+func (s *NamespaceSet) Contains(namespace string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, allNsPresent := s.namespaces[metav1.NamespaceAll]
+	_, nsPresent := s.namespaces[namespace]
+	return allNsPresent || nsPresent
+}
@@ -0,0 +1,36 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamespaceSet(t *testing.T) {
+	s := NewNamespaceSet("a")
+	assert.True(t, s.Contains("a"))
+	assert.False(t, s.Contains("b"))
+
+	s.Add("b")
+	s.Remove("a")
+	assert.False(t, s.Contains("a"))
+	assert.True(t, s.Contains("b"))
+
+	all := NewNamespaceSet("")
+	assert.True(t, all.Contains("a"))
+	assert.True(t, all.Contains(""))
+}
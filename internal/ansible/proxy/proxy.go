@@ -82,9 +82,12 @@ type Options struct {
 	RESTMapper        meta.RESTMapper
 	ControllerMap     *controllermap.ControllerMap
 	WatchedNamespaces []string
-	DisableCache      bool
-	OwnerInjection    bool
-	LogRequests       bool
+	// WatchedNamespaceSet, if set, is used instead of WatchedNamespaces, and
+	// can be updated while the proxy is running.
+	WatchedNamespaceSet *NamespaceSet
+	DisableCache        bool
+	OwnerInjection      bool
+	LogRequests         bool
 }
 
 // Run will start a proxy server in a go routine that returns on the error
@@ -101,14 +104,12 @@ func Run(done chan error, o Options) error {
 	if o.ControllerMap == nil {
 		return fmt.Errorf("failed to get controller map from options")
 	}
-	if o.WatchedNamespaces == nil {
-		return fmt.Errorf("failed to get list of watched namespaces from options")
-	}
-
-	watchedNamespaceMap := make(map[string]interface{})
-	// Convert string list to map
-	for _, ns := range o.WatchedNamespaces {
-		watchedNamespaceMap[ns] = nil
+	watchedNamespaceSet := o.WatchedNamespaceSet
+	if watchedNamespaceSet == nil {
+		if o.WatchedNamespaces == nil {
+			return fmt.Errorf("failed to get list of watched namespaces from options")
+		}
+		watchedNamespaceSet = NewNamespaceSet(o.WatchedNamespaces...)
 	}
 
 	// Create apiResources and
@@ -153,7 +154,7 @@ func Run(done chan error, o Options) error {
 			next:              server.Handler,
 			cMap:              o.ControllerMap,
 			restMapper:        o.RESTMapper,
-			watchedNamespaces: watchedNamespaceMap,
+			watchedNamespaces: watchedNamespaceSet,
 			apiResources:      resources,
 		}
 	} else {
@@ -171,7 +172,7 @@ func Run(done chan error, o Options) error {
 			next:              server.Handler,
 			informerCache:     o.Cache,
 			restMapper:        o.RESTMapper,
-			watchedNamespaces: watchedNamespaceMap,
+			watchedNamespaces: watchedNamespaceSet,
 			cMap:              o.ControllerMap,
 			injectOwnerRef:    o.OwnerInjection,
 			apiResources:      resources,
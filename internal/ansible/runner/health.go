@@ -0,0 +1,52 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// healthCheckTimeout is how long each command run by CheckAnsibleRunner may take.
+const healthCheckTimeout = 10 * time.Second
+
+// CheckAnsibleRunner checks that the ansible-runner command, and Ansible in
+// the Python environment that runs it, are usable.
+func CheckAnsibleRunner(req *http.Request) error {
+	for _, command := range []string{"ansible-runner", "ansible-playbook"} {
+		if err := checkVersionCommand(req.Context(), command); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func checkVersionCommand(ctx context.Context, command string) error {
+	path, err := exec.LookPath(command)
+	if err != nil {
+		return fmt.Errorf("%s not found: %v", command, err)
+	}
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, path, "--version").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s --version failed: %v: %s", path, err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package runner
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCheckAnsibleRunner(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ansible-runner-health")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := os.Getenv("PATH")
+	defer os.Setenv("PATH", path)
+	if err := os.Setenv("PATH", dir); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest("GET", "/readyz", nil)
+
+	writeScript := func(name, script string) {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := CheckAnsibleRunner(req); err == nil || !strings.Contains(err.Error(), "ansible-runner not found") {
+		t.Fatalf("expected ansible-runner not found error, got %v", err)
+	}
+
+	writeScript("ansible-runner", "echo 1.4.6")
+	writeScript("ansible-playbook", "echo \"ModuleNotFoundError: No module named 'ansible'\"; exit 1")
+	err = CheckAnsibleRunner(req)
+	if err == nil || !strings.Contains(err.Error(), "No module named 'ansible'") {
+		t.Fatalf("expected ansible-playbook error, got %v", err)
+	}
+
+	writeScript("ansible-playbook", "echo 2.9.15")
+	if err := CheckAnsibleRunner(req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+}
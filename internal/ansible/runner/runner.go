@@ -15,6 +15,8 @@
 package runner
 
 import (
+	"bytes"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -23,6 +25,7 @@ import (
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -51,9 +54,9 @@ const (
 )
 
 // Runner - a runnable that should take the parameters and name and namespace
-// and run the correct code.
+// and run the correct code. The run is interrupted when the context is done.
 type Runner interface {
-	Run(string, *unstructured.Unstructured, string) (RunResult, error)
+	Run(context.Context, string, *unstructured.Unstructured, string) (RunResult, error)
 	GetFinalizer() (string, bool)
 }
 
@@ -177,7 +180,7 @@ type runner struct {
 	ansibleArgs         string
 }
 
-func (r *runner) Run(ident string, u *unstructured.Unstructured, kubeconfig string) (RunResult, error) {
+func (r *runner) Run(ctx context.Context, ident string, u *unstructured.Unstructured, kubeconfig string) (RunResult, error) {
 	timer := metrics.ReconcileTimer(r.GVK.String())
 	defer timer.ObserveDuration()
 
@@ -258,8 +261,10 @@ func (r *runner) Run(ident string, u *unstructured.Unstructured, kubeconfig stri
 		dc.Env = append(dc.Env, fmt.Sprintf("K8S_AUTH_KUBECONFIG=%s", kubeconfig),
 			fmt.Sprintf("KUBECONFIG=%s", kubeconfig))
 
-		output, err := dc.CombinedOutput()
-		if err != nil {
+		output, err := runUntilDone(ctx, dc)
+		if ctx.Err() != nil {
+			logger.Info("Ansible-runner was interrupted", "output", string(output))
+		} else if err != nil {
 			logger.Error(err, string(output))
 		} else {
 			logger.Info("Ansible-runner exited successfully")
@@ -293,6 +298,29 @@ func (r *runner) Run(ident string, u *unstructured.Unstructured, kubeconfig stri
 	}, nil
 }
 
+// runUntilDone runs cmd like cmd.CombinedOutput, but stops it when ctx is
+// done. ansible-runner is sent SIGTERM rather than killed, so that it can stop
+// the playbook and write its artifacts.
+func runUntilDone(ctx context.Context, cmd *exec.Cmd) ([]byte, error) {
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	exited := make(chan struct{})
+	defer close(exited)
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = cmd.Process.Signal(syscall.SIGTERM)
+		case <-exited:
+		}
+	}()
+	err := cmd.Wait()
+	return output.Bytes(), err
+}
+
 func (r *runner) isFinalizerRun(u *unstructured.Unstructured) bool {
 	finalizersSet := r.Finalizer != nil && u.GetFinalizers() != nil
 	// The resource is deleted and our finalizer is present, we need to run the finalizer
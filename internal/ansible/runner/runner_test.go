@@ -15,11 +15,13 @@
 package runner
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"reflect"
 	"testing"
+	"time"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -244,3 +246,24 @@ func TestAnsibleVerbosityString(t *testing.T) {
 		}
 	}
 }
+
+func TestRunUntilDone(t *testing.T) {
+	output, err := runUntilDone(context.TODO(), exec.Command("sh", "-c", "echo out; echo err >&2"))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if string(output) != "out\nerr\n" {
+		t.Fatalf("Unexpected output %q", output)
+	}
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = runUntilDone(ctx, exec.Command("sleep", "30"))
+	if err == nil {
+		t.Fatal("Expected an error from the interrupted command")
+	}
+	if elapsed := time.Since(start); elapsed > 10*time.Second {
+		t.Fatalf("Command was not interrupted, ran for %v", elapsed)
+	}
+}
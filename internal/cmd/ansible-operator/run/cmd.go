@@ -21,6 +21,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,12 +42,25 @@ import (
 	"github.com/operator-framework/operator-sdk/internal/ansible/proxy/controllermap"
 	"github.com/operator-framework/operator-sdk/internal/ansible/runner"
 	"github.com/operator-framework/operator-sdk/internal/ansible/watches"
+	"github.com/operator-framework/operator-sdk/internal/drain"
+	"github.com/operator-framework/operator-sdk/internal/health"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	sdkVersion "github.com/operator-framework/operator-sdk/internal/version"
 )
 
 var log = logf.Log.WithName("cmd")
 
+const (
+	// proxyAddress and proxyPort are where the proxy that Ansible uses to
+	// reach the API server listens.
+	proxyAddress = "localhost"
+	proxyPort    = 8888
+
+	// runnerCheckInterval is how often the readiness probe checks that
+	// ansible-runner is usable once it has passed.
+	runnerCheckInterval = 5 * time.Minute
+)
+
 func printVersion() {
 	log.Info("Version",
 		"Go Version", runtime.Version(),
@@ -102,7 +116,7 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 	// TODO: probably should expose the host & port as an environment variables
 	options := manager.Options{
 		MetricsBindAddress:         f.MetricsAddress,
-		HealthProbeBindAddress:     f.ProbeAddr,
+		HealthProbeBindAddress:     "0", // served by the health probe server below
 		LeaderElection:             f.EnableLeaderElection,
 		LeaderElectionID:           f.LeaderElectionID,
 		LeaderElectionResourceLock: resourcelock.ConfigMapsResourceLock,
@@ -110,9 +124,26 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		ClientBuilder:              manager.NewClientBuilder(),
 	}
 
+	// The namespaces that the proxy caches responses and adds dependent watches for.
+	watchedNamespaces := proxy.NewNamespaceSet()
 	namespace, found := os.LookupEnv(k8sutil.WatchNamespaceEnvVar)
 	log = log.WithValues("Namespace", namespace)
-	if found {
+	namespaceSelector, err := k8sutil.GetWatchNamespaceSelector(namespace)
+	if err != nil {
+		log.Error(err, "Failed to get namespace selector.")
+		os.Exit(1)
+	}
+	if namespaceSelector != nil {
+		log.Info("Watching namespaces matching selector.", "Selector", namespaceSelector.String())
+		options.NewCache = k8sutil.NamespaceSelectorCacheBuilder(namespaceSelector,
+			func(ns string, watched bool) {
+				if watched {
+					watchedNamespaces.Add(ns)
+				} else {
+					watchedNamespaces.Remove(ns)
+				}
+			})
+	} else if found {
 		if namespace == metav1.NamespaceAll {
 			log.Info("Watching all namespaces.")
 			options.Namespace = metav1.NamespaceAll
@@ -130,6 +161,11 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 			k8sutil.WatchNamespaceEnvVar))
 		options.Namespace = metav1.NamespaceAll
 	}
+	if namespaceSelector == nil {
+		for _, ns := range strings.Split(namespace, ",") {
+			watchedNamespaces.Add(ns)
+		}
+	}
 
 	err = setAnsibleEnvVars(f)
 	if err != nil {
@@ -138,18 +174,19 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 	}
 
 	// Create a new manager to provide shared dependencies and start components
+	// In-flight reconciles are drained, and then interrupted, before the
+	// manager returns.
+	tracker := drain.NewTracker(f.GracefulShutdownTimeout)
+	shutdownTimeout := tracker.ShutdownTimeout()
+	options.GracefulShutdownTimeout = &shutdownTimeout
+
 	mgr, err := manager.New(cfg, options)
 	if err != nil {
 		log.Error(err, "Failed to create a new manager.")
 		os.Exit(1)
 	}
-
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		log.Error(err, "Unable to set up health check")
-		os.Exit(1)
-	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		log.Error(err, "Unable to set up ready check")
+	if err := mgr.Add(tracker); err != nil {
+		log.Error(err, "Failed to add drain tracker to the manager.")
 		os.Exit(1)
 	}
 
@@ -174,6 +211,7 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 			MaxConcurrentReconciles: w.MaxConcurrentReconciles,
 			ReconcilePeriod:         w.ReconcilePeriod,
 			Selector:                w.Selector,
+			Drain:                   tracker,
 		})
 		if ctr == nil {
 			log.Error(fmt.Errorf("failed to add controller for GVK %v", w.GroupVersionKind.String()), "")
@@ -188,33 +226,48 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		}, w.Blacklist)
 	}
 
-	// todo: remove when a upper version be bumped
-	err = mgr.AddHealthzCheck("ping", healthz.Ping)
-	if err != nil {
-		log.Error(err, "Failed to add Healthz check.")
-	}
-
 	done := make(chan error)
 
 	// start the proxy
 	err = proxy.Run(done, proxy.Options{
-		Address:           "localhost",
-		Port:              8888,
-		KubeConfig:        mgr.GetConfig(),
-		Cache:             mgr.GetCache(),
-		RESTMapper:        mgr.GetRESTMapper(),
-		ControllerMap:     cMap,
-		OwnerInjection:    f.InjectOwnerRef,
-		WatchedNamespaces: []string{namespace},
+		Address:             proxyAddress,
+		Port:                proxyPort,
+		KubeConfig:          mgr.GetConfig(),
+		Cache:               mgr.GetCache(),
+		RESTMapper:          mgr.GetRESTMapper(),
+		ControllerMap:       cMap,
+		OwnerInjection:      f.InjectOwnerRef,
+		WatchedNamespaceSet: watchedNamespaces,
 	})
 	if err != nil {
 		log.Error(err, "Error starting proxy.")
 		os.Exit(1)
 	}
 
+	probes := health.NewServer(f.ProbeAddr)
+	probes.Healthz.AddCheck("ping", healthz.Ping)
+	probes.Readyz.AddCheck("ping", healthz.Ping)
+	probes.Readyz.AddCheck("cache-sync", health.CacheSynced(mgr.GetCache()))
+	probes.Readyz.AddCheck("proxy", proxy.HealthChecker(proxyAddress, proxyPort))
+	probes.Readyz.AddCheck("ansible-runner", health.Cached(runner.CheckAnsibleRunner, runnerCheckInterval))
+	if f.ReconcileStallThreshold > 0 {
+		// A long reconcile only takes the operator out of service, since
+		// restarting it would not make the reconcile finish any sooner.
+		probes.Readyz.AddCheck("reconcile-queues",
+			health.QueuesNotStalled(crmetrics.Registry, f.ReconcileStallThreshold))
+	}
+
+	ctx := signals.SetupSignalHandler()
+	// start the probes, which are served while the manager's caches sync
+	go func() {
+		if err := probes.Start(ctx); err != nil {
+			done <- fmt.Errorf("health probe server: %w", err)
+		}
+	}()
+
 	// start the operator
 	go func() {
-		done <- mgr.Start(signals.SetupSignalHandler())
+		done <- mgr.Start(ctx)
 	}()
 
 	// wait for either to finish
@@ -20,9 +20,11 @@ import (
 	"os"
 	"runtime"
 	"strings"
+	"time"
 
 	"github.com/spf13/cobra"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
@@ -32,18 +34,27 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
 	crmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
+	crwebhook "sigs.k8s.io/controller-runtime/pkg/webhook"
 
+	"github.com/operator-framework/operator-sdk/internal/drain"
+	"github.com/operator-framework/operator-sdk/internal/health"
 	"github.com/operator-framework/operator-sdk/internal/helm/controller"
 	"github.com/operator-framework/operator-sdk/internal/helm/flags"
 	"github.com/operator-framework/operator-sdk/internal/helm/metrics"
+	"github.com/operator-framework/operator-sdk/internal/helm/postrender"
 	"github.com/operator-framework/operator-sdk/internal/helm/release"
 	"github.com/operator-framework/operator-sdk/internal/helm/watches"
+	"github.com/operator-framework/operator-sdk/internal/helm/webhook"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 	sdkVersion "github.com/operator-framework/operator-sdk/internal/version"
 )
 
 var log = logf.Log.WithName("cmd")
 
+// chartCheckInterval is how often the readiness probe reloads the charts of
+// the watches file once they have loaded.
+const chartCheckInterval = time.Minute
+
 func printVersion() {
 	log.Info("Version",
 		"Go Version", runtime.Version(),
@@ -97,7 +108,7 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 	// Set default manager options
 	options := manager.Options{
 		MetricsBindAddress:         f.MetricsAddress,
-		HealthProbeBindAddress:     f.ProbeAddr,
+		HealthProbeBindAddress:     "0", // served by the health probe server below
 		LeaderElection:             f.EnableLeaderElection,
 		LeaderElectionID:           f.LeaderElectionID,
 		LeaderElectionResourceLock: resourcelock.ConfigMapsResourceLock,
@@ -107,7 +118,15 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 
 	namespace, found := os.LookupEnv(k8sutil.WatchNamespaceEnvVar)
 	log = log.WithValues("Namespace", namespace)
-	if found {
+	namespaceSelector, err := k8sutil.GetWatchNamespaceSelector(namespace)
+	if err != nil {
+		log.Error(err, "Failed to get namespace selector.")
+		os.Exit(1)
+	}
+	if namespaceSelector != nil {
+		log.Info("Watching namespaces matching selector.", "Selector", namespaceSelector.String())
+		options.NewCache = k8sutil.NamespaceSelectorCacheBuilder(namespaceSelector)
+	} else if found {
 		if namespace == metav1.NamespaceAll {
 			log.Info("Watching all namespaces.")
 			options.Namespace = metav1.NamespaceAll
@@ -126,18 +145,19 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		options.Namespace = metav1.NamespaceAll
 	}
 
+	// In-flight reconciles are drained, and then interrupted, before the
+	// manager returns.
+	tracker := drain.NewTracker(f.GracefulShutdownTimeout)
+	shutdownTimeout := tracker.ShutdownTimeout()
+	options.GracefulShutdownTimeout = &shutdownTimeout
+
 	mgr, err := manager.New(cfg, options)
 	if err != nil {
 		log.Error(err, "Failed to create a new manager.")
 		os.Exit(1)
 	}
-
-	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
-		log.Error(err, "Unable to set up health check")
-		os.Exit(1)
-	}
-	if err := mgr.AddReadyzCheck("readyz", healthz.Ping); err != nil {
-		log.Error(err, "Unable to set up ready check")
+	if err := mgr.Add(tracker); err != nil {
+		log.Error(err, "Failed to add drain tracker to the manager.")
 		os.Exit(1)
 	}
 
@@ -146,16 +166,54 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		log.Error(err, "Failed to create new manager factories.")
 		os.Exit(1)
 	}
+	releaseNamers := make(map[schema.GroupVersionKind]webhook.ReleaseNamer, len(ws))
+	chartDirs := make([]string, 0, len(ws))
 	for _, w := range ws {
+		chartDirs = append(chartDirs, w.ChartDir)
+
+		reconcilePeriod := f.ReconcilePeriod
+		if w.ReconcilePeriod != nil {
+			reconcilePeriod = w.ReconcilePeriod.Duration
+		}
+		maxConcurrentReconciles := f.MaxConcurrentReconciles
+		if w.MaxConcurrentReconciles != nil {
+			maxConcurrentReconciles = *w.MaxConcurrentReconciles
+		}
+		factoryOpts := []release.ManagerFactoryOption{
+			release.WithReleaseNameTemplate(w.ReleaseNameTemplate),
+			release.WithMaxHistory(w.MaxHistory),
+			release.WithManagedCRDs(w.ManageCRDs),
+			release.WithStorage(w.Storage),
+		}
+		if w.PostRenderer != nil {
+			pr, err := postrender.New(*w.PostRenderer)
+			if err != nil {
+				log.Error(err, "Failed to create post-renderer.")
+				os.Exit(1)
+			}
+			factoryOpts = append(factoryOpts, release.WithPostRenderer(pr))
+		}
+		factory := release.NewManagerFactory(mgr, w.ChartDir, factoryOpts...)
+		releaseNamers[w.GroupVersionKind] = factory
+
 		// Register the controller with the factory.
 		err := controller.Add(mgr, controller.WatchOptions{
 			Namespace:               namespace,
+			NamespaceSelector:       namespaceSelector,
 			GVK:                     w.GroupVersionKind,
-			ManagerFactory:          release.NewManagerFactory(mgr, w.ChartDir),
-			ReconcilePeriod:         f.ReconcilePeriod,
+			ManagerFactory:          factory,
+			ReconcilePeriod:         reconcilePeriod,
 			WatchDependentResources: *w.WatchDependentResources,
 			OverrideValues:          w.OverrideValues,
-			MaxConcurrentReconciles: f.MaxConcurrentReconciles,
+			MaxConcurrentReconciles: maxConcurrentReconciles,
+			Selector:                w.Selector,
+			Blacklist:               w.Blacklist,
+			MaxHistory:              w.MaxHistory,
+			CheckReadiness:          w.CheckReadiness,
+			ActionOptions:           w.ActionOptions,
+
+			WatchClusterScopedResources: w.WatchClusterScopedResources,
+			Drain:                       tracker,
 		})
 		if err != nil {
 			log.Error(err, "Failed to add manager factory to controller.")
@@ -163,8 +221,40 @@ func run(cmd *cobra.Command, f *flags.Flags) {
 		}
 	}
 
+	if f.EnableReleaseNameWebhook {
+		mgr.GetWebhookServer().Register(webhook.ReleaseNamePath, &crwebhook.Admission{
+			Handler: &webhook.ReleaseNameValidator{
+				Client:        mgr.GetAPIReader(),
+				ReleaseNamers: releaseNamers,
+			},
+		})
+		log.Info("Serving release name webhook", "path", webhook.ReleaseNamePath)
+	}
+
+	probes := health.NewServer(f.ProbeAddr)
+	probes.Healthz.AddCheck("ping", healthz.Ping)
+	probes.Readyz.AddCheck("ping", healthz.Ping)
+	probes.Readyz.AddCheck("cache-sync", health.CacheSynced(mgr.GetCache()))
+	probes.Readyz.AddCheck("charts", health.Cached(release.ChartsLoadable(chartDirs...), chartCheckInterval))
+	if f.ReconcileStallThreshold > 0 {
+		// A long reconcile only takes the operator out of service, since
+		// restarting it would not make the reconcile finish any sooner.
+		probes.Readyz.AddCheck("reconcile-queues",
+			health.QueuesNotStalled(crmetrics.Registry, f.ReconcileStallThreshold))
+	}
+
+	ctx := signals.SetupSignalHandler()
+	// Serve the probes while the manager's caches sync, which the manager
+	// waits for before starting its own runnables.
+	go func() {
+		if err := probes.Start(ctx); err != nil {
+			log.Error(err, "Health probe server exited non-zero.")
+			os.Exit(1)
+		}
+	}()
+
 	// Start the Cmd
-	if err = mgr.Start(signals.SetupSignalHandler()); err != nil {
+	if err = mgr.Start(ctx); err != nil {
 		log.Error(err, "Manager exited non-zero.")
 		os.Exit(1)
 	}
@@ -23,7 +23,6 @@ import (
 	"sort"
 	"strings"
 
-	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
 	"github.com/operator-framework/operator-registry/pkg/lib/bundle"
 	"sigs.k8s.io/yaml"
 
@@ -241,7 +240,7 @@ func (c bundleCmd) runManifests() (err error) {
 }
 
 // writeScorecardConfig writes cfg to dir at the hard-coded config path 'config.yaml'.
-func writeScorecardConfig(dir string, cfg v1alpha3.Configuration) error {
+func writeScorecardConfig(dir string, cfg scorecard.Configuration) error {
 	if cfg.Metadata.Name == "" {
 		return nil
 	}
@@ -19,8 +19,10 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io/ioutil"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
@@ -36,11 +38,16 @@ import (
 )
 
 type scorecardCmd struct {
+	artifactsDir   string
+	baseline       string
 	bundle         string
 	config         string
 	kubeconfig     string
 	namespace      string
 	outputFormat   string
+	outputFile     string
+	parallelism    int
+	runner         string
 	selector       string
 	serviceAccount string
 	list           bool
@@ -60,7 +67,16 @@ func NewCmd() *cobra.Command {
 one argument, either a bundle image or directory containing manifests and metadata.
 If the argument holds an image tag, it must be present remotely.`,
 		PreRunE: func(cmd *cobra.Command, args []string) (err error) {
-			return c.validate(args)
+			if err := c.validate(args); err != nil {
+				return err
+			}
+			if err := c.validateOutputFormat(); err != nil {
+				return err
+			}
+			if err := c.validateRunner(); err != nil {
+				return err
+			}
+			return c.validateBaseline()
 		},
 		RunE: func(cmd *cobra.Command, args []string) (err error) {
 			c.bundle = args[0]
@@ -73,7 +89,18 @@ If the argument holds an image tag, it must be present remotely.`,
 	scorecardCmd.Flags().StringVarP(&c.config, "config", "c", "", "path to scorecard config file")
 	scorecardCmd.Flags().StringVarP(&c.namespace, "namespace", "n", "", "namespace to run the test images in")
 	scorecardCmd.Flags().StringVarP(&c.outputFormat, "output", "o", "text",
-		"Output format for results. Valid values: text, json")
+		"Output format for results. Valid values: text, json, junit, tap")
+	scorecardCmd.Flags().StringVar(&c.outputFile, "output-file", "",
+		"Write results to this file in the format set by --output, and print them as text to standard output")
+	scorecardCmd.Flags().StringVar(&c.artifactsDir, "artifacts-dir", "",
+		"Directory to save the logs, status and events of test pods to before they are cleaned up, with an index.json summary")
+	scorecardCmd.Flags().StringVar(&c.baseline, "baseline", "",
+		"JSON output of a previous run to compare results with. If set, only tests that passed in it "+
+			"and now fail, or new tests that fail, cause a non-zero exit code")
+	scorecardCmd.Flags().StringVar(&c.runner, "runner", "pod",
+		"How to run tests. Valid values: pod (in pods in a cluster), local (built-in tests only, in-process without a cluster)")
+	scorecardCmd.Flags().IntVar(&c.parallelism, "parallelism", 0,
+		"Maximum number of tests of a parallel stage to run at once. 0 means no limit")
 	scorecardCmd.Flags().StringVarP(&c.serviceAccount, "service-account", "s", "default",
 		"Service account to use for tests")
 	scorecardCmd.Flags().BoolVarP(&c.list, "list", "L", false,
@@ -86,26 +113,66 @@ If the argument holds an image tag, it must be present remotely.`,
 	return scorecardCmd
 }
 
-func (c *scorecardCmd) printOutput(output v1alpha3.TestList) error {
-	switch c.outputFormat {
+// outputFormats are the valid values of --output.
+var outputFormats = []string{"text", "json", "junit", "tap"}
+
+// runners are the valid values of --runner.
+var runners = []string{"pod", "local"}
+
+func (c *scorecardCmd) printOutput(o scorecard.Scorecard, output v1alpha3.TestList) error {
+	out, err := formatOutput(c.outputFormat, o, output)
+	if err != nil {
+		return err
+	}
+	if c.outputFile == "" {
+		_, err = os.Stdout.Write(out)
+		return err
+	}
+	if err := ioutil.WriteFile(c.outputFile, out, 0644); err != nil {
+		return fmt.Errorf("error writing output file: %v", err)
+	}
+	_, err = os.Stdout.Write(formatText(output))
+	return err
+}
+
+// formatOutput returns output, the output of o, in format.
+func formatOutput(format string, o scorecard.Scorecard, output v1alpha3.TestList) ([]byte, error) {
+	switch format {
 	case "text":
-		if len(output.Items) == 0 {
-			fmt.Println("0 tests selected")
-			return nil
-		}
-		for _, test := range output.Items {
-			fmt.Println(test.MarshalText())
-		}
+		return formatText(output), nil
 	case "json":
 		bytes, err := json.MarshalIndent(output, "", "  ")
 		if err != nil {
-			return fmt.Errorf("marshal json error: %v", err)
+			return nil, fmt.Errorf("marshal json error: %v", err)
+		}
+		return append(bytes, '\n'), nil
+	case "junit":
+		bytes, err := o.MarshalJUnit(output)
+		if err != nil {
+			return nil, fmt.Errorf("marshal junit error: %v", err)
 		}
-		fmt.Printf("%s\n", string(bytes))
+		return bytes, nil
+	case "tap":
+		bytes, err := o.MarshalTAP(output)
+		if err != nil {
+			return nil, fmt.Errorf("marshal tap error: %v", err)
+		}
+		return bytes, nil
 	default:
-		return fmt.Errorf("invalid output format selected")
+		return nil, fmt.Errorf("invalid output format selected")
 	}
-	return nil
+}
+
+func formatText(output v1alpha3.TestList) []byte {
+	if len(output.Items) == 0 {
+		return []byte("0 tests selected\n")
+	}
+	var sb strings.Builder
+	for _, test := range output.Items {
+		sb.WriteString(scorecard.MarshalText(test))
+		sb.WriteString("\n")
+	}
+	return []byte(sb.String())
 }
 
 func (c *scorecardCmd) run() (err error) {
@@ -128,6 +195,7 @@ func (c *scorecardCmd) run() (err error) {
 
 	o := scorecard.Scorecard{
 		SkipCleanup: c.skipCleanup,
+		Parallelism: c.parallelism,
 	}
 
 	configPath := c.config
@@ -148,24 +216,21 @@ func (c *scorecardCmd) run() (err error) {
 		return fmt.Errorf("could not parse selector %w", err)
 	}
 
+	var baseline v1alpha3.TestList
+	if c.baseline != "" {
+		if baseline, err = scorecard.LoadBaseline(c.baseline); err != nil {
+			return err
+		}
+	}
+
 	var scorecardTests v1alpha3.TestList
 	if c.list {
 		scorecardTests = o.List()
 	} else {
-		runner := scorecard.PodTestRunner{
-			ServiceAccount: c.serviceAccount,
-			Namespace:      scorecard.GetKubeNamespace(c.kubeconfig, c.namespace),
-			BundlePath:     c.bundle,
-			BundleMetadata: metadata,
+		if o.TestRunner, err = c.newTestRunner(metadata); err != nil {
+			return err
 		}
 
-		// Only get the client if running tests.
-		if runner.Client, err = scorecard.GetKubeClient(c.kubeconfig); err != nil {
-			return fmt.Errorf("error getting kubernetes client: %w", err)
-		}
-
-		o.TestRunner = &runner
-
 		ctx, cancel := context.WithTimeout(context.Background(), c.waitTime)
 		defer cancel()
 
@@ -175,20 +240,69 @@ func (c *scorecardCmd) run() (err error) {
 		}
 	}
 
-	if err := c.printOutput(scorecardTests); err != nil {
+	if err := c.printOutput(o, scorecardTests); err != nil {
 		log.Fatal(err)
 	}
 
+	if c.baseline != "" {
+		comparison := o.Compare(baseline, scorecardTests)
+		if err := c.printComparison(comparison); err != nil {
+			log.Fatal(err)
+		}
+		if comparison.HasRegressions() {
+			os.Exit(1)
+		}
+		return nil
+	}
+
 	if hasFailingTest(scorecardTests) {
 		os.Exit(1)
 	}
 	return nil
 }
 
+// printComparison prints comparison after the text output of a run, or to
+// standard error if results are printed to standard output in another format.
+func (c *scorecardCmd) printComparison(comparison scorecard.Comparison) error {
+	out := os.Stdout
+	if c.outputFormat != "text" && c.outputFile == "" {
+		out = os.Stderr
+	}
+	_, err := out.WriteString(comparison.MarshalText())
+	return err
+}
+
+// newTestRunner returns the test runner selected by --runner for the bundle with metadata.
+func (c *scorecardCmd) newTestRunner(metadata registryutil.Labels) (scorecard.TestRunner, error) {
+	if c.runner == "local" {
+		return &scorecard.LocalTestRunner{
+			BundlePath:     c.bundle,
+			BundleMetadata: metadata,
+		}, nil
+	}
+
+	runner := scorecard.PodTestRunner{
+		ServiceAccount: c.serviceAccount,
+		Namespace:      scorecard.GetKubeNamespace(c.kubeconfig, c.namespace),
+		BundlePath:     c.bundle,
+		BundleMetadata: metadata,
+		ArtifactsDir:   c.artifactsDir,
+	}
+
+	// Only get the client if running tests in pods.
+	var err error
+	if runner.Client, err = scorecard.GetKubeClient(c.kubeconfig); err != nil {
+		return nil, fmt.Errorf("error getting kubernetes client: %w", err)
+	}
+	return &runner, nil
+}
+
+// hasFailingTest returns true if a test of list did not pass. Skipped tests were
+// skipped because of another test that did not pass, or a dependency on it.
 func hasFailingTest(list v1alpha3.TestList) bool {
 	for _, t := range list.Items {
 		for _, r := range t.Status.Results {
-			if r.State != v1alpha3.PassState {
+			if r.State != v1alpha3.PassState && r.State != scorecard.SkippedState {
 				return true
 			}
 		}
@@ -200,6 +314,38 @@ func (c *scorecardCmd) validate(args []string) error {
 	if len(args) != 1 {
 		return fmt.Errorf("a bundle image or directory argument is required")
 	}
+	if c.parallelism < 0 {
+		return fmt.Errorf("--parallelism must not be negative")
+	}
+	return nil
+}
+
+func (c *scorecardCmd) validateOutputFormat() error {
+	for _, format := range outputFormats {
+		if c.outputFormat == format {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid output format %q, must be one of: %s", c.outputFormat,
+		strings.Join(outputFormats, ", "))
+}
+
+func (c *scorecardCmd) validateRunner() error {
+	for _, runner := range runners {
+		if c.runner == runner {
+			if runner == "local" && c.artifactsDir != "" {
+				return fmt.Errorf("--artifacts-dir cannot be set with --runner local, which runs no pods")
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid runner %q, must be one of: %s", c.runner, strings.Join(runners, ", "))
+}
+
+func (c *scorecardCmd) validateBaseline() error {
+	if c.baseline != "" && c.list {
+		return fmt.Errorf("--baseline cannot be set with --list, which runs no tests")
+	}
 	return nil
 }
 
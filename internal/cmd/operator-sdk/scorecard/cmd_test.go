@@ -15,8 +15,16 @@
 package scorecard
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"k8s.io/apimachinery/pkg/labels"
+
+	"github.com/operator-framework/operator-sdk/internal/scorecard"
 )
 
 var _ = Describe("Running the scorecard command", func() {
@@ -46,6 +54,26 @@ var _ = Describe("Running the scorecard command", func() {
 			Expect(flag.Shorthand).To(Equal("o"))
 			Expect(flag.DefValue).To(Equal("text"))
 
+			flag = cmd.Flags().Lookup("output-file")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(""))
+
+			flag = cmd.Flags().Lookup("parallelism")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("0"))
+
+			flag = cmd.Flags().Lookup("artifacts-dir")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(""))
+
+			flag = cmd.Flags().Lookup("baseline")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal(""))
+
+			flag = cmd.Flags().Lookup("runner")
+			Expect(flag).NotTo(BeNil())
+			Expect(flag.DefValue).To(Equal("pod"))
+
 			flag = cmd.Flags().Lookup("service-account")
 			Expect(flag).NotTo(BeNil())
 			Expect(flag.Shorthand).To(Equal("s"))
@@ -86,5 +114,123 @@ var _ = Describe("Running the scorecard command", func() {
 			err := cmd.validate([]string{input})
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("fails if parallelism is negative", func() {
+			cmd.parallelism = -1
+			Expect(cmd.validate([]string{"cherry"})).To(MatchError("--parallelism must not be negative"))
+		})
+	})
+
+	Describe("hasFailingTest", func() {
+		newList := func(states ...v1alpha3.State) v1alpha3.TestList {
+			list := v1alpha3.NewTestList()
+			for _, state := range states {
+				test := v1alpha3.NewTest()
+				test.Status.Results = []v1alpha3.TestResult{{State: state}}
+				list.Items = append(list.Items, test)
+			}
+			return list
+		}
+
+		It("ignores passed and skipped tests", func() {
+			Expect(hasFailingTest(newList(v1alpha3.PassState, scorecard.SkippedState))).To(BeFalse())
+		})
+
+		It("finds failed and errored tests", func() {
+			Expect(hasFailingTest(newList(v1alpha3.PassState, v1alpha3.FailState))).To(BeTrue())
+			Expect(hasFailingTest(newList(v1alpha3.ErrorState, scorecard.SkippedState))).To(BeTrue())
+		})
+	})
+
+	Describe("validateOutputFormat", func() {
+		It("succeeds for each output format", func() {
+			for _, format := range []string{"text", "json", "junit", "tap"} {
+				cmd := scorecardCmd{outputFormat: format}
+				Expect(cmd.validateOutputFormat()).To(Succeed())
+			}
+		})
+
+		It("fails for an unknown output format", func() {
+			cmd := scorecardCmd{outputFormat: "xml"}
+			Expect(cmd.validateOutputFormat()).To(MatchError(ContainSubstring(`invalid output format "xml"`)))
+		})
+	})
+
+	Describe("validateRunner", func() {
+		It("succeeds for each runner", func() {
+			for _, runner := range []string{"pod", "local"} {
+				cmd := scorecardCmd{runner: runner}
+				Expect(cmd.validateRunner()).To(Succeed())
+			}
+		})
+
+		It("fails for the local runner with an artifacts directory", func() {
+			cmd := scorecardCmd{runner: "local", artifactsDir: "artifacts"}
+			Expect(cmd.validateRunner()).To(MatchError(ContainSubstring("--artifacts-dir cannot be set with --runner local")))
+		})
+
+		It("fails for an unknown runner", func() {
+			cmd := scorecardCmd{runner: "docker"}
+			Expect(cmd.validateRunner()).To(MatchError(ContainSubstring(`invalid runner "docker"`)))
+		})
+	})
+
+	Describe("validateBaseline", func() {
+		It("succeeds with a baseline", func() {
+			cmd := scorecardCmd{baseline: "baseline.json"}
+			Expect(cmd.validateBaseline()).To(Succeed())
+		})
+
+		It("fails with a baseline and --list", func() {
+			cmd := scorecardCmd{baseline: "baseline.json", list: true}
+			Expect(cmd.validateBaseline()).To(MatchError(ContainSubstring("--baseline cannot be set with --list")))
+		})
+	})
+
+	Describe("newTestRunner", func() {
+		It("returns a local test runner without a cluster", func() {
+			cmd := scorecardCmd{runner: "local", bundle: "testdata/bundle", kubeconfig: "does-not-exist"}
+			runner, err := cmd.newTestRunner(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(runner).To(Equal(&scorecard.LocalTestRunner{BundlePath: "testdata/bundle"}))
+		})
+	})
+
+	Describe("printOutput", func() {
+		var (
+			o      scorecard.Scorecard
+			output v1alpha3.TestList
+			dir    string
+		)
+		BeforeEach(func() {
+			test := v1alpha3.NewTest()
+			test.Spec = v1alpha3.TestConfiguration{Image: "scorecard-test", Labels: map[string]string{"test": "foo"}}
+			test.Status.Results = []v1alpha3.TestResult{{Name: "foo", State: v1alpha3.PassState}}
+			o = scorecard.Scorecard{
+				Config: scorecard.Configuration{Stages: []scorecard.StageConfiguration{
+					{Tests: []scorecard.TestConfiguration{{TestConfiguration: test.Spec}}},
+				}},
+				Selector: labels.Everything(),
+			}
+			output = v1alpha3.NewTestList()
+			output.Items = []v1alpha3.Test{test}
+
+			var err error
+			dir, err = ioutil.TempDir("", "scorecard-output")
+			Expect(err).NotTo(HaveOccurred())
+		})
+		AfterEach(func() {
+			Expect(os.RemoveAll(dir)).To(Succeed())
+		})
+
+		It("writes the output format to the output file", func() {
+			path := filepath.Join(dir, "results.xml")
+			cmd := scorecardCmd{outputFormat: "junit", outputFile: path}
+			Expect(cmd.printOutput(o, output)).To(Succeed())
+
+			expected, err := o.MarshalJUnit(output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ioutil.ReadFile(path)).To(Equal(expected))
+		})
 	})
 })
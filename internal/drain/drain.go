@@ -0,0 +1,165 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package drain lets in-flight reconciles of Ansible- and Helm-based operators
+// finish when the manager stops, instead of being killed mid-run.
+package drain
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("drain")
+
+// InterruptTimeout is how long work that is still running at the end of the
+// grace period has to record that it was interrupted.
+const InterruptTimeout = 10 * time.Second
+
+// Tracker is a manager runnable that tracks in-flight work. When the manager
+// stops, the tracker refuses new work and waits for in-flight work to finish
+// for up to a grace period. Work that is still running after that is
+// interrupted.
+//
+// A nil *Tracker tracks nothing and never refuses work.
+type Tracker struct {
+	gracePeriod time.Duration
+
+	mu       sync.Mutex
+	draining bool
+	work     map[*work]struct{}
+	idle     *sync.Cond
+}
+
+type work struct {
+	cancel    context.CancelFunc
+	interrupt func(context.Context)
+}
+
+// NewTracker returns a tracker that waits for up to gracePeriod for in-flight
+// work to finish when the manager stops.
+func NewTracker(gracePeriod time.Duration) *Tracker {
+	t := &Tracker{
+		gracePeriod: gracePeriod,
+		work:        map[*work]struct{}{},
+	}
+	t.idle = sync.NewCond(&t.mu)
+	return t
+}
+
+// ShutdownTimeout is the time the manager must give its runnables to stop for
+// in-flight work to be drained, and interrupted if it does not finish.
+func (t *Tracker) ShutdownTimeout() time.Duration {
+	return t.gracePeriod + InterruptTimeout
+}
+
+// Begin registers work that is about to start. It returns false if the manager
+// is stopping, in which case the work must not be started. Otherwise, the work
+// should use the returned context in place of ctx, which is done as soon as
+// the manager stops, and end must be called when the work is done. A nil
+// Tracker returns ctx itself.
+//
+// If the work is still running at the end of the grace period, its context is
+// cancelled and interrupt is called with a context bounded by
+// InterruptTimeout. interrupt should record that the work was interrupted, so
+// that whoever picks the work up next finds an accurate status.
+func (t *Tracker) Begin(ctx context.Context, interrupt func(ctx context.Context)) (workCtx context.Context,
+	end func(), ok bool) {
+	if t == nil {
+		return ctx, func() {}, true
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.draining {
+		return nil, nil, false
+	}
+	workCtx, cancel := context.WithCancel(context.Background())
+	w := &work{cancel: cancel, interrupt: interrupt}
+	t.work[w] = struct{}{}
+	var once sync.Once
+	return workCtx, func() {
+		once.Do(func() {
+			cancel()
+			t.mu.Lock()
+			defer t.mu.Unlock()
+			delete(t.work, w)
+			if len(t.work) == 0 {
+				t.idle.Broadcast()
+			}
+		})
+	}, true
+}
+
+// Start waits until ctx is done, then drains in-flight work. It implements
+// manager.Runnable, so that the manager waits for the drain before it returns.
+func (t *Tracker) Start(ctx context.Context) error {
+	<-ctx.Done()
+
+	t.mu.Lock()
+	t.draining = true
+	inFlight := len(t.work)
+	t.mu.Unlock()
+	if inFlight == 0 {
+		return nil
+	}
+
+	log.Info("Waiting for in-flight reconciles to finish", "count", inFlight, "gracePeriod", t.gracePeriod)
+	if t.wait(t.gracePeriod) {
+		log.Info("In-flight reconciles finished")
+		return nil
+	}
+
+	t.mu.Lock()
+	interrupted := make([]*work, 0, len(t.work))
+	for w := range t.work {
+		interrupted = append(interrupted, w)
+	}
+	t.mu.Unlock()
+	log.Info("Interrupting reconciles that did not finish within the grace period", "count", len(interrupted))
+
+	interruptCtx, cancel := context.WithTimeout(context.Background(), InterruptTimeout)
+	defer cancel()
+	var wg sync.WaitGroup
+	for _, w := range interrupted {
+		wg.Add(1)
+		go func(w *work) {
+			defer wg.Done()
+			w.cancel()
+			w.interrupt(interruptCtx)
+		}(w)
+	}
+	wg.Wait()
+	return nil
+}
+
+// wait waits for up to timeout for all work to end, and returns whether it did.
+func (t *Tracker) wait(timeout time.Duration) bool {
+	timer := time.AfterFunc(timeout, func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		t.idle.Broadcast()
+	})
+	defer timer.Stop()
+	deadline := time.Now().Add(timeout)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for len(t.work) > 0 && time.Now().Before(deadline) {
+		t.idle.Wait()
+	}
+	return len(t.work) == 0
+}
@@ -0,0 +1,90 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package drain
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func noInterrupt(t *testing.T) func(context.Context) {
+	return func(context.Context) { t.Error("work was interrupted") }
+}
+
+func TestTrackerDrainsWork(t *testing.T) {
+	tracker := NewTracker(time.Minute)
+	mgrCtx, stop := context.WithCancel(context.Background())
+
+	ctx, end, ok := tracker.Begin(context.Background(), noInterrupt(t))
+	require.True(t, ok)
+
+	stopped := make(chan error)
+	go func() { stopped <- tracker.Start(mgrCtx) }()
+	stop()
+
+	// New work is refused once the manager stops, while in-flight work keeps
+	// running with a live context.
+	assert.Eventually(t, func() bool {
+		_, _, ok := tracker.Begin(context.Background(), noInterrupt(t))
+		return !ok
+	}, time.Second, time.Millisecond)
+	assert.NoError(t, ctx.Err())
+	select {
+	case <-stopped:
+		t.Fatal("tracker stopped before in-flight work ended")
+	default:
+	}
+
+	end()
+	end()
+	assert.NoError(t, <-stopped)
+	assert.Error(t, ctx.Err())
+}
+
+func TestTrackerInterruptsWork(t *testing.T) {
+	tracker := NewTracker(10 * time.Millisecond)
+	mgrCtx, stop := context.WithCancel(context.Background())
+	stop()
+
+	var workCtx context.Context
+	interrupted := false
+	workCtx, _, ok := tracker.Begin(context.Background(), func(ctx context.Context) {
+		assert.Error(t, workCtx.Err(), "work context was not cancelled before interrupt")
+		_, hasDeadline := ctx.Deadline()
+		assert.True(t, hasDeadline)
+		interrupted = true
+	})
+	require.True(t, ok)
+
+	assert.NoError(t, tracker.Start(mgrCtx))
+	assert.True(t, interrupted)
+}
+
+func TestNilTracker(t *testing.T) {
+	var tracker *Tracker
+	mgrCtx, stop := context.WithCancel(context.Background())
+	ctx, end, ok := tracker.Begin(mgrCtx, noInterrupt(t))
+	require.True(t, ok)
+	assert.NoError(t, ctx.Err())
+	end()
+
+	// Without a tracker, work stops with the manager.
+	stop()
+	assert.Error(t, ctx.Err())
+}
@@ -35,6 +35,7 @@ import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"sigs.k8s.io/yaml"
 
+	"github.com/operator-framework/operator-sdk/internal/scorecard"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 )
 
@@ -53,7 +54,7 @@ type Manifests struct {
 	ValidatingWebhooks               []admissionregv1.ValidatingWebhook
 	MutatingWebhooks                 []admissionregv1.MutatingWebhook
 	CustomResources                  []unstructured.Unstructured
-	ScorecardConfig                  scorecardv1alpha3.Configuration
+	ScorecardConfig                  scorecard.Configuration
 
 	Others []unstructured.Unstructured
 }
@@ -371,7 +372,7 @@ func (c *Manifests) addMutatingWebhookConfigurations(rawManifests ...[]byte) err
 // addScorecardConfig assumes manifest data in rawManifests is a ScorecardConfigs and adds it to the collector.
 // If a config has already been found, addScorecardConfig will return an error.
 func (c *Manifests) addScorecardConfig(rawManifest []byte) error {
-	cfg := scorecardv1alpha3.Configuration{}
+	cfg := scorecard.Configuration{}
 	if err := yaml.Unmarshal(rawManifest, &cfg); err != nil {
 		return err
 	}
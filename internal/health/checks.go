@@ -0,0 +1,106 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// cacheSyncTimeout is how long a check waits for informer caches to sync.
+const cacheSyncTimeout = time.Second
+
+// CacheSynced returns a check that fails until the informers of c are synced.
+func CacheSynced(c cache.Cache) healthz.Checker {
+	return func(req *http.Request) error {
+		ctx, cancel := context.WithTimeout(req.Context(), cacheSyncTimeout)
+		defer cancel()
+		if !c.WaitForCacheSync(ctx) {
+			return errors.New("informer caches are not synced")
+		}
+		return nil
+	}
+}
+
+// longestRunningProcessorMetric is the controller-runtime workqueue metric of
+// how long each controller's longest running reconcile has been running.
+const longestRunningProcessorMetric = "workqueue_longest_running_processor_seconds"
+
+// QueuesNotStalled returns a check that fails if a reconcile of any
+// controller has been running for longer than threshold, according to the
+// workqueue metrics gathered from g.
+func QueuesNotStalled(g prometheus.Gatherer, threshold time.Duration) healthz.Checker {
+	return func(*http.Request) error {
+		families, err := g.Gather()
+		if err != nil {
+			return fmt.Errorf("failed to gather workqueue metrics: %v", err)
+		}
+		var stalled []string
+		for _, family := range families {
+			if family.GetName() != longestRunningProcessorMetric {
+				continue
+			}
+			for _, m := range family.GetMetric() {
+				running := time.Duration(m.GetGauge().GetValue() * float64(time.Second))
+				if running <= threshold {
+					continue
+				}
+				name := ""
+				for _, l := range m.GetLabel() {
+					if l.GetName() == "name" {
+						name = l.GetValue()
+					}
+				}
+				stalled = append(stalled, fmt.Sprintf("%s (%s)", name, running.Round(time.Second)))
+			}
+		}
+		if len(stalled) > 0 {
+			sort.Strings(stalled)
+			return fmt.Errorf("reconciles running for longer than %s: %s", threshold, strings.Join(stalled, ", "))
+		}
+		return nil
+	}
+}
+
+// Cached returns a check that runs check at most once per interval if it
+// passes, for checks that are too expensive to run on every probe. Failures
+// are not cached, so that recovery is noticed promptly.
+func Cached(check healthz.Checker, interval time.Duration) healthz.Checker {
+	var mu sync.Mutex
+	var lastPassed time.Time
+	return func(req *http.Request) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !lastPassed.IsZero() && time.Since(lastPassed) < interval {
+			return nil
+		}
+		if err := check(req); err != nil {
+			lastPassed = time.Time{}
+			return err
+		}
+		lastPassed = time.Now()
+		return nil
+	}
+}
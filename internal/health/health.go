@@ -0,0 +1,160 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package health serves the liveness and readiness probes of Ansible- and
+// Helm-based operators.
+package health
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var log = logf.Log.WithName("health")
+
+// Handler serves the results of a set of named checks. Unlike
+// controller-runtime's healthz.Handler, which withholds the reason a check
+// failed, the error of each failing check is written to the response.
+//
+// All checks pass if the response status is 200. The "verbose" query
+// parameter lists the result of every check, even if all of them pass, and
+// "exclude" skips the named check.
+type Handler struct {
+	mu     sync.RWMutex
+	name   string
+	checks []namedCheck
+}
+
+type namedCheck struct {
+	name  string
+	check healthz.Checker
+}
+
+// NewHandler returns a handler with no checks, named after the probe it
+// serves, e.g. "readyz".
+func NewHandler(name string) *Handler {
+	return &Handler{name: name}
+}
+
+// AddCheck adds a check to h. Checks are run in the order they are added.
+func (h *Handler) AddCheck(name string, check healthz.Checker) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, namedCheck{name: name, check: check})
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	query := req.URL.Query()
+	_, verbose := query["verbose"]
+	excluded := map[string]bool{}
+	for _, name := range query["exclude"] {
+		excluded[strings.TrimSpace(name)] = true
+	}
+
+	h.mu.RLock()
+	checks := append([]namedCheck(nil), h.checks...)
+	h.mu.RUnlock()
+
+	var out bytes.Buffer
+	failed := false
+	for _, c := range checks {
+		if excluded[c.name] {
+			fmt.Fprintf(&out, "[+]%s excluded: ok\n", c.name)
+			continue
+		}
+		if err := c.check(req); err != nil {
+			log.V(1).Info("Health check failed", "probe", h.name, "check", c.name, "error", err.Error())
+			fmt.Fprintf(&out, "[-]%s failed: %v\n", c.name, err)
+			failed = true
+			continue
+		}
+		fmt.Fprintf(&out, "[+]%s ok\n", c.name)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	if failed {
+		log.Info("Health check failed", "probe", h.name, "checks", out.String())
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = out.WriteTo(w)
+		fmt.Fprintf(w, "%s check failed\n", h.name)
+		return
+	}
+	if !verbose {
+		fmt.Fprint(w, "ok")
+		return
+	}
+	_, _ = out.WriteTo(w)
+	fmt.Fprintf(w, "%s check passed\n", h.name)
+}
+
+// Server serves the liveness and readiness probes in place of the manager's
+// own health probe server. It should be started before the manager, so that
+// the probes are served while the manager's caches sync.
+type Server struct {
+	// Address is the address the probes are served on.
+	Address string
+	Healthz *Handler
+	Readyz  *Handler
+}
+
+// NewServer returns a server of the "/healthz" and "/readyz" probes on
+// address, which have no checks.
+func NewServer(address string) *Server {
+	return &Server{
+		Address: address,
+		Healthz: NewHandler("healthz"),
+		Readyz:  NewHandler("readyz"),
+	}
+}
+
+// Start serves the probes until ctx is done.
+func (s *Server) Start(ctx context.Context) error {
+	mux := http.NewServeMux()
+	mux.Handle("/healthz", s.Healthz)
+	mux.Handle("/readyz", s.Readyz)
+	srv := &http.Server{Handler: mux}
+
+	l, err := net.Listen("tcp", s.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on health probe address %s: %w", s.Address, err)
+	}
+	log.Info("Serving health probes", "address", l.Addr().String())
+
+	errs := make(chan error, 1)
+	go func() {
+		if err := srv.Serve(l); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			errs <- err
+		}
+		close(errs)
+	}()
+	select {
+	case err := <-errs:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
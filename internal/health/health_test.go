@@ -0,0 +1,94 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package health
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+func serve(h http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler("readyz")
+	h.AddCheck("ping", healthz.Ping)
+
+	rec := serve(h, "/readyz")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "ok", rec.Body.String())
+
+	rec = serve(h, "/readyz?verbose")
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "[+]ping ok\nreadyz check passed\n", rec.Body.String())
+
+	h.AddCheck("proxy", func(*http.Request) error { return errors.New("connection refused") })
+	rec = serve(h, "/readyz?verbose")
+	assert.Equal(t, http.StatusInternalServerError, rec.Code)
+	assert.Equal(t, "[+]ping ok\n[-]proxy failed: connection refused\nreadyz check failed\n", rec.Body.String())
+
+	rec = serve(h, "/readyz?exclude=proxy")
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+func TestQueuesNotStalled(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	longest := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: longestRunningProcessorMetric,
+	}, []string{"name"})
+	reg.MustRegister(longest)
+	check := QueuesNotStalled(reg, time.Minute)
+
+	longest.WithLabelValues("foo-controller").Set(10)
+	assert.NoError(t, check(nil))
+
+	longest.WithLabelValues("bar-controller").Set(90)
+	longest.WithLabelValues("baz-controller").Set(120)
+	err := check(nil)
+	require.Error(t, err)
+	assert.Equal(t, "reconciles running for longer than 1m0s: bar-controller (1m30s), baz-controller (2m0s)",
+		err.Error())
+}
+
+func TestCached(t *testing.T) {
+	calls := 0
+	var result error
+	check := Cached(func(*http.Request) error {
+		calls++
+		return result
+	}, time.Hour)
+
+	result = errors.New("failed")
+	assert.Error(t, check(nil))
+	assert.Error(t, check(nil))
+	assert.Equal(t, 2, calls)
+
+	result = nil
+	assert.NoError(t, check(nil))
+	result = errors.New("failed")
+	assert.NoError(t, check(nil))
+	assert.Equal(t, 3, calls)
+}
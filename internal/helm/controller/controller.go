@@ -15,6 +15,7 @@
 package controller
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"sync"
@@ -25,16 +26,21 @@ import (
 	"helm.sh/helm/v3/pkg/releaseutil"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	crthandler "sigs.k8s.io/controller-runtime/pkg/handler"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	ctrlpredicate "sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 	"sigs.k8s.io/yaml"
 
 	libhandler "github.com/operator-framework/operator-lib/handler"
 	"github.com/operator-framework/operator-lib/predicate"
+	"github.com/operator-framework/operator-sdk/internal/drain"
+	"github.com/operator-framework/operator-sdk/internal/helm/readiness"
 	"github.com/operator-framework/operator-sdk/internal/helm/release"
 	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 )
@@ -51,6 +57,24 @@ type WatchOptions struct {
 	WatchDependentResources bool
 	OverrideValues          map[string]string
 	MaxConcurrentReconciles int
+	Selector                metav1.LabelSelector
+	Blacklist               []schema.GroupVersionKind
+	MaxHistory              int
+	CheckReadiness          bool
+	ActionOptions           release.ActionOptions
+
+	// NamespaceSelector, if set, selects the watched namespaces by label
+	// instead of Namespace.
+	NamespaceSelector labels.Selector
+
+	// WatchClusterScopedResources tracks dependent resources that cannot be
+	// owned by the custom resource, such as cluster-scoped resources and
+	// resources in other namespaces, across the whole cluster and verifies
+	// that they are deleted when the release is uninstalled.
+	WatchClusterScopedResources bool
+
+	// Drain, if set, lets in-flight reconciles finish when the operator stops.
+	Drain *drain.Tracker
 }
 
 // Add creates a new helm operator controller and adds it to the manager
@@ -64,6 +88,15 @@ func Add(mgr manager.Manager, options WatchOptions) error {
 		ManagerFactory:  options.ManagerFactory,
 		ReconcilePeriod: options.ReconcilePeriod,
 		OverrideValues:  options.OverrideValues,
+		MaxHistory:      options.MaxHistory,
+		ActionOptions:   options.ActionOptions,
+
+		VerifyDependentCleanup: options.WatchClusterScopedResources,
+		APIReader:              mgr.GetAPIReader(),
+		Drain:                  options.Drain,
+	}
+	if options.CheckReadiness {
+		r.ReadinessEvaluator = readiness.NewEvaluator(mgr.GetCache(), mgr.GetAPIReader(), mgr.GetRESTMapper())
 	}
 
 	// Register the GVK with the schema
@@ -78,30 +111,55 @@ func Add(mgr manager.Manager, options WatchOptions) error {
 		return err
 	}
 
+	selectorPredicate, err := ctrlpredicate.LabelSelectorPredicate(options.Selector)
+	if err != nil {
+		return err
+	}
+
 	o := &unstructured.Unstructured{}
 	o.SetGroupVersionKind(options.GVK)
-	if err := c.Watch(&source.Kind{Type: o}, &handler.InstrumentedEnqueueRequestForObject{}); err != nil {
+	if err := c.Watch(&source.Kind{Type: o}, &handler.InstrumentedEnqueueRequestForObject{}, selectorPredicate); err != nil {
 		return err
 	}
 
 	if options.WatchDependentResources {
-		watchDependentResources(mgr, r, c)
+		watchAllNamespaces := options.Namespace == metav1.NamespaceAll && options.NamespaceSelector == nil
+		trackAcrossNamespaces := options.WatchClusterScopedResources && !watchAllNamespaces
+		watchDependentResources(mgr, r, c, options.Blacklist, trackAcrossNamespaces)
 	}
 
 	log.Info("Watching resource", "apiVersion", options.GVK.GroupVersion(), "kind",
-		options.GVK.Kind, "namespace", options.Namespace, "reconcilePeriod", options.ReconcilePeriod.String())
+		options.GVK.Kind, "namespace", options.Namespace, "namespaceSelector", options.NamespaceSelector,
+		"reconcilePeriod", options.ReconcilePeriod.String(),
+		"maxConcurrentReconciles", options.MaxConcurrentReconciles)
 	return nil
 }
 
 // watchDependentResources adds a release hook function to the HelmOperatorReconciler
-// that adds watches for resources in released Helm charts.
-func watchDependentResources(mgr manager.Manager, r *HelmOperatorReconciler, c controller.Controller) {
-	owner := &unstructured.Unstructured{}
-	owner.SetGroupVersionKind(r.GVK)
-
+// that adds watches for resources in released Helm charts. Resources with a GVK
+// in blacklist are never watched.
+//
+// Resources that cannot have an owner reference to the custom resource are
+// watched by owner annotation. If trackAcrossNamespaces is true, those
+// resources are watched with a cluster-wide cache, so that resources in
+// namespaces outside of the manager's cache are tracked too.
+func watchDependentResources(mgr manager.Manager, r *HelmOperatorReconciler, c controller.Controller,
+	blacklist []schema.GroupVersionKind, trackAcrossNamespaces bool) {
 	var m sync.RWMutex
-	watches := map[schema.GroupVersionKind]struct{}{}
+	ownerWatches := map[schema.GroupVersionKind]struct{}{}
+	annotationWatches := map[schema.GroupVersionKind]struct{}{}
+	// Treat blacklisted GVKs as already watched so that no watch is added for them.
+	for _, gvk := range blacklist {
+		ownerWatches[gvk] = struct{}{}
+		annotationWatches[gvk] = struct{}{}
+	}
+	var clusterCache cache.Cache
+
 	releaseHook := func(release *rpb.Release) error {
+		owner := &unstructured.Unstructured{}
+		owner.SetGroupVersionKind(r.GVK)
+		owner.SetNamespace(release.Namespace)
+
 		resources := releaseutil.SplitManifests(release.Manifest)
 		for _, resource := range resources {
 			var u unstructured.Unstructured
@@ -113,11 +171,8 @@ func watchDependentResources(mgr manager.Manager, r *HelmOperatorReconciler, c c
 			if gvk.Empty() {
 				continue
 			}
-			m.RLock()
-			_, ok := watches[gvk]
-			m.RUnlock()
-			if ok {
-				continue
+			if u.GetNamespace() == "" {
+				u.SetNamespace(release.Namespace)
 			}
 
 			restMapper := mgr.GetRESTMapper()
@@ -125,6 +180,16 @@ func watchDependentResources(mgr manager.Manager, r *HelmOperatorReconciler, c c
 			if err != nil {
 				return err
 			}
+			watches := annotationWatches
+			if useOwnerRef {
+				watches = ownerWatches
+			}
+			m.RLock()
+			_, ok := watches[gvk]
+			m.RUnlock()
+			if ok {
+				continue
+			}
 
 			if useOwnerRef { // Setup watch using owner references.
 				err = c.Watch(&source.Kind{Type: &u}, &crthandler.EnqueueRequestForOwner{OwnerType: owner},
@@ -133,7 +198,23 @@ func watchDependentResources(mgr manager.Manager, r *HelmOperatorReconciler, c c
 					return err
 				}
 			} else { // Setup watch using annotations.
-				err = c.Watch(&source.Kind{Type: &u}, &libhandler.EnqueueRequestForAnnotation{Type: gvk.GroupKind()},
+				var src source.Source = &source.Kind{Type: &u}
+				if trackAcrossNamespaces {
+					m.Lock()
+					if clusterCache == nil {
+						if clusterCache, err = newClusterCache(mgr); err != nil {
+							m.Unlock()
+							return err
+						}
+					}
+					m.Unlock()
+					informer, err := clusterCache.GetInformer(context.TODO(), &u)
+					if err != nil {
+						return err
+					}
+					src = &source.Informer{Informer: informer}
+				}
+				err = c.Watch(src, &libhandler.EnqueueRequestForAnnotation{Type: r.GVK.GroupKind()},
 					predicate.DependentPredicate{})
 				if err != nil {
 					return err
@@ -143,9 +224,26 @@ func watchDependentResources(mgr manager.Manager, r *HelmOperatorReconciler, c c
 			watches[gvk] = struct{}{}
 			m.Unlock()
 			log.Info("Watching dependent resource", "ownerApiVersion", r.GVK.GroupVersion(),
-				"ownerKind", r.GVK.Kind, "apiVersion", gvk.GroupVersion(), "kind", gvk.Kind)
+				"ownerKind", r.GVK.Kind, "apiVersion", gvk.GroupVersion(), "kind", gvk.Kind,
+				"ownerReference", useOwnerRef)
 		}
 		return nil
 	}
 	r.releaseHook = releaseHook
 }
+
+// newClusterCache creates a cache that is not restricted to the manager's
+// namespaces and adds it to the manager, which starts it.
+func newClusterCache(mgr manager.Manager) (cache.Cache, error) {
+	clusterCache, err := cache.New(mgr.GetConfig(), cache.Options{
+		Scheme: mgr.GetScheme(),
+		Mapper: mgr.GetRESTMapper(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cluster-wide cache: %w", err)
+	}
+	if err := mgr.Add(clusterCache); err != nil {
+		return nil, fmt.Errorf("failed to start cluster-wide cache: %w", err)
+	}
+	return clusterCache, nil
+}
@@ -19,23 +19,35 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
 	"time"
 
+	libhandler "github.com/operator-framework/operator-lib/handler"
+	"helm.sh/helm/v3/pkg/kube"
 	rpb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
 	"helm.sh/helm/v3/pkg/storage/driver"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/yaml"
 
+	"github.com/operator-framework/operator-sdk/internal/drain"
 	"github.com/operator-framework/operator-sdk/internal/helm/internal/diff"
 	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/metrics"
+	"github.com/operator-framework/operator-sdk/internal/helm/readiness"
 	"github.com/operator-framework/operator-sdk/internal/helm/release"
+	"github.com/operator-framework/operator-sdk/internal/util/k8sutil"
 )
 
 // blank assignment to verify that HelmOperatorReconciler implements reconcile.Reconciler
@@ -52,11 +64,72 @@ type HelmOperatorReconciler struct {
 	ManagerFactory  release.ManagerFactory
 	ReconcilePeriod time.Duration
 	OverrideValues  map[string]string
-	releaseHook     ReleaseHookFunc
+	MaxHistory      int
+	ActionOptions   release.ActionOptions
+	// ReadinessEvaluator, if set, is used to set the Ready condition from the
+	// readiness of the objects in the deployed release.
+	ReadinessEvaluator *readiness.Evaluator
+	// VerifyDependentCleanup, if true, keeps the uninstall finalizer until the
+	// release's resources that are tracked by owner annotation are deleted.
+	VerifyDependentCleanup bool
+	// APIReader reads the release's resources when verifying their cleanup.
+	// Unlike Client, it is not limited to the watched namespaces.
+	APIReader client.Reader
+	// Drain tracks reconciles so that they can finish when the operator stops.
+	Drain       *drain.Tracker
+	releaseHook ReleaseHookFunc
 }
 
 const (
 	finalizer = "uninstall-helm-release"
+
+	// ReconcilePeriodAnnotation - annotation used by a user to specify the reconciliation interval for the CR.
+	// To use create a CR with an annotation "helm.sdk.operatorframework.io/reconcile-period: 30s" or some other valid
+	// Duration. This will override the operators/or controllers reconcile period for that particular CR.
+	ReconcilePeriodAnnotation = "helm.sdk.operatorframework.io/reconcile-period"
+
+	// PreviewAnnotation - annotation used by a user to put the CR in preview mode. To use set the annotation
+	// "helm.sdk.operatorframework.io/preview: true" on a CR. While in preview mode, the release is not installed,
+	// upgraded or reconciled, and the changes that would be made are written to the CR's status.preview field.
+	PreviewAnnotation = "helm.sdk.operatorframework.io/preview"
+
+	// PausedAnnotation - annotation used by a user to pause the reconciliation of the CR, e.g. while fixing the
+	// release's resources by hand. To use set the annotation "helm.sdk.operatorframework.io/paused: true" on a CR.
+	// While paused, the release is only uninstalled when the CR is deleted. Removing the annotation resumes
+	// reconciliation immediately.
+	PausedAnnotation = "helm.sdk.operatorframework.io/paused"
+
+	// Annotations used by a user to override the watch's Helm action options for a CR, e.g.
+	// "helm.sdk.operatorframework.io/wait: true" or "helm.sdk.operatorframework.io/timeout: 10m".
+	WaitAnnotation         = "helm.sdk.operatorframework.io/wait"
+	TimeoutAnnotation      = "helm.sdk.operatorframework.io/timeout"
+	DisableHooksAnnotation = "helm.sdk.operatorframework.io/disable-hooks"
+	SkipCRDsAnnotation     = "helm.sdk.operatorframework.io/skip-crds"
+	RecreatePodsAnnotation = "helm.sdk.operatorframework.io/recreate-pods"
+	ResetValuesAnnotation  = "helm.sdk.operatorframework.io/reset-values"
+	ReuseValuesAnnotation  = "helm.sdk.operatorframework.io/reuse-values"
+	DescriptionAnnotation  = "helm.sdk.operatorframework.io/description"
+
+	// pausedMessage is the message of the Paused condition and event.
+	pausedMessage = "Reconciliation is paused until the paused annotation is removed"
+
+	// interruptedMessage is the message of the ReleaseFailed condition and event of an interrupted reconcile.
+	interruptedMessage = "Reconciliation was interrupted by the operator stopping, and will be retried"
+
+	// maxPreviewDiffSize is the maximum size in bytes of the diff stored in status.preview.
+	maxPreviewDiffSize = 8 * 1024
+
+	// defaultStatusHistoryLimit is the number of revisions kept in status.history
+	// when the release history is not limited.
+	defaultStatusHistoryLimit = 10
+
+	// readinessRequeueDelay is how long to wait before checking the readiness
+	// of a release's objects again when some of them are not ready.
+	readinessRequeueDelay = 10 * time.Second
+
+	// dependentCleanupRequeueDelay is how long to wait before checking again
+	// whether a release's resources were deleted after an uninstall.
+	dependentCleanupRequeueDelay = 5 * time.Second
 )
 
 // Reconcile reconciles the requested resource by installing, updating, or
@@ -76,6 +149,7 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		"kind", o.GetKind(),
 	)
 	log.V(1).Info("Reconciling")
+	gvk := r.GVK.String()
 
 	err := r.Client.Get(ctx, request.NamespacedName, o)
 	if apierrors.IsNotFound(err) {
@@ -86,39 +160,123 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		return reconcile.Result{}, err
 	}
 
+	if o.GetDeletionTimestamp() == nil && isPaused(o) {
+		log.Info("Reconciliation is paused, skipping reconciliation")
+		status := types.StatusFor(o)
+		// The Event and the condition are only recorded when reconciliation
+		// becomes paused, not on every later reconcile while it is.
+		if hasPausedCondition(status) {
+			return reconcile.Result{}, nil
+		}
+		r.EventRecorder.Event(o, "Normal", string(types.ReasonPaused), pausedMessage)
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionPaused,
+			Status:  types.StatusTrue,
+			Reason:  types.ReasonPaused,
+			Message: pausedMessage,
+		})
+		// The CR is reconciled again when the annotation is removed.
+		return reconcile.Result{}, r.updateResourceStatus(ctx, o, status)
+	}
+
+	// The reconcile is drained rather than interrupted when the operator stops,
+	// so it uses the context of the drain from here on. Its status is written
+	// with that context, so once it is interrupted it cannot overwrite the
+	// interrupted status.
+	actions := newActionGate()
+	ctx, endReconcile, started := r.Drain.Begin(ctx, func(ctx context.Context) {
+		log.Info("Interrupted reconcile that did not finish before the operator stopped")
+		if !actions.wait(ctx) {
+			log.Info("Helm action did not return in time, not marking resource as interrupted")
+			return
+		}
+		if err := r.markInterrupted(ctx, request.NamespacedName); err != nil {
+			log.Error(err, "Failed to mark resource as interrupted")
+		}
+	})
+	if !started {
+		log.Info("Operator is stopping, skipping reconciliation")
+		return reconcile.Result{Requeue: true}, nil
+	}
+	defer endReconcile()
+
+	reconcilePeriod := r.reconcilePeriodFor(o)
+
 	manager, err := r.ManagerFactory.NewManager(o, r.OverrideValues)
 	if err != nil {
 		log.Error(err, "Failed to get release manager")
 		return reconcile.Result{}, err
 	}
+	manager = gatedManager{Manager: manager, actions: actions}
 
 	status := types.StatusFor(o)
 	log = log.WithValues("release", manager.ReleaseName())
 
+	actionOptions, optionsErr := r.actionOptionsFor(o)
+
 	if o.GetDeletionTimestamp() != nil {
 		if !contains(o.GetFinalizers(), finalizer) {
 			log.Info("Resource is terminated, skipping reconciliation")
 			return reconcile.Result{}, nil
 		}
 
-		uninstalledRelease, err := manager.UninstallRelease(ctx)
+		if optionsErr != nil {
+			log.Info("Ignoring invalid Helm action option annotations", "error", optionsErr.Error())
+			actionOptions = r.ActionOptions
+		}
+		uninstallTimer := metrics.ActionTimer(gvk, metrics.ActionUninstall)
+		uninstalledRelease, err := manager.UninstallRelease(ctx, actionOptions.UninstallOption())
+		if !errors.Is(err, driver.ErrReleaseNotFound) {
+			uninstallTimer.ObserveDuration()
+		}
 		if err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
 			log.Error(err, "Failed to uninstall release")
+			metrics.ActionCompleted(gvk, metrics.ActionUninstall, string(types.ReasonUninstallError))
 			status.SetCondition(types.HelmAppCondition{
 				Type:    types.ConditionReleaseFailed,
 				Status:  types.StatusTrue,
 				Reason:  types.ReasonUninstallError,
 				Message: err.Error(),
 			})
-			_ = r.updateResourceStatus(o, status)
+			_ = r.updateResourceStatus(ctx, o, status)
 			return reconcile.Result{}, err
 		}
+
+		if r.VerifyDependentCleanup {
+			manifest := ""
+			if uninstalledRelease != nil {
+				manifest = uninstalledRelease.Manifest
+			} else if status.DeployedRelease != nil {
+				manifest = status.DeployedRelease.Manifest
+			}
+			remaining, err := remainingDependents(ctx, r.APIReader, r.Client, r.Client.RESTMapper(), o, manifest)
+			if err != nil || len(remaining) > 0 {
+				message := fmt.Sprintf("Waiting for resources to be deleted: %s", strings.Join(remaining, ", "))
+				if err != nil {
+					log.Error(err, "Failed to verify cleanup of release resources")
+					message = err.Error()
+				}
+				status.SetCondition(types.HelmAppCondition{
+					Type:    types.ConditionReleaseFailed,
+					Status:  types.StatusTrue,
+					Reason:  types.ReasonUninstallError,
+					Message: message,
+				})
+				_ = r.updateResourceStatus(ctx, o, status)
+				if err != nil {
+					return reconcile.Result{}, err
+				}
+				log.Info("Waiting for release resources to be deleted", "resources", remaining)
+				return reconcile.Result{RequeueAfter: dependentCleanupRequeueDelay}, nil
+			}
+		}
 		status.RemoveCondition(types.ConditionReleaseFailed)
 
 		if errors.Is(err, driver.ErrReleaseNotFound) {
 			log.Info("Release not found, removing finalizer")
 		} else {
 			log.Info("Uninstalled release")
+			metrics.ActionCompleted(gvk, metrics.ActionUninstall, string(types.ReasonUninstallSuccessful))
 			if log.V(0).Enabled() && uninstalledRelease != nil {
 				fmt.Println(diff.Generate(uninstalledRelease.Manifest, ""))
 			}
@@ -129,7 +287,7 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 			})
 			status.DeployedRelease = nil
 		}
-		if err := r.updateResourceStatus(o, status); err != nil {
+		if err := r.updateResourceStatus(ctx, o, status); err != nil {
 			log.Info("Failed to update CR status")
 			return reconcile.Result{}, err
 		}
@@ -139,6 +297,7 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 			log.Info("Failed to remove CR uninstall finalizer")
 			return reconcile.Result{}, err
 		}
+		metrics.ReleaseDeleted(gvk, request.NamespacedName)
 
 		// Since the client is hitting a cache, waiting for the
 		// deletion here will guarantee that the next reconciliation
@@ -152,42 +311,94 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		return reconcile.Result{}, nil
 	}
 
+	status.RemoveCondition(types.ConditionPaused)
 	status.SetCondition(types.HelmAppCondition{
 		Type:   types.ConditionInitialized,
 		Status: types.StatusTrue,
 	})
 
-	if err := manager.Sync(ctx); err != nil {
+	syncOptions := actionOptions
+	if optionsErr != nil {
+		syncOptions = r.ActionOptions
+	}
+	if err := manager.Sync(ctx, syncOptions.UpgradeOption()); err != nil {
 		log.Error(err, "Failed to sync release")
+		metrics.ActionCompleted(gvk, metrics.ActionReconcile, string(types.ReasonReconcileError))
 		status.SetCondition(types.HelmAppCondition{
 			Type:    types.ConditionIrreconcilable,
 			Status:  types.StatusTrue,
 			Reason:  types.ReasonReconcileError,
 			Message: err.Error(),
 		})
-		_ = r.updateResourceStatus(o, status)
+		_ = r.updateResourceStatus(ctx, o, status)
 		return reconcile.Result{}, err
 	}
 	status.RemoveCondition(types.ConditionIrreconcilable)
 
+	if optionsErr != nil {
+		log.Error(optionsErr, "Invalid Helm action options")
+		r.EventRecorder.Eventf(o, "Warning", "InvalidOptions", "Invalid Helm action options: %v", optionsErr)
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionIrreconcilable,
+			Status:  types.StatusTrue,
+			Reason:  types.ReasonInvalidOptions,
+			Message: optionsErr.Error(),
+		})
+		_ = r.updateResourceStatus(ctx, o, status)
+		return reconcile.Result{}, optionsErr
+	}
+
+	if hasHelmPreviewAnnotation(o) {
+		result, err := r.previewRelease(ctx, o, manager, status, actionOptions, reconcilePeriod)
+		if err == nil {
+			previewStatus := rpb.StatusPendingInstall
+			if manager.IsInstalled() {
+				previewStatus = rpb.StatusPendingUpgrade
+			}
+			metrics.SetReleaseStatus(gvk, request.NamespacedName, previewStatus)
+		}
+		return result, err
+	}
+	status.Preview = nil
+
+	// The chart's CRDs are applied on every reconcile, since they are not part
+	// of the release manifest that decides whether an upgrade is required.
+	if err := r.applyCRDs(ctx, o, manager, status, actionOptions); err != nil {
+		action := metrics.ActionReconcile
+		if !manager.IsInstalled() {
+			action = metrics.ActionInstall
+		} else if manager.IsUpgradeRequired() {
+			action = metrics.ActionUpgrade
+		}
+		metrics.ActionCompleted(gvk, action, string(types.ReasonCRDUpgradeError))
+		return reconcile.Result{}, err
+	}
+
 	if !manager.IsInstalled() {
 		for k, v := range r.OverrideValues {
 			r.EventRecorder.Eventf(o, "Warning", "OverrideValuesInUse",
 				"Chart value %q overridden to %q by operator's watches.yaml", k, v)
 		}
-		installedRelease, err := manager.InstallRelease(ctx)
+		installTimer := metrics.ActionTimer(gvk, metrics.ActionInstall)
+		installedRelease, err := manager.InstallRelease(ctx, actionOptions.InstallOption())
+		installTimer.ObserveDuration()
 		if err != nil {
 			log.Error(err, "Release failed")
+			metrics.ActionCompleted(gvk, metrics.ActionInstall, string(types.ReasonInstallError))
+			metrics.SetReleaseStatus(gvk, request.NamespacedName, rpb.StatusFailed)
 			status.SetCondition(types.HelmAppCondition{
 				Type:    types.ConditionReleaseFailed,
 				Status:  types.StatusTrue,
 				Reason:  types.ReasonInstallError,
 				Message: err.Error(),
 			})
-			_ = r.updateResourceStatus(o, status)
+			r.addRevision(status, nil, types.ReasonInstallError)
+			_ = r.updateResourceStatus(ctx, o, status)
 			return reconcile.Result{}, err
 		}
 		status.RemoveCondition(types.ConditionReleaseFailed)
+		metrics.ActionCompleted(gvk, metrics.ActionInstall, string(types.ReasonInstallSuccessful))
+		metrics.SetReleaseStatus(gvk, request.NamespacedName, rpb.StatusDeployed)
 
 		log.V(1).Info("Adding finalizer", "finalizer", finalizer)
 		controllerutil.AddFinalizer(o, finalizer)
@@ -222,8 +433,13 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 			Name:     installedRelease.Name,
 			Manifest: installedRelease.Manifest,
 		}
-		err = r.updateResourceStatus(o, status)
-		return reconcile.Result{RequeueAfter: r.ReconcilePeriod}, err
+		r.addRevision(status, installedRelease, types.ReasonInstallSuccessful)
+		requeueAfter := r.updateReadyCondition(ctx, o, status, installedRelease.Manifest, reconcilePeriod)
+		err = r.updateResourceStatus(ctx, o, status)
+		if err == nil {
+			metrics.ReconcileSucceeded(gvk, request.NamespacedName)
+		}
+		return reconcile.Result{RequeueAfter: requeueAfter}, err
 	}
 
 	if !contains(o.GetFinalizers(), finalizer) {
@@ -241,19 +457,27 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 				"Chart value %q overridden to %q by operator's watches.yaml", k, v)
 		}
 		force := hasHelmUpgradeForceAnnotation(o)
-		previousRelease, upgradedRelease, err := manager.UpgradeRelease(ctx, release.ForceUpgrade(force))
+		upgradeTimer := metrics.ActionTimer(gvk, metrics.ActionUpgrade)
+		previousRelease, upgradedRelease, err := manager.UpgradeRelease(ctx, actionOptions.UpgradeOption(),
+			release.ForceUpgrade(force))
+		upgradeTimer.ObserveDuration()
 		if err != nil {
 			log.Error(err, "Release failed")
+			metrics.ActionCompleted(gvk, metrics.ActionUpgrade, string(types.ReasonUpgradeError))
+			metrics.SetReleaseStatus(gvk, request.NamespacedName, rpb.StatusFailed)
 			status.SetCondition(types.HelmAppCondition{
 				Type:    types.ConditionReleaseFailed,
 				Status:  types.StatusTrue,
 				Reason:  types.ReasonUpgradeError,
 				Message: err.Error(),
 			})
-			_ = r.updateResourceStatus(o, status)
+			r.addRevision(status, nil, types.ReasonUpgradeError)
+			_ = r.updateResourceStatus(ctx, o, status)
 			return reconcile.Result{}, err
 		}
 		status.RemoveCondition(types.ConditionReleaseFailed)
+		metrics.ActionCompleted(gvk, metrics.ActionUpgrade, string(types.ReasonUpgradeSuccessful))
+		metrics.SetReleaseStatus(gvk, request.NamespacedName, rpb.StatusDeployed)
 
 		if r.releaseHook != nil {
 			if err := r.releaseHook(upgradedRelease); err != nil {
@@ -281,8 +505,13 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 			Name:     upgradedRelease.Name,
 			Manifest: upgradedRelease.Manifest,
 		}
-		err = r.updateResourceStatus(o, status)
-		return reconcile.Result{RequeueAfter: r.ReconcilePeriod}, err
+		r.addRevision(status, upgradedRelease, types.ReasonUpgradeSuccessful)
+		requeueAfter := r.updateReadyCondition(ctx, o, status, upgradedRelease.Manifest, reconcilePeriod)
+		err = r.updateResourceStatus(ctx, o, status)
+		if err == nil {
+			metrics.ReconcileSucceeded(gvk, request.NamespacedName)
+		}
+		return reconcile.Result{RequeueAfter: requeueAfter}, err
 	}
 
 	// If a change is made to the CR spec that causes a release failure, a
@@ -293,19 +522,24 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 	// no longer being attempted.
 	status.RemoveCondition(types.ConditionReleaseFailed)
 
+	reconcileTimer := metrics.ActionTimer(gvk, metrics.ActionReconcile)
 	expectedRelease, err := manager.ReconcileRelease(ctx)
+	reconcileTimer.ObserveDuration()
 	if err != nil {
 		log.Error(err, "Failed to reconcile release")
+		metrics.ActionCompleted(gvk, metrics.ActionReconcile, string(types.ReasonReconcileError))
 		status.SetCondition(types.HelmAppCondition{
 			Type:    types.ConditionIrreconcilable,
 			Status:  types.StatusTrue,
 			Reason:  types.ReasonReconcileError,
 			Message: err.Error(),
 		})
-		_ = r.updateResourceStatus(o, status)
+		_ = r.updateResourceStatus(ctx, o, status)
 		return reconcile.Result{}, err
 	}
 	status.RemoveCondition(types.ConditionIrreconcilable)
+	metrics.ActionCompleted(gvk, metrics.ActionReconcile, string(types.ReasonReconcileSuccessful))
+	metrics.SetReleaseStatus(gvk, request.NamespacedName, rpb.StatusDeployed)
 
 	if r.releaseHook != nil {
 		if err := r.releaseHook(expectedRelease); err != nil {
@@ -333,8 +567,12 @@ func (r HelmOperatorReconciler) Reconcile(ctx context.Context, request reconcile
 		Name:     expectedRelease.Name,
 		Manifest: expectedRelease.Manifest,
 	}
-	err = r.updateResourceStatus(o, status)
-	return reconcile.Result{RequeueAfter: r.ReconcilePeriod}, err
+	requeueAfter := r.updateReadyCondition(ctx, o, status, expectedRelease.Manifest, reconcilePeriod)
+	err = r.updateResourceStatus(ctx, o, status)
+	if err == nil {
+		metrics.ReconcileSucceeded(gvk, request.NamespacedName)
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, err
 }
 
 // returns the boolean representation of the annotation string
@@ -355,16 +593,450 @@ func hasHelmUpgradeForceAnnotation(o *unstructured.Unstructured) bool {
 	return value
 }
 
+// updateReadyCondition sets the Ready condition from the readiness of the
+// objects in manifest, if readiness checks are enabled. It returns how long to
+// wait before the next reconcile, which is shortened while objects are not
+// ready so that the condition is updated promptly.
+func (r HelmOperatorReconciler) updateReadyCondition(ctx context.Context, o *unstructured.Unstructured,
+	status *types.HelmAppStatus, manifest string, reconcilePeriod time.Duration) time.Duration {
+	if r.ReadinessEvaluator == nil {
+		return reconcilePeriod
+	}
+	requeueAfter := readinessRequeueDelay
+	if reconcilePeriod > 0 && reconcilePeriod < requeueAfter {
+		requeueAfter = reconcilePeriod
+	}
+
+	unready, err := r.ReadinessEvaluator.Evaluate(ctx, manifest, o.GetNamespace())
+	if err != nil {
+		log.Error(err, "Failed to evaluate release readiness", "namespace", o.GetNamespace(), "name", o.GetName())
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionReady,
+			Status:  types.StatusUnknown,
+			Reason:  types.ReasonReadinessError,
+			Message: err.Error(),
+		})
+		return requeueAfter
+	}
+	if len(unready) > 0 {
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionReady,
+			Status:  types.StatusFalse,
+			Reason:  types.ReasonResourcesNotReady,
+			Message: strings.Join(unready, "; "),
+		})
+		return requeueAfter
+	}
+	status.SetCondition(types.HelmAppCondition{
+		Type:   types.ConditionReady,
+		Status: types.StatusTrue,
+		Reason: types.ReasonResourcesReady,
+	})
+	return reconcilePeriod
+}
+
+// applyCRDs applies the chart's CRDs if CRD management is enabled and opts do
+// not skip CRDs, and records the outcome for each CRD in the status. If any CRD
+// cannot be applied, the ReleaseFailed condition is set and the status is
+// updated, since the release cannot be installed or upgraded safely.
+func (r HelmOperatorReconciler) applyCRDs(ctx context.Context, o *unstructured.Unstructured,
+	manager release.Manager, status *types.HelmAppStatus, opts release.ActionOptions) error {
+	if opts.SkipCRDs != nil && *opts.SkipCRDs {
+		return nil
+	}
+	results, err := manager.ApplyCRDs(ctx)
+	if results != nil {
+		status.CRDs = results
+	}
+	if err != nil {
+		log.Error(err, "Failed to apply chart CRDs", "namespace", o.GetNamespace(), "name", o.GetName())
+		r.EventRecorder.Eventf(o, "Warning", "CRDUpgradeError", "Failed to apply chart CRDs: %v", err)
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionReleaseFailed,
+			Status:  types.StatusTrue,
+			Reason:  types.ReasonCRDUpgradeError,
+			Message: err.Error(),
+		})
+		_ = r.updateResourceStatus(ctx, o, status)
+		return err
+	}
+	return nil
+}
+
+// addRevision records the outcome of an install or upgrade in the status
+// history. rel is the resulting release, which is nil if the action failed.
+func (r HelmOperatorReconciler) addRevision(status *types.HelmAppStatus, rel *rpb.Release,
+	outcome types.HelmAppConditionReason) {
+	revision := types.HelmAppRevision{
+		Timestamp: metav1.Now(),
+		Outcome:   outcome,
+	}
+	if rel != nil {
+		revision.Revision = rel.Version
+		if rel.Chart != nil && rel.Chart.Metadata != nil {
+			revision.ChartVersion = rel.Chart.Metadata.Version
+		}
+	}
+	limit := defaultStatusHistoryLimit
+	if r.MaxHistory > 0 {
+		limit = r.MaxHistory
+	}
+	status.AddRevision(revision, limit)
+}
+
+// actionOptionsFor returns the reconciler's Helm action options, overridden by
+// the action option annotations set on o. It returns an error if any
+// annotation cannot be parsed or the resulting options are invalid.
+func (r HelmOperatorReconciler) actionOptionsFor(o *unstructured.Unstructured) (release.ActionOptions, error) {
+	annotations := o.GetAnnotations()
+	overlay := release.ActionOptions{}
+	var errs []string
+
+	parseBool := func(annotation string) *bool {
+		v, ok := annotations[annotation]
+		if !ok {
+			return nil
+		}
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("annotation %q: %q is not a boolean", annotation, v))
+			return nil
+		}
+		return &b
+	}
+	overlay.Wait = parseBool(WaitAnnotation)
+	overlay.DisableHooks = parseBool(DisableHooksAnnotation)
+	overlay.SkipCRDs = parseBool(SkipCRDsAnnotation)
+	overlay.RecreatePods = parseBool(RecreatePodsAnnotation)
+	overlay.ResetValues = parseBool(ResetValuesAnnotation)
+	overlay.ReuseValues = parseBool(ReuseValuesAnnotation)
+
+	if v, ok := annotations[TimeoutAnnotation]; ok {
+		d, err := time.ParseDuration(v)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("annotation %q: %q is not a duration", TimeoutAnnotation, v))
+		} else {
+			overlay.Timeout = &metav1.Duration{Duration: d}
+		}
+	}
+	if v, ok := annotations[DescriptionAnnotation]; ok {
+		overlay.Description = &v
+	}
+
+	if len(errs) > 0 {
+		return r.ActionOptions, errors.New(strings.Join(errs, "; "))
+	}
+	opts := r.ActionOptions.Merge(overlay)
+	if err := opts.Validate(); err != nil {
+		return r.ActionOptions, err
+	}
+	return opts, nil
+}
+
+// reconcilePeriodFor returns the reconcile period for o, which is the value of
+// the reconcile period annotation if it is set and valid, or the reconciler's
+// reconcile period otherwise.
+func (r HelmOperatorReconciler) reconcilePeriodFor(o *unstructured.Unstructured) time.Duration {
+	period, ok := o.GetAnnotations()[ReconcilePeriodAnnotation]
+	if !ok {
+		return r.ReconcilePeriod
+	}
+	duration, err := time.ParseDuration(period)
+	if err != nil || duration < 0 {
+		log.Info("Could not parse annotation as a non-negative duration",
+			"annotation", ReconcilePeriodAnnotation, "value informed", period)
+		return r.ReconcilePeriod
+	}
+	return duration
+}
+
+// previewRelease records the changes that the next install or upgrade of the
+// release with actionOptions would make in the status of o, without modifying
+// the release.
+func (r HelmOperatorReconciler) previewRelease(ctx context.Context, o *unstructured.Unstructured,
+	manager release.Manager, status *types.HelmAppStatus, actionOptions release.ActionOptions,
+	reconcilePeriod time.Duration) (reconcile.Result, error) {
+	log := log.WithValues("namespace", o.GetNamespace(), "name", o.GetName(), "release", manager.ReleaseName())
+
+	deployedRelease, candidateRelease, err := manager.PreviewRelease(ctx, actionOptions.InstallOption())
+	if err != nil {
+		log.Error(err, "Failed to preview release")
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionReleaseFailed,
+			Status:  types.StatusTrue,
+			Reason:  types.ReasonPreviewError,
+			Message: err.Error(),
+		})
+		_ = r.updateResourceStatus(ctx, o, status)
+		return reconcile.Result{}, err
+	}
+	status.RemoveCondition(types.ConditionReleaseFailed)
+
+	deployedManifest := ""
+	if deployedRelease != nil {
+		deployedManifest = deployedRelease.Manifest
+	}
+	changes, truncated := truncateDiff(diff.GenerateChanges(deployedManifest, candidateRelease.Manifest),
+		maxPreviewDiffSize)
+	status.Preview = &types.HelmAppPreview{
+		Diff:      changes,
+		Truncated: truncated,
+	}
+
+	log.Info("Previewed release", "truncated", truncated)
+	err = r.updateResourceStatus(ctx, o, status)
+	return reconcile.Result{RequeueAfter: reconcilePeriod}, err
+}
+
+// truncateDiff cuts d at the last complete line that fits in size bytes. It
+// returns the result and whether d was truncated.
+func truncateDiff(d string, size int) (string, bool) {
+	if len(d) <= size {
+		return d, false
+	}
+	d = d[:size]
+	if i := strings.LastIndex(d, "\n"); i >= 0 {
+		d = d[:i+1]
+	}
+	return d, true
+}
+
+// returns the boolean representation of the preview annotation string
+// will return false if annotation is not set
+func hasHelmPreviewAnnotation(o *unstructured.Unstructured) bool {
+	preview := o.GetAnnotations()[PreviewAnnotation]
+	if preview == "" {
+		return false
+	}
+	value, err := strconv.ParseBool(preview)
+	if err != nil {
+		log.Info("Could not parse annotation as a boolean",
+			"annotation", PreviewAnnotation, "value informed", preview)
+		return false
+	}
+	return value
+}
+
+// returns the boolean representation of the paused annotation string
+// will return false if annotation is not set
+func isPaused(o *unstructured.Unstructured) bool {
+	paused := o.GetAnnotations()[PausedAnnotation]
+	if paused == "" {
+		return false
+	}
+	value, err := strconv.ParseBool(paused)
+	if err != nil {
+		log.Info("Could not parse annotation as a boolean",
+			"annotation", PausedAnnotation, "value informed", paused)
+		return false
+	}
+	return value
+}
+
+// hasPausedCondition returns true if status records that reconciliation is paused.
+func hasPausedCondition(status *types.HelmAppStatus) bool {
+	for _, c := range status.Conditions {
+		if c.Type == types.ConditionPaused {
+			return c.Status == types.StatusTrue
+		}
+	}
+	return false
+}
+
+// remainingDependents deletes the resources in manifest that are tracked by
+// owner annotation rather than owner reference, since the garbage collector
+// does not delete them with o, and returns the ones that still exist.
+// Resources with Helm's "keep" resource policy and resources that are no
+// longer annotated with o as their owner are ignored. The resources are read
+// with reader, which must see every namespace and cluster-scoped resources.
+func remainingDependents(ctx context.Context, reader client.Reader, c client.Client, mapper meta.RESTMapper,
+	o *unstructured.Unstructured, manifest string) ([]string, error) {
+	ownerName := fmt.Sprintf("%s/%s", o.GetNamespace(), o.GetName())
+	ownerType := o.GroupVersionKind().GroupKind().String()
+
+	var remaining []string
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		u := &unstructured.Unstructured{}
+		if err := yaml.Unmarshal([]byte(doc), u); err != nil {
+			return nil, err
+		}
+		gvk := u.GroupVersionKind()
+		if gvk.Empty() || u.GetAnnotations()[kube.ResourcePolicyAnno] == kube.KeepPolicy {
+			continue
+		}
+
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			// The resource's kind no longer exists, so neither does the resource.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+			u.SetNamespace("")
+		} else if u.GetNamespace() == "" {
+			u.SetNamespace(o.GetNamespace())
+		}
+		useOwnerRef, err := k8sutil.SupportsOwnerReference(mapper, o, u)
+		if err != nil {
+			return nil, err
+		}
+		if useOwnerRef {
+			continue
+		}
+
+		live := &unstructured.Unstructured{}
+		live.SetGroupVersionKind(gvk)
+		key := client.ObjectKeyFromObject(u)
+		if err := reader.Get(ctx, key, live); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to get %s %s: %w", gvk.Kind, key, err)
+		}
+		annotations := live.GetAnnotations()
+		if annotations[libhandler.NamespacedNameAnnotation] != ownerName || annotations[libhandler.TypeAnnotation] != ownerType {
+			continue
+		}
+
+		if live.GetDeletionTimestamp() == nil {
+			err := c.Delete(ctx, live, client.PropagationPolicy(metav1.DeletePropagationBackground))
+			if err != nil && !apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("failed to delete %s %s: %w", gvk.Kind, key, err)
+			}
+			// Resources without finalizers are deleted immediately.
+			if err := reader.Get(ctx, key, live); apierrors.IsNotFound(err) {
+				continue
+			}
+		}
+		remaining = append(remaining, fmt.Sprintf("%s %s", gvk.Kind, strings.TrimPrefix(key.String(), "/")))
+	}
+	return remaining, nil
+}
+
 func (r HelmOperatorReconciler) updateResource(o client.Object) error {
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
 		return r.Client.Update(context.TODO(), o)
 	})
 }
 
-func (r HelmOperatorReconciler) updateResourceStatus(o *unstructured.Unstructured, status *types.HelmAppStatus) error {
+func (r HelmOperatorReconciler) updateResourceStatus(ctx context.Context, o *unstructured.Unstructured,
+	status *types.HelmAppStatus) error {
+	statusMap, err := status.ToMap()
+	if err != nil {
+		return err
+	}
 	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
-		o.Object["status"] = status
-		return r.Client.Status().Update(context.TODO(), o)
+		o.Object["status"] = statusMap
+		return r.Client.Status().Update(ctx, o)
+	})
+}
+
+// actionGate is held by a reconcile while it runs a Helm action. Helm actions
+// take no context, so an action keeps running after its reconcile is
+// interrupted, and the interrupted status must only be written once it has
+// returned.
+type actionGate chan struct{}
+
+func newActionGate() actionGate {
+	return make(actionGate, 1)
+}
+
+// enter holds the gate for an action, unless ctx is done.
+func (g actionGate) enter(ctx context.Context) error {
+	g <- struct{}{}
+	if err := ctx.Err(); err != nil {
+		g.exit()
+		return err
+	}
+	return nil
+}
+
+func (g actionGate) exit() {
+	<-g
+}
+
+// wait waits until no action is running, or until ctx is done, and returns
+// whether no action is running. Once the reconcile's context is done, no
+// action is started after wait returns.
+func (g actionGate) wait(ctx context.Context) bool {
+	select {
+	case g <- struct{}{}:
+		g.exit()
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// gatedManager holds its reconcile's action gate while it runs the Helm
+// actions of the release manager that change the cluster.
+type gatedManager struct {
+	release.Manager
+	actions actionGate
+}
+
+func (m gatedManager) InstallRelease(ctx context.Context, opts ...release.InstallOption) (*rpb.Release, error) {
+	if err := m.actions.enter(ctx); err != nil {
+		return nil, err
+	}
+	defer m.actions.exit()
+	return m.Manager.InstallRelease(ctx, opts...)
+}
+
+func (m gatedManager) UpgradeRelease(ctx context.Context, opts ...release.UpgradeOption) (*rpb.Release,
+	*rpb.Release, error) {
+	if err := m.actions.enter(ctx); err != nil {
+		return nil, nil, err
+	}
+	defer m.actions.exit()
+	return m.Manager.UpgradeRelease(ctx, opts...)
+}
+
+func (m gatedManager) ReconcileRelease(ctx context.Context) (*rpb.Release, error) {
+	if err := m.actions.enter(ctx); err != nil {
+		return nil, err
+	}
+	defer m.actions.exit()
+	return m.Manager.ReconcileRelease(ctx)
+}
+
+func (m gatedManager) UninstallRelease(ctx context.Context, opts ...release.UninstallOption) (*rpb.Release, error) {
+	if err := m.actions.enter(ctx); err != nil {
+		return nil, err
+	}
+	defer m.actions.exit()
+	return m.Manager.UninstallRelease(ctx, opts...)
+}
+
+// markInterrupted records on the resource that its reconcile did not finish
+// before the operator stopped. It gets its own copy of the resource, since the
+// interrupted reconcile may still be using it.
+func (r HelmOperatorReconciler) markInterrupted(ctx context.Context, key apitypes.NamespacedName) error {
+	o := &unstructured.Unstructured{}
+	o.SetGroupVersionKind(r.GVK)
+	return retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		if err := r.Client.Get(ctx, key, o); err != nil {
+			return err
+		}
+		status := types.StatusFor(o)
+		status.SetCondition(types.HelmAppCondition{
+			Type:    types.ConditionReleaseFailed,
+			Status:  types.StatusTrue,
+			Reason:  types.ReasonInterrupted,
+			Message: interruptedMessage,
+		})
+		statusMap, err := status.ToMap()
+		if err != nil {
+			return err
+		}
+		o.Object["status"] = statusMap
+		if err := r.Client.Status().Update(ctx, o); err != nil {
+			return err
+		}
+		r.EventRecorder.Event(o, "Warning", string(types.ReasonInterrupted), interruptedMessage)
+		return nil
 	})
 }
 
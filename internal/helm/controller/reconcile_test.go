@@ -15,10 +15,32 @@
 package controller
 
 import (
+	"context"
 	"testing"
+	"time"
 
+	libhandler "github.com/operator-framework/operator-lib/handler"
 	"github.com/stretchr/testify/assert"
+	rpb "helm.sh/helm/v3/pkg/release"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/operator-framework/operator-sdk/internal/drain"
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+	"github.com/operator-framework/operator-sdk/internal/helm/readiness"
+	"github.com/operator-framework/operator-sdk/internal/helm/release"
 )
 
 func TestHasHelmUpgradeForceAnnotation(t *testing.T) {
@@ -77,6 +99,176 @@ func TestHasHelmUpgradeForceAnnotation(t *testing.T) {
 	}
 }
 
+func TestReconcilePeriodFor(t *testing.T) {
+	r := HelmOperatorReconciler{ReconcilePeriod: time.Minute}
+	tests := []struct {
+		input       map[string]interface{}
+		expectedVal time.Duration
+		name        string
+	}{
+		{
+			input: map[string]interface{}{
+				"helm.sdk.operatorframework.io/reconcile-period": "30s",
+			},
+			expectedVal: 30 * time.Second,
+			name:        "valid duration",
+		},
+		{
+			input: map[string]interface{}{
+				"helm.sdk.operatorframework.io/reconcile-period": "0s",
+			},
+			expectedVal: 0,
+			name:        "zero duration",
+		},
+		{
+			input:       map[string]interface{}{},
+			expectedVal: time.Minute,
+			name:        "annotation not set",
+		},
+		{
+			input: map[string]interface{}{
+				"helm.sdk.operatorframework.io/reconcile-period": "invalid",
+			},
+			expectedVal: time.Minute,
+			name:        "invalid value",
+		},
+		{
+			input: map[string]interface{}{
+				"helm.sdk.operatorframework.io/reconcile-period": "-1m",
+			},
+			expectedVal: time.Minute,
+			name:        "negative value",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expectedVal, r.reconcilePeriodFor(annotations(test.input)), test.name)
+	}
+}
+
+func TestHasHelmPreviewAnnotation(t *testing.T) {
+	tests := []struct {
+		input       map[string]interface{}
+		expectedVal bool
+		name        string
+	}{
+		{
+			input: map[string]interface{}{
+				"helm.sdk.operatorframework.io/preview": "true",
+			},
+			expectedVal: true,
+			name:        "base case true",
+		},
+		{
+			input: map[string]interface{}{
+				"helm.sdk.operatorframework.io/preview": "false",
+			},
+			expectedVal: false,
+			name:        "base case false",
+		},
+		{
+			input:       map[string]interface{}{},
+			expectedVal: false,
+			name:        "annotation not set",
+		},
+		{
+			input: map[string]interface{}{
+				"helm.sdk.operatorframework.io/preview": "invalid",
+			},
+			expectedVal: false,
+			name:        "invalid value",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expectedVal, hasHelmPreviewAnnotation(annotations(test.input)), test.name)
+	}
+}
+
+func TestIsPaused(t *testing.T) {
+	tests := []struct {
+		input       map[string]interface{}
+		expectedVal bool
+		name        string
+	}{
+		{
+			input: map[string]interface{}{
+				"helm.sdk.operatorframework.io/paused": "true",
+			},
+			expectedVal: true,
+			name:        "base case true",
+		},
+		{
+			input: map[string]interface{}{
+				"helm.sdk.operatorframework.io/paused": "false",
+			},
+			expectedVal: false,
+			name:        "base case false",
+		},
+		{
+			input:       map[string]interface{}{},
+			expectedVal: false,
+			name:        "annotation not set",
+		},
+		{
+			input: map[string]interface{}{
+				"helm.sdk.operatorframework.io/paused": "invalid",
+			},
+			expectedVal: false,
+			name:        "invalid value",
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expectedVal, isPaused(annotations(test.input)), test.name)
+	}
+}
+
+func TestTruncateDiff(t *testing.T) {
+	d, truncated := truncateDiff("+a\n-b\n", 10)
+	assert.Equal(t, "+a\n-b\n", d)
+	assert.False(t, truncated)
+
+	d, truncated = truncateDiff("+aaa\n-bbb\n+ccc\n", 12)
+	assert.Equal(t, "+aaa\n-bbb\n", d)
+	assert.True(t, truncated)
+}
+
+func TestActionOptionsFor(t *testing.T) {
+	trueVal := true
+	r := HelmOperatorReconciler{ActionOptions: release.ActionOptions{Wait: &trueVal}}
+
+	opts, err := r.actionOptionsFor(annotations(map[string]interface{}{
+		"helm.sdk.operatorframework.io/wait":          "false",
+		"helm.sdk.operatorframework.io/timeout":       "2m",
+		"helm.sdk.operatorframework.io/disable-hooks": "true",
+		"helm.sdk.operatorframework.io/description":   "manual",
+	}))
+	assert.NoError(t, err)
+	assert.False(t, *opts.Wait)
+	assert.Equal(t, 2*time.Minute, opts.Timeout.Duration)
+	assert.True(t, *opts.DisableHooks)
+	assert.Equal(t, "manual", *opts.Description)
+
+	opts, err = r.actionOptionsFor(annotations(map[string]interface{}{}))
+	assert.NoError(t, err)
+	assert.Equal(t, r.ActionOptions, opts)
+
+	_, err = r.actionOptionsFor(annotations(map[string]interface{}{
+		"helm.sdk.operatorframework.io/skip-crds": "maybe",
+		"helm.sdk.operatorframework.io/timeout":   "soon",
+	}))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "skip-crds")
+	assert.Contains(t, err.Error(), "timeout")
+
+	_, err = r.actionOptionsFor(annotations(map[string]interface{}{
+		"helm.sdk.operatorframework.io/reset-values": "true",
+		"helm.sdk.operatorframework.io/reuse-values": "true",
+	}))
+	assert.Error(t, err)
+}
+
 func annotations(m map[string]interface{}) *unstructured.Unstructured {
 	return &unstructured.Unstructured{
 		Object: map[string]interface{}{
@@ -86,3 +278,431 @@ func annotations(m map[string]interface{}) *unstructured.Unstructured {
 		},
 	}
 }
+
+func TestRemainingDependents(t *testing.T) {
+	owner := &unstructured.Unstructured{}
+	owner.SetGroupVersionKind(schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"})
+	owner.SetNamespace("default")
+	owner.SetName("foo")
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(owner.GroupVersionKind(), meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+	mapper.Add(rbacv1.SchemeGroupVersion.WithKind("ClusterRole"), meta.RESTScopeRoot)
+
+	annotated := func(obj client.Object, ownerName string) client.Object {
+		obj.SetAnnotations(map[string]string{
+			libhandler.NamespacedNameAnnotation: "default/" + ownerName,
+			libhandler.TypeAnnotation:           "Foo.example.com",
+		})
+		return obj
+	}
+	now := metav1.Now()
+	c := fake.NewFakeClientWithScheme(clientgoscheme.Scheme,
+		annotated(&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "owned"}}, "foo"),
+		annotated(&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "kept"}}, "foo"),
+		annotated(&rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "adopted"}}, "bar"),
+		annotated(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "other", Name: "terminating",
+			DeletionTimestamp: &now, Finalizers: []string{"example.com/cleanup"}}}, "foo"),
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "local"}},
+	)
+
+	manifest := `---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: owned
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: kept
+  annotations:
+    helm.sh/resource-policy: keep
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: adopted
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: deleted
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: terminating
+  namespace: other
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: local
+`
+	remaining, err := remainingDependents(context.TODO(), c, c, mapper, owner, manifest)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ConfigMap other/terminating"}, remaining)
+
+	exists := func(obj client.Object, name string) bool {
+		return c.Get(context.TODO(), client.ObjectKey{Name: name}, obj) == nil
+	}
+	assert.False(t, exists(&rbacv1.ClusterRole{}, "owned"), "owned cluster-scoped resource should be deleted")
+	assert.True(t, exists(&rbacv1.ClusterRole{}, "kept"), "resource with keep policy should not be deleted")
+	assert.True(t, exists(&rbacv1.ClusterRole{}, "adopted"), "resource owned by another CR should not be deleted")
+	assert.NoError(t, c.Get(context.TODO(), client.ObjectKey{Namespace: "default", Name: "local"}, &corev1.ConfigMap{}))
+}
+
+func TestReconcileVerifiesDependentsOutsideWatchedNamespace(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}
+	now := metav1.Now()
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	cr.SetNamespace("default")
+	cr.SetName("foo")
+	cr.SetDeletionTimestamp(&now)
+	cr.SetFinalizers([]string{finalizer})
+	key := client.ObjectKeyFromObject(cr)
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(gvk, meta.RESTScopeNamespace)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	dependent := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Namespace:         "other",
+		Name:              "dependent",
+		DeletionTimestamp: &now,
+		Finalizers:        []string{"example.com/cleanup"},
+		Annotations: map[string]string{
+			libhandler.NamespacedNameAnnotation: "default/foo",
+			libhandler.TypeAnnotation:           "Foo.example.com",
+		},
+	}}
+	live := fake.NewClientBuilder().WithObjects(cr, dependent).Build()
+	r := HelmOperatorReconciler{
+		Client:        namespacedClient{Client: live, namespace: "default", mapper: mapper},
+		EventRecorder: record.NewFakeRecorder(10),
+		GVK:           gvk,
+		ManagerFactory: fakeManagerFactory{&fakeManager{deployed: &rpb.Release{
+			Name: "foo",
+			Manifest: `---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dependent
+  namespace: other
+`,
+		}}},
+		VerifyDependentCleanup: true,
+		APIReader:              live,
+	}
+
+	// The dependent is not visible to the namespaced client, but it still
+	// exists, so the finalizer is kept.
+	result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{RequeueAfter: dependentCleanupRequeueDelay}, result)
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(gvk)
+	assert.NoError(t, live.Get(context.TODO(), key, actual))
+	assert.Contains(t, actual.GetFinalizers(), finalizer)
+}
+
+func TestDrain(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Nginx"}
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	cr.SetNamespace("default")
+	cr.SetName("test")
+	key := client.ObjectKeyFromObject(cr)
+	c := fake.NewClientBuilder().WithObjects(cr).Build()
+
+	tracker := drain.NewTracker(time.Minute)
+	stopped, stop := context.WithCancel(context.TODO())
+	stop()
+	assert.NoError(t, tracker.Start(stopped))
+
+	r := HelmOperatorReconciler{
+		Client:        c,
+		EventRecorder: record.NewFakeRecorder(10),
+		GVK:           gvk,
+		Drain:         tracker,
+	}
+
+	// Reconciles are not started once the operator is stopping.
+	result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{Requeue: true}, result)
+
+	assert.NoError(t, r.markInterrupted(context.TODO(), key))
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(gvk)
+	assert.NoError(t, c.Get(context.TODO(), key, actual))
+	conditions := types.StatusFor(actual).Conditions
+	if assert.Len(t, conditions, 1) {
+		assert.Equal(t, types.ConditionReleaseFailed, conditions[0].Type)
+		assert.Equal(t, types.StatusTrue, conditions[0].Status)
+		assert.Equal(t, types.ReasonInterrupted, conditions[0].Reason)
+	}
+}
+
+func TestDrainWaitsForHelmAction(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Nginx"}
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	cr.SetNamespace("default")
+	cr.SetName("test")
+	key := client.ObjectKeyFromObject(cr)
+	c := fake.NewClientBuilder().WithObjects(cr).Build()
+
+	manager := &blockingManager{
+		fakeManager: &fakeManager{deployed: &rpb.Release{Name: "test", Version: 1}},
+		started:     make(chan struct{}),
+		unblock:     make(chan struct{}),
+	}
+	r := HelmOperatorReconciler{
+		Client:          c,
+		EventRecorder:   record.NewFakeRecorder(10),
+		GVK:             gvk,
+		ManagerFactory:  fakeManagerFactory{manager},
+		ReconcilePeriod: time.Minute,
+		Drain:           drain.NewTracker(10 * time.Millisecond),
+	}
+
+	reconciled := make(chan error)
+	go func() {
+		_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		reconciled <- err
+	}()
+	<-manager.started
+
+	stopped, stop := context.WithCancel(context.TODO())
+	stop()
+	drained := make(chan error)
+	go func() { drained <- r.Drain.Start(stopped) }()
+
+	// The interrupted status is not written while the Helm action runs.
+	time.Sleep(50 * time.Millisecond)
+	select {
+	case <-drained:
+		t.Fatal("reconcile was interrupted while a Helm action was running")
+	default:
+	}
+
+	// Once the action returns, the reconcile cannot overwrite the interrupted
+	// status.
+	close(manager.unblock)
+	assert.NoError(t, <-drained)
+	<-reconciled
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(gvk)
+	assert.NoError(t, c.Get(context.TODO(), key, actual))
+	var reasons []types.HelmAppConditionReason
+	for _, condition := range types.StatusFor(actual).Conditions {
+		if condition.Type == types.ConditionReleaseFailed {
+			reasons = append(reasons, condition.Reason)
+		}
+	}
+	assert.Equal(t, []types.HelmAppConditionReason{types.ReasonInterrupted}, reasons)
+}
+
+// fakeManagerFactory returns manager for every resource.
+type fakeManagerFactory struct {
+	manager release.Manager
+}
+
+func (f fakeManagerFactory) NewManager(*unstructured.Unstructured, map[string]string) (release.Manager, error) {
+	return f.manager, nil
+}
+
+func (f fakeManagerFactory) ReleaseName(*unstructured.Unstructured) (string, error) {
+	return f.manager.ReleaseName(), nil
+}
+
+// fakeManager is a release manager of an installed release that needs no
+// upgrade and reconciles to deployed. It counts how often CRDs are applied.
+type fakeManager struct {
+	release.Manager
+	deployed    *rpb.Release
+	crdsApplied int
+}
+
+func (m *fakeManager) ReleaseName() string                                  { return m.deployed.Name }
+func (m *fakeManager) IsInstalled() bool                                    { return true }
+func (m *fakeManager) IsUpgradeRequired() bool                              { return false }
+func (m *fakeManager) Sync(context.Context, ...release.UpgradeOption) error { return nil }
+func (m *fakeManager) ReconcileRelease(context.Context) (*rpb.Release, error) {
+	return m.deployed, nil
+}
+func (m *fakeManager) UninstallRelease(context.Context, ...release.UninstallOption) (*rpb.Release, error) {
+	return m.deployed, nil
+}
+func (m *fakeManager) ApplyCRDs(context.Context) ([]types.HelmAppCRD, error) {
+	m.crdsApplied++
+	return nil, nil
+}
+
+// blockingManager is a fakeManager whose ReconcileRelease blocks until
+// unblock is closed, like a Helm action that keeps running after its reconcile
+// is interrupted.
+type blockingManager struct {
+	*fakeManager
+	started chan struct{}
+	unblock chan struct{}
+}
+
+func (m *blockingManager) ReconcileRelease(ctx context.Context) (*rpb.Release, error) {
+	close(m.started)
+	<-m.unblock
+	return m.fakeManager.ReconcileRelease(ctx)
+}
+
+// namespacedClient reads only objects in namespace, like a client backed by
+// a cache that is limited to the watched namespace.
+type namespacedClient struct {
+	client.Client
+	namespace string
+	mapper    meta.RESTMapper
+}
+
+func (c namespacedClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if key.Namespace != c.namespace {
+		return apierrors.NewNotFound(schema.GroupResource{}, key.Name)
+	}
+	return c.Client.Get(ctx, key, obj)
+}
+
+func (c namespacedClient) RESTMapper() meta.RESTMapper {
+	return c.mapper
+}
+
+func TestReconcileReadiness(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Nginx"}
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	cr.SetNamespace("default")
+	cr.SetName("test")
+	key := client.ObjectKeyFromObject(cr)
+
+	replicas := int32(2)
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "nginx"},
+		Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+		Status:     appsv1.DeploymentStatus{UpdatedReplicas: 2, AvailableReplicas: 1},
+	}
+	c := fake.NewClientBuilder().WithObjects(cr, deployment).Build()
+	r := HelmOperatorReconciler{
+		Client:          c,
+		EventRecorder:   record.NewFakeRecorder(10),
+		GVK:             gvk,
+		ReconcilePeriod: time.Minute,
+		ManagerFactory: fakeManagerFactory{&fakeManager{deployed: &rpb.Release{
+			Name:    "test",
+			Version: 2,
+			Manifest: `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: nginx
+`,
+		}}},
+		ReadinessEvaluator: readiness.NewEvaluator(c, c, testrestmapper.TestOnlyStaticRESTMapper(clientgoscheme.Scheme)),
+	}
+
+	readyCondition := func() types.HelmAppCondition {
+		actual := &unstructured.Unstructured{}
+		actual.SetGroupVersionKind(gvk)
+		assert.NoError(t, c.Get(context.TODO(), key, actual))
+		for _, condition := range types.StatusFor(actual).Conditions {
+			if condition.Type == types.ConditionReady {
+				return condition
+			}
+		}
+		t.Fatalf("resource has no %s condition", types.ConditionReady)
+		return types.HelmAppCondition{}
+	}
+
+	// The reconcile is repeated sooner than the reconcile period while the release is not ready.
+	result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{RequeueAfter: readinessRequeueDelay}, result)
+	condition := readyCondition()
+	assert.Equal(t, types.StatusFalse, condition.Status)
+	assert.Equal(t, types.ReasonResourcesNotReady, condition.Reason)
+	assert.Equal(t, "Deployment/nginx: 1/2 replicas available", condition.Message)
+
+	deployment.Status.AvailableReplicas = 2
+	assert.NoError(t, c.Status().Update(context.TODO(), deployment))
+	result, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.Equal(t, reconcile.Result{RequeueAfter: time.Minute}, result)
+	condition = readyCondition()
+	assert.Equal(t, types.StatusTrue, condition.Status)
+	assert.Equal(t, types.ReasonResourcesReady, condition.Reason)
+}
+
+func TestReconcileAppliesCRDs(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Nginx"}
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	cr.SetNamespace("default")
+	cr.SetName("test")
+	cr.SetFinalizers([]string{finalizer})
+	key := client.ObjectKeyFromObject(cr)
+
+	c := fake.NewClientBuilder().WithObjects(cr).Build()
+	manager := &fakeManager{deployed: &rpb.Release{Name: "test", Version: 2}}
+	r := HelmOperatorReconciler{
+		Client:          c,
+		EventRecorder:   record.NewFakeRecorder(10),
+		GVK:             gvk,
+		ReconcilePeriod: time.Minute,
+		ManagerFactory:  fakeManagerFactory{manager},
+	}
+
+	// CRDs are applied even if the release needs no upgrade, since a chart
+	// may only change its CRDs.
+	_, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, manager.crdsApplied)
+
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(gvk)
+	assert.NoError(t, c.Get(context.TODO(), key, actual))
+	actual.SetAnnotations(map[string]string{SkipCRDsAnnotation: "true"})
+	assert.NoError(t, c.Update(context.TODO(), actual))
+	_, err = r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, manager.crdsApplied)
+}
+
+func TestReconcilePaused(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Nginx"}
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	cr.SetNamespace("default")
+	cr.SetName("test")
+	cr.SetAnnotations(map[string]string{PausedAnnotation: "true"})
+	key := client.ObjectKeyFromObject(cr)
+
+	c := fake.NewClientBuilder().WithObjects(cr).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := HelmOperatorReconciler{
+		Client:        c,
+		EventRecorder: recorder,
+		GVK:           gvk,
+	}
+
+	// The Event and the condition are only recorded once while paused.
+	for i := 0; i < 2; i++ {
+		result, err := r.Reconcile(context.TODO(), reconcile.Request{NamespacedName: key})
+		assert.NoError(t, err)
+		assert.Equal(t, reconcile.Result{}, result)
+	}
+	assert.Len(t, recorder.Events, 1)
+	actual := &unstructured.Unstructured{}
+	actual.SetGroupVersionKind(gvk)
+	assert.NoError(t, c.Get(context.TODO(), key, actual))
+	assert.True(t, hasPausedCondition(types.StatusFor(actual)))
+}
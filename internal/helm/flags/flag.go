@@ -23,14 +23,17 @@ import (
 
 // Flags - Options to be used by a helm operator
 type Flags struct {
-	ReconcilePeriod         time.Duration
-	WatchesFile             string
-	MetricsAddress          string
-	EnableLeaderElection    bool
-	LeaderElectionID        string
-	LeaderElectionNamespace string
-	MaxConcurrentReconciles int
-	ProbeAddr               string
+	ReconcilePeriod          time.Duration
+	WatchesFile              string
+	MetricsAddress           string
+	EnableLeaderElection     bool
+	LeaderElectionID         string
+	LeaderElectionNamespace  string
+	MaxConcurrentReconciles  int
+	ProbeAddr                string
+	ReconcileStallThreshold  time.Duration
+	GracefulShutdownTimeout  time.Duration
+	EnableReleaseNameWebhook bool
 }
 
 // AddTo - Add the helm operator flags to the the flagset
@@ -55,6 +58,17 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 		":8081",
 		"The address the probe endpoint binds to.",
 	)
+	flagSet.DurationVar(&f.ReconcileStallThreshold,
+		"reconcile-stall-threshold",
+		10*time.Minute,
+		"How long a single reconcile may run before the readiness probe fails. Set to 0 to disable the check.",
+	)
+	flagSet.DurationVar(&f.GracefulShutdownTimeout,
+		"graceful-shutdown-timeout",
+		20*time.Second,
+		"How long to wait for in-flight reconciles to finish when the operator stops, before they are interrupted. "+
+			"The pod's termination grace period must be at least 10s longer.",
+	)
 	flagSet.BoolVar(&f.EnableLeaderElection,
 		"enable-leader-election",
 		false,
@@ -75,4 +89,9 @@ func (f *Flags) AddTo(flagSet *pflag.FlagSet) {
 		runtime.NumCPU(),
 		"Maximum number of concurrent reconciles for controllers.",
 	)
+	flagSet.BoolVar(&f.EnableReleaseNameWebhook,
+		"enable-release-name-webhook",
+		false,
+		"Serve a validating webhook that rejects custom resources whose Helm release name would collide with an existing release.",
+	)
 }
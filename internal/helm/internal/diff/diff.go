@@ -49,6 +49,26 @@ func Generate(a, b string) string {
 	return buff.String()
 }
 
+// GenerateChanges generates a diff between a and b that contains only the
+// added and removed lines, without color.
+func GenerateChanges(a, b string) string {
+	dmp := diffmatchpatch.New()
+
+	wSrc, wDst, warray := dmp.DiffLinesToRunes(a, b)
+	diffs := dmp.DiffMainRunes(wSrc, wDst, false)
+	diffs = dmp.DiffCharsToLines(diffs, warray)
+	var buff bytes.Buffer
+	for _, diff := range diffs {
+		switch diff.Type {
+		case diffmatchpatch.DiffInsert:
+			_, _ = buff.WriteString(prefixLines(diff.Text, "+"))
+		case diffmatchpatch.DiffDelete:
+			_, _ = buff.WriteString(prefixLines(diff.Text, "-"))
+		}
+	}
+	return buff.String()
+}
+
 func prefixLines(s, prefix string) string {
 	var buf bytes.Buffer
 	lines := strings.Split(s, "\n")
@@ -0,0 +1,29 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerateChanges(t *testing.T) {
+	a := "kind: ConfigMap\nname: foo\nreplicas: 1\n"
+	b := "kind: ConfigMap\nname: foo\nreplicas: 2\n"
+	assert.Equal(t, "-replicas: 1\n+replicas: 2\n", GenerateChanges(a, b))
+	assert.Equal(t, "", GenerateChanges(a, a))
+	assert.Equal(t, "+kind: ConfigMap\n+name: foo\n+replicas: 1\n", GenerateChanges("", a))
+}
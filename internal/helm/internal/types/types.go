@@ -55,11 +55,47 @@ type HelmAppRelease struct {
 	Manifest string `json:"manifest,omitempty"`
 }
 
+// HelmAppPreview describes the changes that the next install or upgrade of a
+// release would make, generated while the release is in preview mode.
+type HelmAppPreview struct {
+	// Diff contains the manifest lines that would be added or removed.
+	Diff string `json:"diff,omitempty"`
+	// Truncated is true if Diff was cut short to limit the status size.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+// HelmAppRevision summarizes an install or upgrade of a release.
+type HelmAppRevision struct {
+	Revision     int                    `json:"revision,omitempty"`
+	ChartVersion string                 `json:"chartVersion,omitempty"`
+	Timestamp    metav1.Time            `json:"timestamp"`
+	Outcome      HelmAppConditionReason `json:"outcome"`
+}
+
+// HelmAppCRD reports the outcome of applying one of the CRDs bundled in the
+// chart's crds/ directory.
+type HelmAppCRD struct {
+	Name    string           `json:"name"`
+	Action  HelmAppCRDAction `json:"action"`
+	Message string           `json:"message,omitempty"`
+}
+
+type HelmAppCRDAction string
+
+const (
+	CRDCreated   HelmAppCRDAction = "Created"
+	CRDUpdated   HelmAppCRDAction = "Updated"
+	CRDUnchanged HelmAppCRDAction = "Unchanged"
+	CRDRefused   HelmAppCRDAction = "Refused"
+)
+
 const (
 	ConditionInitialized    HelmAppConditionType = "Initialized"
 	ConditionDeployed       HelmAppConditionType = "Deployed"
 	ConditionReleaseFailed  HelmAppConditionType = "ReleaseFailed"
 	ConditionIrreconcilable HelmAppConditionType = "Irreconcilable"
+	ConditionReady          HelmAppConditionType = "Ready"
+	ConditionPaused         HelmAppConditionType = "Paused"
 
 	StatusTrue    ConditionStatus = "True"
 	StatusFalse   ConditionStatus = "False"
@@ -68,15 +104,27 @@ const (
 	ReasonInstallSuccessful   HelmAppConditionReason = "InstallSuccessful"
 	ReasonUpgradeSuccessful   HelmAppConditionReason = "UpgradeSuccessful"
 	ReasonUninstallSuccessful HelmAppConditionReason = "UninstallSuccessful"
+	ReasonReconcileSuccessful HelmAppConditionReason = "ReconcileSuccessful"
 	ReasonInstallError        HelmAppConditionReason = "InstallError"
 	ReasonUpgradeError        HelmAppConditionReason = "UpgradeError"
 	ReasonReconcileError      HelmAppConditionReason = "ReconcileError"
 	ReasonUninstallError      HelmAppConditionReason = "UninstallError"
+	ReasonPreviewError        HelmAppConditionReason = "PreviewError"
+	ReasonResourcesReady      HelmAppConditionReason = "ResourcesReady"
+	ReasonResourcesNotReady   HelmAppConditionReason = "ResourcesNotReady"
+	ReasonReadinessError      HelmAppConditionReason = "ReadinessError"
+	ReasonInvalidOptions      HelmAppConditionReason = "InvalidOptions"
+	ReasonCRDUpgradeError     HelmAppConditionReason = "CRDUpgradeError"
+	ReasonPaused              HelmAppConditionReason = "Paused"
+	ReasonInterrupted         HelmAppConditionReason = "ReconcileInterrupted"
 )
 
 type HelmAppStatus struct {
 	Conditions      []HelmAppCondition `json:"conditions"`
 	DeployedRelease *HelmAppRelease    `json:"deployedRelease,omitempty"`
+	Preview         *HelmAppPreview    `json:"preview,omitempty"`
+	History         []HelmAppRevision  `json:"history,omitempty"`
+	CRDs            []HelmAppCRD       `json:"crds,omitempty"`
 }
 
 func (s *HelmAppStatus) ToMap() (map[string]interface{}, error) {
@@ -129,6 +177,17 @@ func (s *HelmAppStatus) RemoveCondition(conditionType HelmAppConditionType) *Hel
 	return s
 }
 
+// AddRevision appends revision to the status history, dropping the oldest
+// revisions so that at most limit revisions are kept. AddRevision does not
+// update the resource in the cluster.
+func (s *HelmAppStatus) AddRevision(revision HelmAppRevision, limit int) *HelmAppStatus {
+	s.History = append(s.History, revision)
+	if limit > 0 && len(s.History) > limit {
+		s.History = append([]HelmAppRevision(nil), s.History[len(s.History)-limit:]...)
+	}
+	return s
+}
+
 // StatusFor safely returns a typed status block from a custom resource.
 func StatusFor(cr *unstructured.Unstructured) *HelmAppStatus {
 	switch s := cr.Object["status"].(type) {
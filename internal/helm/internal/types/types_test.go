@@ -59,6 +59,24 @@ func TestRemoveCondition(t *testing.T) {
 	assert.Empty(t, actual.Conditions)
 }
 
+func TestAddRevision(t *testing.T) {
+	status := &HelmAppStatus{}
+	for i := 1; i <= 3; i++ {
+		status.AddRevision(HelmAppRevision{Revision: i, Outcome: ReasonUpgradeSuccessful}, 2)
+	}
+	assert.Len(t, status.History, 2)
+	assert.Equal(t, 2, status.History[0].Revision)
+	assert.Equal(t, 3, status.History[1].Revision)
+
+	newStatus, err := status.ToMap()
+	assert.NoError(t, err)
+	resource := newTestResource()
+	resource.Object["status"] = newStatus
+	actual := StatusFor(resource)
+	assert.Equal(t, 3, actual.History[1].Revision)
+	assert.Equal(t, ReasonUpgradeSuccessful, actual.History[1].Outcome)
+}
+
 func TestStatusForEmpty(t *testing.T) {
 	status := StatusFor(newTestResource())
 
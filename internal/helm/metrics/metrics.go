@@ -15,7 +15,15 @@
 package metrics
 
 import (
+	"fmt"
+	"sync"
+	"time"
+
 	"github.com/prometheus/client_golang/prometheus"
+	rpb "helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/types"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 
 	sdkVersion "github.com/operator-framework/operator-sdk/internal/version"
 )
@@ -24,6 +32,14 @@ const (
 	subsystem = "helm_operator"
 )
 
+// Release actions recorded by the action metrics.
+const (
+	ActionInstall   = "install"
+	ActionUpgrade   = "upgrade"
+	ActionUninstall = "uninstall"
+	ActionReconcile = "reconcile"
+)
+
 var (
 	buildInfo = prometheus.NewGauge(
 		prometheus.GaugeOpts{
@@ -36,9 +52,179 @@ var (
 			},
 		},
 	)
+
+	releaseActions = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Subsystem: subsystem,
+			Name:      "release_actions_total",
+			Help:      "Count of release actions and their outcomes.",
+		},
+		[]string{
+			"GVK",
+			"action",
+			"reason",
+		})
+
+	releaseActionDurations = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Subsystem: subsystem,
+			Name:      "release_action_duration_seconds",
+			Help:      "How long in seconds a release action takes.",
+			Buckets:   []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600},
+		},
+		[]string{
+			"GVK",
+			"action",
+		})
+
+	releases = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Subsystem: subsystem,
+			Name:      "releases",
+			Help:      "Number of releases per release status.",
+		},
+		[]string{
+			"GVK",
+			"status",
+		})
+
+	lastSuccess = newLastSuccessCollector()
+
+	// releaseStatuses holds the last known status of each release, so that the
+	// releases gauge can be adjusted when a release changes status.
+	releaseStatusesMu sync.Mutex
+	releaseStatuses   = map[releaseKey]rpb.Status{}
 )
 
+type releaseKey struct {
+	gvk string
+	types.NamespacedName
+}
+
+func init() {
+	metrics.Registry.MustRegister(releaseActions)
+	metrics.Registry.MustRegister(releaseActionDurations)
+	metrics.Registry.MustRegister(releases)
+	metrics.Registry.MustRegister(lastSuccess)
+}
+
+// We will never want to panic our app because of metric saving.
+// Therefore, we will recover our panics here and error log them
+// for later diagnosis but will never fail the app.
+func recoverMetricPanic() {
+	if r := recover(); r != nil {
+		logf.Log.WithName("metrics").Error(fmt.Errorf("%v", r),
+			"Recovering from metric function")
+	}
+}
+
 func RegisterBuildInfo(r prometheus.Registerer) {
 	buildInfo.Set(1)
 	r.MustRegister(buildInfo)
 }
+
+// ActionCompleted counts a completed release action. reason is the
+// HelmAppConditionReason that describes the outcome, e.g. "InstallSuccessful"
+// or "InstallError".
+func ActionCompleted(gvk, action, reason string) {
+	defer recoverMetricPanic()
+	releaseActions.WithLabelValues(gvk, action, reason).Inc()
+}
+
+// ActionTimer returns a timer that records the duration of a release action
+// when its ObserveDuration method is called.
+func ActionTimer(gvk, action string) *prometheus.Timer {
+	defer recoverMetricPanic()
+	return prometheus.NewTimer(prometheus.ObserverFunc(func(duration float64) {
+		releaseActionDurations.WithLabelValues(gvk, action).Observe(duration)
+	}))
+}
+
+// SetReleaseStatus records the status of the release managed by the custom
+// resource with the given key.
+func SetReleaseStatus(gvk string, key types.NamespacedName, status rpb.Status) {
+	defer recoverMetricPanic()
+	releaseStatusesMu.Lock()
+	defer releaseStatusesMu.Unlock()
+
+	k := releaseKey{gvk: gvk, NamespacedName: key}
+	if old, ok := releaseStatuses[k]; ok {
+		if old == status {
+			return
+		}
+		releases.WithLabelValues(gvk, old.String()).Dec()
+	}
+	releaseStatuses[k] = status
+	releases.WithLabelValues(gvk, status.String()).Inc()
+}
+
+// ReconcileSucceeded records that the custom resource with the given key was
+// reconciled successfully.
+func ReconcileSucceeded(gvk string, key types.NamespacedName) {
+	defer recoverMetricPanic()
+	lastSuccess.set(releaseKey{gvk: gvk, NamespacedName: key}, time.Now())
+}
+
+// ReleaseDeleted removes the metrics of the custom resource with the given
+// key, after its release was uninstalled.
+func ReleaseDeleted(gvk string, key types.NamespacedName) {
+	defer recoverMetricPanic()
+	k := releaseKey{gvk: gvk, NamespacedName: key}
+	lastSuccess.delete(k)
+
+	releaseStatusesMu.Lock()
+	defer releaseStatusesMu.Unlock()
+	if old, ok := releaseStatuses[k]; ok {
+		releases.WithLabelValues(gvk, old.String()).Dec()
+		delete(releaseStatuses, k)
+	}
+}
+
+// lastSuccessCollector reports the time since the last successful reconcile
+// of each custom resource. The value is computed when the metric is
+// collected, so that it keeps growing while reconciles fail.
+type lastSuccessCollector struct {
+	desc *prometheus.Desc
+
+	mu    sync.Mutex
+	times map[releaseKey]time.Time
+	now   func() time.Time
+}
+
+func newLastSuccessCollector() *lastSuccessCollector {
+	return &lastSuccessCollector{
+		desc: prometheus.NewDesc(
+			prometheus.BuildFQName("", subsystem, "seconds_since_last_successful_reconcile"),
+			"Seconds since the custom resource was last reconciled successfully.",
+			[]string{"GVK", "namespace", "name"}, nil,
+		),
+		times: map[releaseKey]time.Time{},
+		now:   time.Now,
+	}
+}
+
+func (c *lastSuccessCollector) set(k releaseKey, t time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.times[k] = t
+}
+
+func (c *lastSuccessCollector) delete(k releaseKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.times, k)
+}
+
+func (c *lastSuccessCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c *lastSuccessCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	for k, t := range c.times {
+		ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, now.Sub(t).Seconds(),
+			k.gvk, k.Namespace, k.Name)
+	}
+}
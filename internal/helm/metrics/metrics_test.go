@@ -0,0 +1,71 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	rpb "helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestReleaseStatus(t *testing.T) {
+	gvk := "example.com/v1, Kind=Status"
+	foo := types.NamespacedName{Namespace: "default", Name: "foo"}
+	bar := types.NamespacedName{Namespace: "default", Name: "bar"}
+
+	SetReleaseStatus(gvk, foo, rpb.StatusDeployed)
+	SetReleaseStatus(gvk, bar, rpb.StatusDeployed)
+	SetReleaseStatus(gvk, bar, rpb.StatusDeployed)
+	assert.Equal(t, 2.0, testutil.ToFloat64(releases.WithLabelValues(gvk, "deployed")))
+
+	SetReleaseStatus(gvk, bar, rpb.StatusFailed)
+	assert.Equal(t, 1.0, testutil.ToFloat64(releases.WithLabelValues(gvk, "deployed")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(releases.WithLabelValues(gvk, "failed")))
+
+	ReleaseDeleted(gvk, bar)
+	assert.Equal(t, 0.0, testutil.ToFloat64(releases.WithLabelValues(gvk, "failed")))
+}
+
+func TestActionCompleted(t *testing.T) {
+	gvk := "example.com/v1, Kind=Action"
+	ActionCompleted(gvk, ActionInstall, "InstallSuccessful")
+	ActionCompleted(gvk, ActionInstall, "InstallSuccessful")
+	ActionCompleted(gvk, ActionInstall, "InstallError")
+	assert.Equal(t, 2.0, testutil.ToFloat64(releaseActions.WithLabelValues(gvk, ActionInstall, "InstallSuccessful")))
+	assert.Equal(t, 1.0, testutil.ToFloat64(releaseActions.WithLabelValues(gvk, ActionInstall, "InstallError")))
+}
+
+func TestLastSuccessCollector(t *testing.T) {
+	c := newLastSuccessCollector()
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	c.now = func() time.Time { return now }
+	k := releaseKey{gvk: "example.com/v1, Kind=Foo", NamespacedName: types.NamespacedName{Namespace: "default", Name: "foo"}}
+	c.set(k, now.Add(-90*time.Second))
+
+	expected := `
+# HELP helm_operator_seconds_since_last_successful_reconcile Seconds since the custom resource was last reconciled successfully.
+# TYPE helm_operator_seconds_since_last_successful_reconcile gauge
+helm_operator_seconds_since_last_successful_reconcile{GVK="example.com/v1, Kind=Foo",name="foo",namespace="default"} 90
+`
+	assert.NoError(t, testutil.CollectAndCompare(c, strings.NewReader(expected)))
+
+	c.delete(k)
+	assert.Equal(t, 0, testutil.CollectAndCount(c))
+}
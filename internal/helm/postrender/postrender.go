@@ -0,0 +1,275 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package postrender provides a Helm post-renderer that patches the
+// manifests rendered from a chart before they are installed or upgraded.
+package postrender
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	jsonpatch "github.com/evanphx/json-patch"
+	"helm.sh/helm/v3/pkg/postrender"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/strategicpatch"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+)
+
+// Config defines the changes a post-renderer makes to rendered manifests.
+type Config struct {
+	// Patches are applied, in order, to the manifests matching their target.
+	Patches []Patch `json:"patches,omitempty"`
+	// Images maps image repositories to the repositories that replace them in
+	// every container, e.g. "docker.io/nginx: registry.example.com/nginx".
+	// Image tags and digests are preserved.
+	Images map[string]string `json:"images,omitempty"`
+}
+
+// Patch is a kustomize-style patch. Patch holds either a strategic merge
+// patch, written as a YAML object, or a JSON 6902 patch, written as a YAML
+// list of operations.
+type Patch struct {
+	Target Target `json:"target"`
+	Patch  string `json:"patch"`
+}
+
+// Target selects the objects a patch applies to. Empty fields match any
+// value.
+type Target struct {
+	Group     string `json:"group,omitempty"`
+	Version   string `json:"version,omitempty"`
+	Kind      string `json:"kind,omitempty"`
+	Name      string `json:"name,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// blank assignment to verify that postRenderer implements postrender.PostRenderer
+var _ postrender.PostRenderer = &postRenderer{}
+
+type postRenderer struct {
+	patches []patch
+	images  map[string]string
+}
+
+type patch struct {
+	target Target
+	// json6902 is set for JSON 6902 patches.
+	json6902 jsonpatch.Patch
+	// merge is set for strategic merge patches.
+	merge []byte
+}
+
+// New returns a post-renderer that applies c. It returns an error if any
+// patch in c cannot be parsed.
+func New(c Config) (postrender.PostRenderer, error) {
+	pr := &postRenderer{images: c.Images}
+	for i, p := range c.Patches {
+		parsed, err := parsePatch(p)
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch %d: %w", i, err)
+		}
+		pr.patches = append(pr.patches, parsed)
+	}
+	return pr, nil
+}
+
+func parsePatch(p Patch) (patch, error) {
+	data, err := yaml.YAMLToJSON([]byte(p.Patch))
+	if err != nil {
+		return patch{}, err
+	}
+	data = bytes.TrimSpace(data)
+	switch {
+	case bytes.HasPrefix(data, []byte("[")):
+		ops, err := jsonpatch.DecodePatch(data)
+		if err != nil {
+			return patch{}, err
+		}
+		return patch{target: p.Target, json6902: ops}, nil
+	case bytes.HasPrefix(data, []byte("{")):
+		return patch{target: p.Target, merge: data}, nil
+	default:
+		return patch{}, fmt.Errorf("patch must be a YAML object or list")
+	}
+}
+
+var separator = regexp.MustCompile(`(?m)^---[ \t]*$`)
+
+// Run applies the post-renderer's patches and image rewrites to each manifest
+// in renderedManifests. Manifests that are not changed are returned as is.
+func (pr *postRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	out := &bytes.Buffer{}
+	for _, doc := range separator.Split(renderedManifests.String(), -1) {
+		if strings.TrimSpace(doc) == "" {
+			continue
+		}
+		modified, err := pr.renderDoc(doc)
+		if err != nil {
+			return nil, err
+		}
+		out.WriteString("---")
+		if !strings.HasPrefix(modified, "\n") {
+			out.WriteString("\n")
+		}
+		out.WriteString(modified)
+		if !strings.HasSuffix(modified, "\n") {
+			out.WriteString("\n")
+		}
+	}
+	return out, nil
+}
+
+// renderDoc applies the post-renderer to a single YAML document. Leading
+// comments, such as Helm's "# Source:" lines, are preserved.
+func (pr *postRenderer) renderDoc(doc string) (string, error) {
+	obj := &unstructured.Unstructured{}
+	if err := yaml.Unmarshal([]byte(doc), &obj.Object); err != nil {
+		return "", fmt.Errorf("failed to parse manifest: %w", err)
+	}
+	if len(obj.Object) == 0 {
+		return doc, nil
+	}
+
+	changed := false
+	for _, p := range pr.patches {
+		if !p.matches(obj) {
+			continue
+		}
+		if err := p.apply(obj); err != nil {
+			return "", fmt.Errorf("failed to patch %s %s: %w", obj.GetKind(), obj.GetName(), err)
+		}
+		changed = true
+	}
+	if rewriteImages(obj.Object, pr.images) {
+		changed = true
+	}
+	if !changed {
+		return doc, nil
+	}
+
+	body, err := yaml.Marshal(obj.Object)
+	if err != nil {
+		return "", err
+	}
+	return leadingComments(doc) + string(body), nil
+}
+
+func (p patch) matches(obj *unstructured.Unstructured) bool {
+	gvk := obj.GroupVersionKind()
+	t := p.target
+	return (t.Group == "" || t.Group == gvk.Group) &&
+		(t.Version == "" || t.Version == gvk.Version) &&
+		(t.Kind == "" || t.Kind == gvk.Kind) &&
+		(t.Name == "" || t.Name == obj.GetName()) &&
+		(t.Namespace == "" || t.Namespace == obj.GetNamespace())
+}
+
+func (p patch) apply(obj *unstructured.Unstructured) error {
+	original, err := json.Marshal(obj.Object)
+	if err != nil {
+		return err
+	}
+
+	var patched []byte
+	if p.json6902 != nil {
+		patched, err = p.json6902.Apply(original)
+	} else if typed, newErr := scheme.Scheme.New(obj.GroupVersionKind()); newErr == nil {
+		patched, err = strategicpatch.StrategicMergePatch(original, p.merge, typed)
+	} else if runtime.IsNotRegisteredError(newErr) {
+		// Strategic merge patches are not supported for types without Go
+		// structs, such as custom resources, so fall back to a JSON merge patch.
+		patched, err = jsonpatch.MergePatch(original, p.merge)
+	} else {
+		err = newErr
+	}
+	if err != nil {
+		return err
+	}
+
+	obj.Object = map[string]interface{}{}
+	return json.Unmarshal(patched, &obj.Object)
+}
+
+// rewriteImages replaces the repository of each container image in obj that
+// has an entry in images. It returns true if any image was replaced.
+func rewriteImages(obj interface{}, images map[string]string) bool {
+	if len(images) == 0 {
+		return false
+	}
+	changed := false
+	switch o := obj.(type) {
+	case map[string]interface{}:
+		for k, v := range o {
+			if k == "containers" || k == "initContainers" || k == "ephemeralContainers" {
+				if containers, ok := v.([]interface{}); ok {
+					for _, c := range containers {
+						if container, ok := c.(map[string]interface{}); ok {
+							if image, ok := container["image"].(string); ok {
+								if newImage, ok := rewriteImage(image, images); ok {
+									container["image"] = newImage
+									changed = true
+								}
+							}
+						}
+					}
+				}
+			}
+			if rewriteImages(v, images) {
+				changed = true
+			}
+		}
+	case []interface{}:
+		for _, v := range o {
+			if rewriteImages(v, images) {
+				changed = true
+			}
+		}
+	}
+	return changed
+}
+
+func rewriteImage(image string, images map[string]string) (string, bool) {
+	repo, suffix := image, ""
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo, suffix = repo[:i], repo[i:]
+	}
+	// A colon after the last slash separates the tag; one before it belongs
+	// to a registry port.
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo, suffix = repo[:i], repo[i:]+suffix
+	}
+	newRepo, ok := images[repo]
+	if !ok || newRepo == repo {
+		return image, false
+	}
+	return newRepo + suffix, true
+}
+
+func leadingComments(doc string) string {
+	var b strings.Builder
+	for _, line := range strings.SplitAfter(doc, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed != "" && !strings.HasPrefix(trimmed, "#") {
+			break
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
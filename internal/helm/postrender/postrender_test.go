@@ -0,0 +1,150 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package postrender
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const manifests = `---
+# Source: test/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        image: docker.io/nginx:1.19
+      - name: sidecar
+        image: quay.io/sidecar:v1
+---
+# Source: test/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: test
+spec:
+  type: ClusterIP
+`
+
+func TestRunStrategicMergePatch(t *testing.T) {
+	pr, err := New(Config{Patches: []Patch{{
+		Target: Target{Kind: "Deployment"},
+		Patch: `
+spec:
+  template:
+    spec:
+      containers:
+      - name: app
+        resources:
+          limits:
+            cpu: 100m
+`,
+	}}})
+	assert.NoError(t, err)
+
+	out, err := pr.Run(bytes.NewBufferString(manifests))
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "# Source: test/templates/deployment.yaml\n")
+	assert.Contains(t, out.String(), "cpu: 100m")
+	// The strategic merge patch merges the container list by name.
+	assert.Contains(t, out.String(), "image: quay.io/sidecar:v1")
+	assert.Contains(t, out.String(), "---\n# Source: test/templates/service.yaml\napiVersion: v1\nkind: Service\n")
+}
+
+func TestRunJSON6902Patch(t *testing.T) {
+	pr, err := New(Config{Patches: []Patch{{
+		Target: Target{Version: "v1", Kind: "Service", Name: "test"},
+		Patch: `
+- op: replace
+  path: /spec/type
+  value: NodePort
+`,
+	}}})
+	assert.NoError(t, err)
+
+	out, err := pr.Run(bytes.NewBufferString(manifests))
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "type: NodePort")
+	assert.NotContains(t, out.String(), "type: ClusterIP")
+}
+
+func TestRunMergePatchUnregisteredKind(t *testing.T) {
+	pr, err := New(Config{Patches: []Patch{{
+		Target: Target{Kind: "Foo"},
+		Patch:  "metadata:\n  labels:\n    foo: bar\n",
+	}}})
+	assert.NoError(t, err)
+
+	out, err := pr.Run(bytes.NewBufferString("---\napiVersion: example.com/v1\nkind: Foo\nmetadata:\n  name: test\n"))
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "foo: bar")
+}
+
+func TestRunImages(t *testing.T) {
+	pr, err := New(Config{Images: map[string]string{"docker.io/nginx": "registry.example.com:5000/nginx"}})
+	assert.NoError(t, err)
+
+	out, err := pr.Run(bytes.NewBufferString(manifests))
+	assert.NoError(t, err)
+	assert.Contains(t, out.String(), "image: registry.example.com:5000/nginx:1.19")
+	assert.Contains(t, out.String(), "image: quay.io/sidecar:v1")
+}
+
+func TestRunUnchanged(t *testing.T) {
+	pr, err := New(Config{})
+	assert.NoError(t, err)
+
+	out, err := pr.Run(bytes.NewBufferString(manifests))
+	assert.NoError(t, err)
+	assert.Equal(t, manifests, out.String())
+}
+
+func TestNewInvalidPatch(t *testing.T) {
+	_, err := New(Config{Patches: []Patch{{Patch: "just a string"}}})
+	assert.Error(t, err)
+
+	_, err = New(Config{Patches: []Patch{{Patch: "spec: [unterminated"}}})
+	assert.Error(t, err)
+}
+
+func TestRewriteImage(t *testing.T) {
+	images := map[string]string{
+		"nginx":                "mirror/nginx",
+		"localhost:5000/app":   "registry/app",
+		"docker.io/library/db": "registry/db",
+	}
+	testCases := []struct {
+		image    string
+		expected string
+	}{
+		{"nginx", "mirror/nginx"},
+		{"nginx:1.19", "mirror/nginx:1.19"},
+		{"nginx@sha256:abc", "mirror/nginx@sha256:abc"},
+		{"localhost:5000/app:v1", "registry/app:v1"},
+		{"docker.io/library/db:1", "registry/db:1"},
+		{"other:1", "other:1"},
+	}
+	for _, tc := range testCases {
+		actual, _ := rewriteImage(tc.image, images)
+		assert.Equal(t, tc.expected, actual, tc.image)
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readiness
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DeploymentChecker reports a Deployment as ready once its latest generation
+// has been observed and all of its replicas are updated and available.
+func DeploymentChecker(_ context.Context, _ client.Reader, obj *unstructured.Unstructured) (bool, string, error) {
+	d := &appsv1.Deployment{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, d); err != nil {
+		return false, "", err
+	}
+	if d.Status.ObservedGeneration < d.Generation {
+		return false, "rollout not yet observed", nil
+	}
+	replicas := replicasOrDefault(d.Spec.Replicas)
+	if d.Status.UpdatedReplicas < replicas {
+		return false, fmt.Sprintf("%d/%d replicas updated", d.Status.UpdatedReplicas, replicas), nil
+	}
+	if d.Status.AvailableReplicas < replicas {
+		return false, fmt.Sprintf("%d/%d replicas available", d.Status.AvailableReplicas, replicas), nil
+	}
+	return true, "", nil
+}
+
+// StatefulSetChecker reports a StatefulSet as ready once its latest
+// generation has been observed and all of its replicas are updated and ready.
+func StatefulSetChecker(_ context.Context, _ client.Reader, obj *unstructured.Unstructured) (bool, string, error) {
+	s := &appsv1.StatefulSet{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, s); err != nil {
+		return false, "", err
+	}
+	if s.Status.ObservedGeneration < s.Generation {
+		return false, "rollout not yet observed", nil
+	}
+	replicas := replicasOrDefault(s.Spec.Replicas)
+	if s.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType && s.Status.UpdatedReplicas < replicas {
+		return false, fmt.Sprintf("%d/%d replicas updated", s.Status.UpdatedReplicas, replicas), nil
+	}
+	if s.Status.ReadyReplicas < replicas {
+		return false, fmt.Sprintf("%d/%d replicas ready", s.Status.ReadyReplicas, replicas), nil
+	}
+	return true, "", nil
+}
+
+// JobChecker reports a Job as ready once it has completed.
+func JobChecker(_ context.Context, _ client.Reader, obj *unstructured.Unstructured) (bool, string, error) {
+	j := &batchv1.Job{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, j); err != nil {
+		return false, "", err
+	}
+	for _, c := range j.Status.Conditions {
+		if c.Status != corev1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case batchv1.JobComplete:
+			return true, "", nil
+		case batchv1.JobFailed:
+			return false, fmt.Sprintf("failed: %s", c.Message), nil
+		}
+	}
+	return false, "not complete", nil
+}
+
+// PVCChecker reports a PersistentVolumeClaim as ready once it is bound.
+func PVCChecker(_ context.Context, _ client.Reader, obj *unstructured.Unstructured) (bool, string, error) {
+	pvc := &corev1.PersistentVolumeClaim{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, pvc); err != nil {
+		return false, "", err
+	}
+	if pvc.Status.Phase != corev1.ClaimBound {
+		return false, fmt.Sprintf("phase is %q", pvc.Status.Phase), nil
+	}
+	return true, "", nil
+}
+
+// ServiceChecker reports a Service as ready once its Endpoints have at least
+// one ready address. Services without a selector and ExternalName Services
+// have no managed Endpoints, so they are always ready.
+func ServiceChecker(ctx context.Context, c client.Reader, obj *unstructured.Unstructured) (bool, string, error) {
+	svc := &corev1.Service{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(obj.Object, svc); err != nil {
+		return false, "", err
+	}
+	if svc.Spec.Type == corev1.ServiceTypeExternalName || len(svc.Spec.Selector) == 0 {
+		return true, "", nil
+	}
+
+	endpoints := &unstructured.Unstructured{}
+	endpoints.SetAPIVersion("v1")
+	endpoints.SetKind("Endpoints")
+	err := c.Get(ctx, client.ObjectKey{Namespace: svc.Namespace, Name: svc.Name}, endpoints)
+	if apierrors.IsNotFound(err) {
+		return false, "no endpoints", nil
+	}
+	if err != nil {
+		return false, "", err
+	}
+	ep := &corev1.Endpoints{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(endpoints.Object, ep); err != nil {
+		return false, "", err
+	}
+	for _, subset := range ep.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true, "", nil
+		}
+	}
+	return false, "no ready endpoints", nil
+}
+
+func replicasOrDefault(replicas *int32) int32 {
+	if replicas == nil {
+		return 1
+	}
+	return *replicas
+}
@@ -0,0 +1,122 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package readiness evaluates whether the objects in a Helm release are
+// ready, e.g. whether Deployments have rolled out and Jobs have completed.
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/yaml"
+)
+
+// Checker reports whether obj, the live state of an object in a release, is
+// ready. If it is not ready, Checker returns a short reason.
+type Checker func(ctx context.Context, c client.Reader, obj *unstructured.Unstructured) (bool, string, error)
+
+// Evaluator evaluates the readiness of the objects in a release manifest.
+// Objects of kinds without a registered Checker are considered ready.
+type Evaluator struct {
+	client   client.Reader
+	uncached client.Reader
+	mapper   meta.RESTMapper
+	checkers map[schema.GroupKind]Checker
+}
+
+// NewEvaluator returns an Evaluator that has Checkers registered for
+// Deployments, StatefulSets, Jobs, PersistentVolumeClaims and Services.
+// It reads objects in the namespace of a release with c, which can be the
+// manager's cache since that namespace is watched, and objects in other
+// namespaces, which may not be, with uncached. Cluster-scoped objects, as
+// determined by mapper, are also read with uncached.
+func NewEvaluator(c, uncached client.Reader, mapper meta.RESTMapper) *Evaluator {
+	e := &Evaluator{client: c, uncached: uncached, mapper: mapper, checkers: map[schema.GroupKind]Checker{}}
+	e.Register(schema.GroupKind{Group: "apps", Kind: "Deployment"}, DeploymentChecker)
+	e.Register(schema.GroupKind{Group: "apps", Kind: "StatefulSet"}, StatefulSetChecker)
+	e.Register(schema.GroupKind{Group: "batch", Kind: "Job"}, JobChecker)
+	e.Register(schema.GroupKind{Kind: "PersistentVolumeClaim"}, PVCChecker)
+	e.Register(schema.GroupKind{Kind: "Service"}, ServiceChecker)
+	return e
+}
+
+// Register sets the Checker for objects of kind gk, replacing any existing
+// Checker. A nil Checker removes the check for gk.
+func (e *Evaluator) Register(gk schema.GroupKind, checker Checker) {
+	if checker == nil {
+		delete(e.checkers, gk)
+		return
+	}
+	e.checkers[gk] = checker
+}
+
+// Evaluate checks the objects in manifest and returns a description of each
+// object that is not ready, sorted. Namespaced objects without a namespace are
+// looked up in namespace.
+func (e *Evaluator) Evaluate(ctx context.Context, manifest, namespace string) ([]string, error) {
+	var unready []string
+	for _, m := range releaseutil.SplitManifests(manifest) {
+		var u unstructured.Unstructured
+		if err := yaml.Unmarshal([]byte(m), &u.Object); err != nil {
+			return nil, fmt.Errorf("failed to parse manifest: %w", err)
+		}
+		gvk := u.GroupVersionKind()
+		checker, ok := e.checkers[gvk.GroupKind()]
+		if !ok {
+			continue
+		}
+
+		mapping, err := e.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get REST mapping for %s: %w", gvk, err)
+		}
+		key := client.ObjectKey{Namespace: u.GetNamespace(), Name: u.GetName()}
+		if mapping.Scope.Name() == meta.RESTScopeNameRoot {
+			key.Namespace = ""
+		} else if key.Namespace == "" {
+			key.Namespace = namespace
+		}
+		reader := e.client
+		if key.Namespace != namespace {
+			reader = e.uncached
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(gvk)
+		if err := reader.Get(ctx, key, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				unready = append(unready, fmt.Sprintf("%s/%s: not found", gvk.Kind, key.Name))
+				continue
+			}
+			return nil, fmt.Errorf("failed to get %s %s: %w", gvk.Kind, key, err)
+		}
+
+		ready, reason, err := checker(ctx, reader, obj)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check readiness of %s %s: %w", gvk.Kind, key, err)
+		}
+		if !ready {
+			unready = append(unready, fmt.Sprintf("%s/%s: %s", gvk.Kind, key.Name, reason))
+		}
+	}
+	sort.Strings(unready)
+	return unready, nil
+}
@@ -0,0 +1,208 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package readiness
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta/testrestmapper"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const manifest = `---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+---
+apiVersion: batch/v1
+kind: Job
+metadata:
+  name: migrate
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+`
+
+var mapper = testrestmapper.TestOnlyStaticRESTMapper(scheme.Scheme)
+
+func newObjects(ready bool) []runtime.Object {
+	replicas := int32(2)
+	available := replicas
+	jobCondition := batchv1.JobComplete
+	phase := corev1.ClaimBound
+	addresses := []corev1.EndpointAddress{{IP: "10.0.0.1"}}
+	if !ready {
+		available = 1
+		jobCondition = batchv1.JobFailed
+		phase = corev1.ClaimPending
+		addresses = nil
+	}
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Namespace: "ns", Name: name, Generation: 1}
+	}
+	return []runtime.Object{
+		&appsv1.Deployment{
+			ObjectMeta: meta("app"),
+			Spec:       appsv1.DeploymentSpec{Replicas: &replicas},
+			Status: appsv1.DeploymentStatus{
+				ObservedGeneration: 1,
+				UpdatedReplicas:    replicas,
+				AvailableReplicas:  available,
+			},
+		},
+		&batchv1.Job{
+			ObjectMeta: meta("migrate"),
+			Status: batchv1.JobStatus{Conditions: []batchv1.JobCondition{
+				{Type: jobCondition, Status: corev1.ConditionTrue, Message: "backoff limit exceeded"},
+			}},
+		},
+		&corev1.PersistentVolumeClaim{
+			ObjectMeta: meta("data"),
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: phase},
+		},
+		&corev1.Service{
+			ObjectMeta: meta("app"),
+			Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "app"}},
+		},
+		&corev1.Endpoints{
+			ObjectMeta: meta("app"),
+			Subsets:    []corev1.EndpointSubset{{Addresses: addresses}},
+		},
+	}
+}
+
+func TestEvaluateReady(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, newObjects(true)...)
+	e := NewEvaluator(c, c, mapper)
+	unready, err := e.Evaluate(context.TODO(), manifest, "ns")
+	assert.NoError(t, err)
+	assert.Empty(t, unready)
+}
+
+func TestEvaluateNotReady(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, newObjects(false)...)
+	e := NewEvaluator(c, c, mapper)
+	unready, err := e.Evaluate(context.TODO(), manifest, "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		"Deployment/app: 1/2 replicas available",
+		"Job/migrate: failed: backoff limit exceeded",
+		`PersistentVolumeClaim/data: phase is "Pending"`,
+		"Service/app: no ready endpoints",
+	}, unready)
+}
+
+func TestEvaluateNotFound(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(scheme.Scheme)
+	e := NewEvaluator(c, c, mapper)
+	unready, err := e.Evaluate(context.TODO(), manifest, "ns")
+	assert.NoError(t, err)
+	assert.Len(t, unready, 4)
+	assert.Contains(t, unready, "Job/migrate: not found")
+}
+
+func TestRegister(t *testing.T) {
+	objs := append(newObjects(false), &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "config"},
+	})
+	c := fake.NewFakeClientWithScheme(scheme.Scheme, objs...)
+	e := NewEvaluator(c, c, mapper)
+	for _, gk := range []schema.GroupKind{
+		{Group: "apps", Kind: "Deployment"},
+		{Group: "batch", Kind: "Job"},
+		{Kind: "PersistentVolumeClaim"},
+	} {
+		e.Register(gk, nil)
+	}
+	e.Register(schema.GroupKind{Kind: "Service"},
+		func(context.Context, client.Reader, *unstructured.Unstructured) (bool, string, error) {
+			return true, "", nil
+		})
+	e.Register(schema.GroupKind{Kind: "ConfigMap"},
+		func(context.Context, client.Reader, *unstructured.Unstructured) (bool, string, error) {
+			return false, "custom check", nil
+		})
+
+	unready, err := e.Evaluate(context.TODO(), manifest, "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ConfigMap/config: custom check"}, unready)
+}
+
+func TestEvaluateReaders(t *testing.T) {
+	// The cache only holds objects of the release's namespace.
+	cached := fake.NewFakeClientWithScheme(scheme.Scheme, newObjects(true)...)
+	other := newObjects(false)[0].(*appsv1.Deployment)
+	other.Namespace = "other"
+	uncached := fake.NewFakeClientWithScheme(scheme.Scheme, other)
+	e := NewEvaluator(cached, uncached, mapper)
+
+	unready, err := e.Evaluate(context.TODO(), manifest+`---
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+  namespace: other
+`, "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Deployment/app: 1/2 replicas available"}, unready)
+}
+
+func TestEvaluateClusterScoped(t *testing.T) {
+	c := fake.NewFakeClientWithScheme(scheme.Scheme)
+	uncached := fake.NewFakeClientWithScheme(scheme.Scheme, &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{Name: "volume"},
+		Status:     corev1.PersistentVolumeStatus{Phase: corev1.VolumePending},
+	})
+	e := NewEvaluator(c, uncached, mapper)
+	e.Register(schema.GroupKind{Kind: "PersistentVolume"},
+		func(_ context.Context, _ client.Reader, obj *unstructured.Unstructured) (bool, string, error) {
+			phase, _, err := unstructured.NestedString(obj.Object, "status", "phase")
+			return phase == string(corev1.VolumeBound), fmt.Sprintf("phase is %q", phase), err
+		})
+
+	unready, err := e.Evaluate(context.TODO(), `---
+apiVersion: v1
+kind: PersistentVolume
+metadata:
+  name: volume
+`, "ns")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{`PersistentVolume/volume: phase is "Pending"`}, unready)
+}
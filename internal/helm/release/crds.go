@@ -0,0 +1,388 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	cpb "helm.sh/helm/v3/pkg/chart"
+	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextv1beta1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+)
+
+var crdGVK = apiextv1.SchemeGroupVersion.WithKind("CustomResourceDefinition")
+
+// crdApplier creates and updates the CRDs bundled in a chart's crds/
+// directory, which Helm itself only installs once. CRDs and their objects are
+// read with reader, which must not be limited to the watched namespaces so
+// that objects in every namespace are checked before a CRD is upgraded.
+type crdApplier struct {
+	client crclient.Client
+	reader crclient.Reader
+}
+
+// crdChange is the change planned for a single chart CRD. existing is nil if
+// the CRD does not exist in the cluster yet.
+type crdChange struct {
+	desired  *apiextv1.CustomResourceDefinition
+	existing *apiextv1.CustomResourceDefinition
+	result   types.HelmAppCRD
+}
+
+// apply plans a change for every CRD in chrt and applies the changes only if
+// none of them is refused, so that a chart's CRDs are never partially
+// upgraded. It returns the outcome for each CRD, including refused ones.
+func (a crdApplier) apply(ctx context.Context, chrt *cpb.Chart) ([]types.HelmAppCRD, error) {
+	desired, err := decodeChartCRDs(chrt)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]crdChange, 0, len(desired))
+	var refused []string
+	for _, crd := range desired {
+		change, err := a.plan(ctx, crd)
+		if err != nil {
+			return nil, err
+		}
+		if change.result.Action == types.CRDRefused {
+			refused = append(refused, fmt.Sprintf("%s: %s", change.result.Name, change.result.Message))
+		}
+		changes = append(changes, change)
+	}
+
+	results := make([]types.HelmAppCRD, 0, len(changes))
+	if len(refused) > 0 {
+		for _, change := range changes {
+			if change.result.Action != types.CRDRefused && change.result.Action != types.CRDUnchanged {
+				change.result.Action = types.CRDUnchanged
+				change.result.Message = "Not applied because another CRD upgrade was refused"
+			}
+			results = append(results, change.result)
+		}
+		return results, fmt.Errorf("refused to upgrade CRDs: %s", strings.Join(refused, "; "))
+	}
+
+	for _, change := range changes {
+		if err := a.applyChange(ctx, change); err != nil {
+			return results, err
+		}
+		results = append(results, change.result)
+	}
+	return results, nil
+}
+
+func (a crdApplier) plan(ctx context.Context, desired *apiextv1.CustomResourceDefinition) (crdChange, error) {
+	change := crdChange{
+		desired: desired,
+		result:  types.HelmAppCRD{Name: desired.GetName()},
+	}
+
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(crdGVK)
+	err := a.reader.Get(ctx, crclient.ObjectKey{Name: desired.GetName()}, u)
+	if apierrors.IsNotFound(err) {
+		change.result.Action = types.CRDCreated
+		return change, nil
+	}
+	if err != nil {
+		return change, fmt.Errorf("failed to get CRD %q: %w", desired.GetName(), err)
+	}
+	existing := &apiextv1.CustomResourceDefinition{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, existing); err != nil {
+		return change, fmt.Errorf("failed to convert CRD %q: %w", desired.GetName(), err)
+	}
+	change.existing = existing
+
+	if equality.Semantic.DeepEqual(existing.Spec, desired.Spec) {
+		change.result.Action = types.CRDUnchanged
+		return change, nil
+	}
+
+	reason, err := a.checkUpgrade(ctx, existing, desired)
+	if err != nil {
+		return change, err
+	}
+	if reason != "" {
+		change.result.Action = types.CRDRefused
+		change.result.Message = reason
+		return change, nil
+	}
+	change.result.Action = types.CRDUpdated
+	return change, nil
+}
+
+// checkUpgrade returns the reason why replacing existing with desired could
+// lose data, or an empty string if the upgrade is safe. An upgrade is unsafe
+// if it drops a version that objects are stored in, or if it removes schema
+// fields that are set in existing objects, since the API server would prune
+// those fields.
+func (a crdApplier) checkUpgrade(ctx context.Context, existing, desired *apiextv1.CustomResourceDefinition) (string, error) {
+	desiredVersions := make(map[string]apiextv1.CustomResourceDefinitionVersion, len(desired.Spec.Versions))
+	for _, v := range desired.Spec.Versions {
+		desiredVersions[v.Name] = v
+	}
+	for _, stored := range existing.Status.StoredVersions {
+		if _, ok := desiredVersions[stored]; !ok {
+			return fmt.Sprintf("stored version %q would be removed", stored), nil
+		}
+	}
+
+	for _, ev := range existing.Spec.Versions {
+		dv, ok := desiredVersions[ev.Name]
+		if !ok || !ev.Served || ev.Schema == nil || dv.Schema == nil {
+			continue
+		}
+		removed := removedFields(ev.Schema.OpenAPIV3Schema, dv.Schema.OpenAPIV3Schema, nil)
+		if len(removed) == 0 {
+			continue
+		}
+		reason, err := a.fieldsInUse(ctx, existing, ev.Name, removed)
+		if err != nil || reason != "" {
+			return reason, err
+		}
+	}
+	return "", nil
+}
+
+// fieldsInUse lists the objects of crd in the given version and returns a
+// reason if any of them sets one of fields.
+func (a crdApplier) fieldsInUse(ctx context.Context, crd *apiextv1.CustomResourceDefinition, version string,
+	fields [][]string) (string, error) {
+	list := &unstructured.UnstructuredList{}
+	list.SetGroupVersionKind(schema.GroupVersionKind{
+		Group:   crd.Spec.Group,
+		Version: version,
+		Kind:    crd.Spec.Names.Kind + "List",
+	})
+	if err := a.reader.List(ctx, list); err != nil {
+		return "", fmt.Errorf("failed to list %s objects: %w", crd.GetName(), err)
+	}
+	for _, item := range list.Items {
+		for _, field := range fields {
+			if fieldIsSet(item.Object, field) {
+				ref := item.GetName()
+				if item.GetNamespace() != "" {
+					ref = item.GetNamespace() + "/" + ref
+				}
+				return fmt.Sprintf("field %q of version %q would be removed but is set in %s %q",
+					fieldPathString(field), version, crd.Spec.Names.Kind, ref), nil
+			}
+		}
+	}
+	return "", nil
+}
+
+func (a crdApplier) applyChange(ctx context.Context, change crdChange) error {
+	var obj *apiextv1.CustomResourceDefinition
+	switch change.result.Action {
+	case types.CRDCreated:
+		obj = change.desired.DeepCopy()
+	case types.CRDUpdated:
+		obj = change.existing.DeepCopy()
+		obj.Spec = change.desired.Spec
+		obj.SetLabels(mergeStringMaps(obj.GetLabels(), change.desired.GetLabels()))
+		obj.SetAnnotations(mergeStringMaps(obj.GetAnnotations(), change.desired.GetAnnotations()))
+	default:
+		return nil
+	}
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+	if err != nil {
+		return fmt.Errorf("failed to convert CRD %q: %w", obj.GetName(), err)
+	}
+	u := &unstructured.Unstructured{Object: content}
+	u.SetGroupVersionKind(crdGVK)
+
+	if change.result.Action == types.CRDCreated {
+		if err := a.client.Create(ctx, u); err != nil {
+			return fmt.Errorf("failed to create CRD %q: %w", obj.GetName(), err)
+		}
+		return nil
+	}
+	if err := a.client.Update(ctx, u); err != nil {
+		return fmt.Errorf("failed to update CRD %q: %w", obj.GetName(), err)
+	}
+	return nil
+}
+
+// decodeChartCRDs returns the CRDs in the crds/ directories of chrt and its
+// dependencies, converted to apiextensions.k8s.io/v1 and defaulted the same
+// way the API server defaults them.
+func decodeChartCRDs(chrt *cpb.Chart) ([]*apiextv1.CustomResourceDefinition, error) {
+	var crds []*apiextv1.CustomResourceDefinition
+	for _, obj := range chrt.CRDObjects() {
+		dec := utilyaml.NewYAMLOrJSONDecoder(bytes.NewReader(obj.File.Data), 4096)
+		for {
+			u := &unstructured.Unstructured{}
+			if err := dec.Decode(&u.Object); err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return nil, fmt.Errorf("failed to decode %s: %w", obj.Filename, err)
+			}
+			if len(u.Object) == 0 {
+				continue
+			}
+			crd, err := toV1CRD(u)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CRD from %s: %w", obj.Filename, err)
+			}
+			crds = append(crds, crd)
+		}
+	}
+	return crds, nil
+}
+
+func toV1CRD(u *unstructured.Unstructured) (*apiextv1.CustomResourceDefinition, error) {
+	gvk := u.GroupVersionKind()
+	if gvk.GroupKind() != crdGVK.GroupKind() {
+		return nil, fmt.Errorf("unexpected %s %q", gvk.Kind, u.GetName())
+	}
+
+	crd := &apiextv1.CustomResourceDefinition{}
+	switch gvk.Version {
+	case apiextv1.SchemeGroupVersion.Version:
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, crd); err != nil {
+			return nil, err
+		}
+	case apiextv1beta1.SchemeGroupVersion.Version:
+		in := &apiextv1beta1.CustomResourceDefinition{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, in); err != nil {
+			return nil, err
+		}
+		apiextv1beta1.SetObjectDefaults_CustomResourceDefinition(in)
+		internal := &apiextensions.CustomResourceDefinition{}
+		if err := apiextv1beta1.Convert_v1beta1_CustomResourceDefinition_To_apiextensions_CustomResourceDefinition(in, internal, nil); err != nil {
+			return nil, err
+		}
+		if err := apiextv1.Convert_apiextensions_CustomResourceDefinition_To_v1_CustomResourceDefinition(internal, crd, nil); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported CRD version %q", gvk.Version)
+	}
+	apiextv1.SetObjectDefaults_CustomResourceDefinition(crd)
+	crd.SetGroupVersionKind(crdGVK)
+	return crd, nil
+}
+
+// removedFields returns the paths of the properties in oldSchema that are no
+// longer allowed by newSchema. Array items are represented by a "[]" path
+// element.
+func removedFields(oldSchema, newSchema *apiextv1.JSONSchemaProps, path []string) [][]string {
+	if oldSchema == nil || newSchema == nil {
+		return nil
+	}
+	if newSchema.XPreserveUnknownFields != nil && *newSchema.XPreserveUnknownFields {
+		return nil
+	}
+
+	var removed [][]string
+	allowsAdditional := newSchema.AdditionalProperties != nil &&
+		(newSchema.AdditionalProperties.Allows || newSchema.AdditionalProperties.Schema != nil)
+	names := make([]string, 0, len(oldSchema.Properties))
+	for name := range oldSchema.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldProp := oldSchema.Properties[name]
+		fieldPath := appendPath(path, name)
+		newProp, ok := newSchema.Properties[name]
+		if !ok {
+			if !allowsAdditional {
+				removed = append(removed, fieldPath)
+			}
+			continue
+		}
+		removed = append(removed, removedFields(&oldProp, &newProp, fieldPath)...)
+	}
+
+	if oldSchema.Items != nil && newSchema.Items != nil {
+		removed = append(removed, removedFields(oldSchema.Items.Schema, newSchema.Items.Schema, appendPath(path, "[]"))...)
+	}
+	return removed
+}
+
+func appendPath(path []string, elem string) []string {
+	out := make([]string, len(path), len(path)+1)
+	copy(out, path)
+	return append(out, elem)
+}
+
+// fieldIsSet returns true if obj has a value at path, in any element of the
+// arrays along the path.
+func fieldIsSet(obj interface{}, path []string) bool {
+	if len(path) == 0 {
+		return true
+	}
+	switch o := obj.(type) {
+	case map[string]interface{}:
+		v, ok := o[path[0]]
+		return ok && path[0] != "[]" && fieldIsSet(v, path[1:])
+	case []interface{}:
+		if path[0] != "[]" {
+			return false
+		}
+		for _, item := range o {
+			if fieldIsSet(item, path[1:]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func fieldPathString(path []string) string {
+	var sb strings.Builder
+	for _, elem := range path {
+		if elem != "[]" && sb.Len() > 0 {
+			sb.WriteString(".")
+		}
+		sb.WriteString(elem)
+	}
+	return sb.String()
+}
+
+func mergeStringMaps(base, overlay map[string]string) map[string]string {
+	if len(overlay) == 0 {
+		return base
+	}
+	out := make(map[string]string, len(base)+len(overlay))
+	for k, v := range base {
+		out[k] = v
+	}
+	for k, v := range overlay {
+		out[k] = v
+	}
+	return out
+}
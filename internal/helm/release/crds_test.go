@@ -0,0 +1,297 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	cpb "helm.sh/helm/v3/pkg/chart"
+	apiextv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
+)
+
+const fooCRDv1beta1 = `apiVersion: apiextensions.k8s.io/v1beta1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com
+spec:
+  group: example.com
+  names:
+    kind: Foo
+    plural: foos
+  scope: Namespaced
+  version: v1
+  validation:
+    openAPIV3Schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          properties:
+            size:
+              type: integer
+`
+
+const fooCRDv1 = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: foos.example.com
+spec:
+  group: example.com
+  names:
+    kind: Foo
+    plural: foos
+  scope: Namespaced
+  versions:
+  - name: v2
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+  - name: v1
+    served: true
+    storage: false
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                type: integer
+              color:
+                type: string
+`
+
+var fooGVK = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Foo"}
+
+func chartWithCRDs(crds ...string) *cpb.Chart {
+	chrt := &cpb.Chart{Metadata: &cpb.Metadata{Name: "test"}}
+	for i, crd := range crds {
+		chrt.Files = append(chrt.Files, &cpb.File{
+			Name: "crds/crd" + string(rune('a'+i)) + ".yaml",
+			Data: []byte(crd),
+		})
+	}
+	return chrt
+}
+
+func newCRDTestClient(t *testing.T, objs ...runtime.Object) crclient.Client {
+	s := runtime.NewScheme()
+	require.NoError(t, apiextv1.AddToScheme(s))
+	s.AddKnownTypeWithName(fooGVK, &unstructured.Unstructured{})
+	s.AddKnownTypeWithName(fooGVK.GroupVersion().WithKind("FooList"), &unstructured.UnstructuredList{})
+	return fake.NewFakeClientWithScheme(s, objs...)
+}
+
+func getCRD(t *testing.T, c crclient.Client, name string) *apiextv1.CustomResourceDefinition {
+	crd := &apiextv1.CustomResourceDefinition{}
+	require.NoError(t, c.Get(context.TODO(), crclient.ObjectKey{Name: name}, crd))
+	return crd
+}
+
+func newFoo(spec map[string]interface{}) *unstructured.Unstructured {
+	foo := &unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+	foo.SetGroupVersionKind(fooGVK)
+	foo.SetNamespace("default")
+	foo.SetName("foo")
+	return foo
+}
+
+// setStoredVersions records versions as stored, which the API server does
+// when objects are written.
+func setStoredVersions(t *testing.T, c crclient.Client, name string, versions ...string) {
+	crd := getCRD(t, c, name)
+	crd.Status.StoredVersions = versions
+	require.NoError(t, c.Status().Update(context.TODO(), crd))
+}
+
+func TestApplyCRDsCreatesAndSkipsUnchanged(t *testing.T) {
+	c := newCRDTestClient(t)
+	a := crdApplier{client: c, reader: c}
+	chrt := chartWithCRDs(fooCRDv1beta1)
+
+	results, err := a.apply(context.TODO(), chrt)
+	require.NoError(t, err)
+	assert.Equal(t, []types.HelmAppCRD{{Name: "foos.example.com", Action: types.CRDCreated}}, results)
+
+	crd := getCRD(t, c, "foos.example.com")
+	require.Len(t, crd.Spec.Versions, 1)
+	assert.Equal(t, "v1", crd.Spec.Versions[0].Name)
+	require.NotNil(t, crd.Spec.Versions[0].Schema)
+	assert.Contains(t, crd.Spec.Versions[0].Schema.OpenAPIV3Schema.Properties["spec"].Properties, "size")
+
+	results, err = a.apply(context.TODO(), chrt)
+	require.NoError(t, err)
+	assert.Equal(t, []types.HelmAppCRD{{Name: "foos.example.com", Action: types.CRDUnchanged}}, results)
+}
+
+func TestApplyCRDsUpdates(t *testing.T) {
+	c := newCRDTestClient(t)
+	a := crdApplier{client: c, reader: c}
+	_, err := a.apply(context.TODO(), chartWithCRDs(fooCRDv1beta1))
+	require.NoError(t, err)
+	setStoredVersions(t, c, "foos.example.com", "v1")
+	require.NoError(t, c.Create(context.TODO(), newFoo(map[string]interface{}{"size": int64(1)})))
+
+	results, err := a.apply(context.TODO(), chartWithCRDs(fooCRDv1))
+	require.NoError(t, err)
+	assert.Equal(t, []types.HelmAppCRD{{Name: "foos.example.com", Action: types.CRDUpdated}}, results)
+
+	crd := getCRD(t, c, "foos.example.com")
+	require.Len(t, crd.Spec.Versions, 2)
+	assert.Equal(t, "v2", crd.Spec.Versions[0].Name)
+	assert.Equal(t, []string{"v1"}, crd.Status.StoredVersions)
+}
+
+func TestApplyCRDsRefusesDroppingStoredVersion(t *testing.T) {
+	c := newCRDTestClient(t)
+	a := crdApplier{client: c, reader: c}
+	_, err := a.apply(context.TODO(), chartWithCRDs(fooCRDv1))
+	require.NoError(t, err)
+	setStoredVersions(t, c, "foos.example.com", "v1", "v2")
+
+	results, err := a.apply(context.TODO(), chartWithCRDs(fooCRDv1beta1))
+	assert.Error(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, types.CRDRefused, results[0].Action)
+	assert.Equal(t, `stored version "v2" would be removed`, results[0].Message)
+	assert.Len(t, getCRD(t, c, "foos.example.com").Spec.Versions, 2)
+}
+
+func TestApplyCRDsRefusesRemovingFieldInUse(t *testing.T) {
+	c := newCRDTestClient(t)
+	a := crdApplier{client: c, reader: c}
+	_, err := a.apply(context.TODO(), chartWithCRDs(fooCRDv1))
+	require.NoError(t, err)
+	setStoredVersions(t, c, "foos.example.com", "v1")
+
+	// The v1beta1 chart CRD drops v2, which is not stored, and spec.color from
+	// v1, which is allowed while no object sets it.
+	foo := newFoo(map[string]interface{}{"size": int64(1)})
+	require.NoError(t, c.Create(context.TODO(), foo))
+	chrt := chartWithCRDs(fooCRDv1beta1)
+	crd := getCRD(t, c, "foos.example.com")
+	reason, err := a.checkUpgrade(context.TODO(), crd, mustDecodeCRD(t, chrt))
+	require.NoError(t, err)
+	assert.Empty(t, reason)
+
+	foo.Object["spec"] = map[string]interface{}{"size": int64(1), "color": "red"}
+	require.NoError(t, c.Update(context.TODO(), foo))
+	results, err := a.apply(context.TODO(), chrt)
+	assert.Error(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, types.CRDRefused, results[0].Action)
+	assert.Equal(t, `field "spec.color" of version "v1" would be removed but is set in Foo "default/foo"`,
+		results[0].Message)
+}
+
+func TestApplyCRDsChecksObjectsOutsideCache(t *testing.T) {
+	// The cached client only sees the watched namespace, so the field in use
+	// by an object in another namespace must be found through the reader.
+	cached := newCRDTestClient(t)
+	_, err := crdApplier{client: cached, reader: cached}.apply(context.TODO(), chartWithCRDs(fooCRDv1))
+	require.NoError(t, err)
+	setStoredVersions(t, cached, "foos.example.com", "v1")
+	crd := getCRD(t, cached, "foos.example.com")
+	crd.ResourceVersion = ""
+
+	foo := newFoo(map[string]interface{}{"color": "red"})
+	foo.SetNamespace("other")
+	live := newCRDTestClient(t, crd, foo)
+
+	a := crdApplier{client: cached, reader: live}
+	results, err := a.apply(context.TODO(), chartWithCRDs(fooCRDv1beta1))
+	assert.Error(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, types.CRDRefused, results[0].Action)
+	assert.Equal(t, `field "spec.color" of version "v1" would be removed but is set in Foo "other/foo"`,
+		results[0].Message)
+}
+
+func mustDecodeCRD(t *testing.T, chrt *cpb.Chart) *apiextv1.CustomResourceDefinition {
+	crds, err := decodeChartCRDs(chrt)
+	require.NoError(t, err)
+	require.Len(t, crds, 1)
+	return crds[0]
+}
+
+func TestDecodeChartCRDsRejectsOtherKinds(t *testing.T) {
+	_, err := decodeChartCRDs(chartWithCRDs("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: foo\n"))
+	assert.Error(t, err)
+
+	crds, err := decodeChartCRDs(chartWithCRDs("---\n" + fooCRDv1 + "---\n"))
+	require.NoError(t, err)
+	assert.Len(t, crds, 1)
+}
+
+func TestRemovedFields(t *testing.T) {
+	object := func(props map[string]apiextv1.JSONSchemaProps) *apiextv1.JSONSchemaProps {
+		return &apiextv1.JSONSchemaProps{Type: "object", Properties: props}
+	}
+	oldSchema := object(map[string]apiextv1.JSONSchemaProps{
+		"spec": *object(map[string]apiextv1.JSONSchemaProps{
+			"a": {Type: "string"},
+			"ports": {Type: "array", Items: &apiextv1.JSONSchemaPropsOrArray{
+				Schema: object(map[string]apiextv1.JSONSchemaProps{"name": {Type: "string"}}),
+			}},
+		}),
+	})
+	newSchema := object(map[string]apiextv1.JSONSchemaProps{
+		"spec": *object(map[string]apiextv1.JSONSchemaProps{
+			"ports": {Type: "array", Items: &apiextv1.JSONSchemaPropsOrArray{
+				Schema: object(nil),
+			}},
+		}),
+	})
+	assert.Equal(t, [][]string{{"spec", "a"}, {"spec", "ports", "[]", "name"}}, removedFields(oldSchema, newSchema, nil))
+
+	preserve := true
+	newSchema.Properties["spec"] = apiextv1.JSONSchemaProps{Type: "object", XPreserveUnknownFields: &preserve}
+	assert.Empty(t, removedFields(oldSchema, newSchema, nil))
+}
+
+func TestFieldIsSet(t *testing.T) {
+	obj := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"ports": []interface{}{
+				map[string]interface{}{"port": int64(80)},
+				map[string]interface{}{"name": "https"},
+			},
+		},
+	}
+	assert.True(t, fieldIsSet(obj, []string{"spec", "ports"}))
+	assert.True(t, fieldIsSet(obj, []string{"spec", "ports", "[]", "name"}))
+	assert.False(t, fieldIsSet(obj, []string{"spec", "ports", "name"}))
+	assert.False(t, fieldIsSet(obj, []string{"spec", "size"}))
+	assert.Equal(t, "spec.ports[].name", fieldPathString([]string{"spec", "ports", "[]", "name"}))
+}
@@ -0,0 +1,42 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// ChartsLoadable returns a check that fails if any of the charts in chartDirs
+// cannot be loaded, e.g. because the chart directory has been removed or a
+// chart file is malformed.
+func ChartsLoadable(chartDirs ...string) healthz.Checker {
+	return func(*http.Request) error {
+		var failed []string
+		for _, dir := range chartDirs {
+			if _, err := loader.Load(dir); err != nil {
+				failed = append(failed, fmt.Sprintf("%s: %v", dir, err))
+			}
+		}
+		if len(failed) > 0 {
+			return fmt.Errorf("failed to load charts: %s", strings.Join(failed, "; "))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,49 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChartsLoadable(t *testing.T) {
+	dir, err := ioutil.TempDir("", "charts")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	chartDir := filepath.Join(dir, "nginx")
+	require.NoError(t, os.MkdirAll(filepath.Join(chartDir, "templates"), 0755))
+	chartFile := filepath.Join(chartDir, "Chart.yaml")
+	require.NoError(t, ioutil.WriteFile(chartFile, []byte("apiVersion: v2\nname: nginx\nversion: 0.1.0\n"), 0644))
+
+	check := ChartsLoadable(chartDir)
+	assert.NoError(t, check(nil))
+
+	require.NoError(t, ioutil.WriteFile(chartFile, []byte("name: ["), 0644))
+	err = check(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to load charts: "+chartDir+": ")
+
+	missing := filepath.Join(dir, "missing")
+	err = ChartsLoadable(missing)(nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), missing)
+}
@@ -27,6 +27,7 @@ import (
 	cpb "helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/kube"
 	helmkube "helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/postrender"
 	rpb "helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
@@ -39,6 +40,8 @@ import (
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
 	"k8s.io/cli-runtime/pkg/resource"
 
+	crclient "sigs.k8s.io/controller-runtime/pkg/client"
+
 	"github.com/operator-framework/operator-sdk/internal/helm/internal/types"
 )
 
@@ -48,11 +51,13 @@ type Manager interface {
 	ReleaseName() string
 	IsInstalled() bool
 	IsUpgradeRequired() bool
-	Sync(context.Context) error
+	Sync(context.Context, ...UpgradeOption) error
 	InstallRelease(context.Context, ...InstallOption) (*rpb.Release, error)
 	UpgradeRelease(context.Context, ...UpgradeOption) (*rpb.Release, *rpb.Release, error)
 	ReconcileRelease(context.Context) (*rpb.Release, error)
 	UninstallRelease(context.Context, ...UninstallOption) (*rpb.Release, error)
+	PreviewRelease(context.Context, ...InstallOption) (*rpb.Release, *rpb.Release, error)
+	ApplyCRDs(context.Context) ([]types.HelmAppCRD, error)
 }
 
 type manager struct {
@@ -62,6 +67,11 @@ type manager struct {
 
 	releaseName string
 	namespace   string
+	maxHistory  int
+
+	postRenderer postrender.PostRenderer
+	crdClient    crclient.Client
+	crdReader    crclient.Reader
 
 	values map[string]interface{}
 	status *types.HelmAppStatus
@@ -69,6 +79,7 @@ type manager struct {
 	isInstalled       bool
 	isUpgradeRequired bool
 	deployedRelease   *rpb.Release
+	candidateRelease  *rpb.Release
 	chart             *cpb.Chart
 }
 
@@ -90,8 +101,10 @@ func (m manager) IsUpgradeRequired() bool {
 }
 
 // Sync ensures the Helm storage backend is in sync with the status of the
-// custom resource.
-func (m *manager) Sync(ctx context.Context) error {
+// custom resource. The upgrade options are applied to the dry-run upgrade that
+// determines whether an upgrade is required, so they should match the options
+// a subsequent UpgradeRelease is called with.
+func (m *manager) Sync(ctx context.Context, opts ...UpgradeOption) error {
 	// Get release history for this release name
 	releases, err := m.storageBackend.History(m.releaseName)
 	if err != nil && !notFoundErr(err) {
@@ -122,10 +135,11 @@ func (m *manager) Sync(ctx context.Context) error {
 	m.isInstalled = true
 
 	// Get the next candidate release to determine if an upgrade is necessary.
-	candidateRelease, err := m.getCandidateRelease(m.namespace, m.releaseName, m.chart, m.values)
+	candidateRelease, err := m.getCandidateRelease(m.namespace, m.releaseName, m.chart, m.values, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to get candidate release: %w", err)
 	}
+	m.candidateRelease = candidateRelease
 	if deployedRelease.Manifest != candidateRelease.Manifest {
 		m.isUpgradeRequired = true
 	}
@@ -149,23 +163,75 @@ func (m manager) getDeployedRelease() (*rpb.Release, error) {
 }
 
 func (m manager) getCandidateRelease(namespace, name string, chart *cpb.Chart,
-	values map[string]interface{}) (*rpb.Release, error) {
+	values map[string]interface{}, opts ...UpgradeOption) (*rpb.Release, error) {
 	upgrade := action.NewUpgrade(m.actionConfig)
 	upgrade.Namespace = namespace
+	upgrade.MaxHistory = m.maxHistory
+	upgrade.PostRenderer = m.postRenderer
+	for _, o := range opts {
+		if err := o(upgrade); err != nil {
+			return nil, fmt.Errorf("failed to apply upgrade option: %w", err)
+		}
+	}
 	upgrade.DryRun = true
 	return upgrade.Run(name, chart, values)
 }
 
-// InstallRelease performs a Helm release install.
-func (m manager) InstallRelease(ctx context.Context, opts ...InstallOption) (*rpb.Release, error) {
+// PreviewRelease renders the release that the next install or upgrade would
+// create, without applying it. It returns the deployed release, which is nil
+// if the release is not installed, and the candidate release. Sync must be
+// called before PreviewRelease, with the upgrade options that match opts.
+func (m manager) PreviewRelease(ctx context.Context, opts ...InstallOption) (*rpb.Release, *rpb.Release, error) {
+	if m.isInstalled {
+		return m.deployedRelease, m.candidateRelease, nil
+	}
+
+	install, err := m.newInstall(opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	install.DryRun = true
+	candidateRelease, err := install.Run(m.chart, m.values)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render release: %w", err)
+	}
+	return nil, candidateRelease, nil
+}
+
+// ApplyCRDs creates or updates the CRDs in the chart's crds/ directory if CRD
+// management is enabled. No CRD is changed if upgrading any of them is
+// refused. The returned results are nil if CRD management is disabled.
+func (m manager) ApplyCRDs(ctx context.Context) ([]types.HelmAppCRD, error) {
+	if m.crdClient == nil {
+		return nil, nil
+	}
+	return crdApplier{client: m.crdClient, reader: m.crdReader}.apply(ctx, m.chart)
+}
+
+// newInstall returns the install action of the release with opts applied.
+func (m manager) newInstall(opts ...InstallOption) (*action.Install, error) {
 	install := action.NewInstall(m.actionConfig)
 	install.ReleaseName = m.releaseName
 	install.Namespace = m.namespace
+	install.PostRenderer = m.postRenderer
 	for _, o := range opts {
 		if err := o(install); err != nil {
 			return nil, fmt.Errorf("failed to apply install option: %w", err)
 		}
 	}
+	// CRDs are applied by ApplyCRDs when CRD management is enabled.
+	if m.crdClient != nil {
+		install.SkipCRDs = true
+	}
+	return install, nil
+}
+
+// InstallRelease performs a Helm release install.
+func (m manager) InstallRelease(ctx context.Context, opts ...InstallOption) (*rpb.Release, error) {
+	install, err := m.newInstall(opts...)
+	if err != nil {
+		return nil, err
+	}
 
 	installedRelease, err := install.Run(m.chart, m.values)
 	if err != nil {
@@ -202,6 +268,8 @@ func ForceUpgrade(force bool) UpgradeOption {
 func (m manager) UpgradeRelease(ctx context.Context, opts ...UpgradeOption) (*rpb.Release, *rpb.Release, error) {
 	upgrade := action.NewUpgrade(m.actionConfig)
 	upgrade.Namespace = m.namespace
+	upgrade.MaxHistory = m.maxHistory
+	upgrade.PostRenderer = m.postRenderer
 	for _, o := range opts {
 		if err := o(upgrade); err != nil {
 			return nil, nil, fmt.Errorf("failed to apply upgrade option: %w", err)
@@ -214,6 +282,7 @@ func (m manager) UpgradeRelease(ctx context.Context, opts ...UpgradeOption) (*rp
 		if upgradedRelease != nil {
 			rollback := action.NewRollback(m.actionConfig)
 			rollback.Force = true
+			rollback.MaxHistory = m.maxHistory
 
 			// As of Helm 2.13, if UpgradeRelease returns a non-nil release, that
 			// means the release was also recorded in the release store.
@@ -15,16 +15,24 @@
 package release
 
 import (
+	"bytes"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"sync"
+	"text/template"
 
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/postrender"
 	helmrelease "helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/storage"
-	"helm.sh/helm/v3/pkg/storage/driver"
 	"helm.sh/helm/v3/pkg/strvals"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	apitypes "k8s.io/apimachinery/pkg/types"
 	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	crmanager "sigs.k8s.io/controller-runtime/pkg/manager"
 
@@ -38,16 +46,80 @@ import (
 // components used to manage releases.
 type ManagerFactory interface {
 	NewManager(r *unstructured.Unstructured, overrideValues map[string]string) (Manager, error)
+	// ReleaseName returns the name of the release that the Manager of r
+	// manages, without creating the Manager.
+	ReleaseName(r *unstructured.Unstructured) (string, error)
 }
 
 type managerFactory struct {
-	mgr      crmanager.Manager
-	chartDir string
+	mgr                 crmanager.Manager
+	chartDir            string
+	releaseNameTemplate string
+	maxHistory          int
+	postRenderer        postrender.PostRenderer
+	manageCRDs          bool
+	storage             StorageConfig
+
+	// migrated holds the types.NamespacedName of each release whose history
+	// was migrated from storage.MigrateFrom.
+	migrated *sync.Map
+}
+
+// ManagerFactoryOption configures optional behavior of a ManagerFactory.
+type ManagerFactoryOption func(*managerFactory)
+
+// WithReleaseNameTemplate configures the factory to name new releases using
+// the given template. See ParseReleaseNameTemplate for the available
+// variables.
+func WithReleaseNameTemplate(tmpl string) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.releaseNameTemplate = tmpl
+	}
+}
+
+// WithMaxHistory configures the factory to limit the number of revisions
+// stored for each release. A value of 0 means no limit.
+func WithMaxHistory(maxHistory int) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.maxHistory = maxHistory
+	}
+}
+
+// WithPostRenderer configures the factory to run the given post-renderer on
+// the manifests of each install and upgrade, including the dry-run upgrades
+// used to decide whether an upgrade is required.
+func WithPostRenderer(pr postrender.PostRenderer) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.postRenderer = pr
+	}
+}
+
+// WithManagedCRDs configures the factory to create Managers that apply the
+// CRDs in the chart's crds/ directory when they reconcile. Helm only
+// creates those CRDs when a release is first installed.
+func WithManagedCRDs(manageCRDs bool) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.manageCRDs = manageCRDs
+	}
+}
+
+// WithStorage configures where the factory's Managers store release records.
+// If the configuration has a MigrateFrom storage, release records found there
+// are moved to the configured storage when the first Manager of a release is
+// created.
+func WithStorage(c StorageConfig) ManagerFactoryOption {
+	return func(f *managerFactory) {
+		f.storage = c
+	}
 }
 
 // NewManagerFactory returns a new Helm manager factory capable of installing and uninstalling releases.
-func NewManagerFactory(mgr crmanager.Manager, chartDir string) ManagerFactory {
-	return &managerFactory{mgr, chartDir}
+func NewManagerFactory(mgr crmanager.Manager, chartDir string, opts ...ManagerFactoryOption) ManagerFactory {
+	f := &managerFactory{mgr: mgr, chartDir: chartDir, migrated: &sync.Map{}}
+	for _, o := range opts {
+		o(f)
+	}
+	return f
 }
 
 func (f managerFactory) NewManager(cr *unstructured.Unstructured, overrideValues map[string]string) (Manager, error) {
@@ -56,7 +128,13 @@ func (f managerFactory) NewManager(cr *unstructured.Unstructured, overrideValues
 	if err != nil {
 		return nil, fmt.Errorf("failed to get core/v1 client: %w", err)
 	}
-	storageBackend := storage.Init(driver.NewSecrets(clientv1.Secrets(cr.GetNamespace())))
+	storageBackend := storage.Init(newStorageDriver(clientv1, f.storage, cr.GetNamespace()))
+	storageBackend.MaxHistory = f.maxHistory
+	if f.storage.MigrateFrom != nil {
+		if err := f.migrateStorage(clientv1, storageBackend, cr); err != nil {
+			return nil, fmt.Errorf("failed to migrate release storage: %w", err)
+		}
+	}
 
 	// Get the necessary clients and client getters. Use a client that injects the CR
 	// as an owner reference into all resources templated by the chart.
@@ -77,7 +155,7 @@ func (f managerFactory) NewManager(cr *unstructured.Unstructured, overrideValues
 		return nil, fmt.Errorf("failed to load chart dir: %w", err)
 	}
 
-	releaseName, err := getReleaseName(storageBackend, crChart.Name(), cr)
+	releaseName, err := getReleaseName(storageBackend, crChart.Name(), cr, f.releaseNameTemplate)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get helm release name: %w", err)
 	}
@@ -100,60 +178,226 @@ func (f managerFactory) NewManager(cr *unstructured.Unstructured, overrideValues
 		Log:              func(_ string, _ ...interface{}) {},
 	}
 
-	return &manager{
+	m := &manager{
 		actionConfig:   actionConfig,
 		storageBackend: storageBackend,
 		kubeClient:     ownerRefClient,
 
 		releaseName: releaseName,
 		namespace:   cr.GetNamespace(),
+		maxHistory:  f.maxHistory,
+
+		postRenderer: f.postRenderer,
 
 		chart:  crChart,
 		values: values,
 		status: types.StatusFor(cr),
-	}, nil
+	}
+	if f.manageCRDs {
+		m.crdClient = f.mgr.GetClient()
+		m.crdReader = f.mgr.GetAPIReader()
+	}
+	return m, nil
+}
+
+func (f managerFactory) ReleaseName(cr *unstructured.Unstructured) (string, error) {
+	clientv1, err := v1.NewForConfig(f.mgr.GetConfig())
+	if err != nil {
+		return "", fmt.Errorf("failed to get core/v1 client: %w", err)
+	}
+	storageBackend := storage.Init(newStorageDriver(clientv1, f.storage, cr.GetNamespace()))
+	chartFile, err := chartutil.LoadChartfile(filepath.Join(f.chartDir, chartutil.ChartfileName))
+	if err != nil {
+		return "", fmt.Errorf("failed to load chart metadata: %w", err)
+	}
+	return getReleaseName(storageBackend, chartFile.Name, cr, f.releaseNameTemplate)
+}
+
+// migrateStorage moves the history of the releases cr may have from the
+// storage the factory migrates from to storageBackend. Each release is only
+// looked up in the previous storage once while the operator runs, rather than
+// on every reconcile.
+func (f managerFactory) migrateStorage(clientv1 v1.CoreV1Interface, storageBackend *storage.Storage,
+	cr *unstructured.Unstructured) error {
+	var names []string
+	for _, name := range migrationCandidates(cr, f.releaseNameTemplate) {
+		key := apitypes.NamespacedName{Namespace: cr.GetNamespace(), Name: name}
+		if _, done := f.migrated.Load(key); !done {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	previousBackend := storage.Init(newStorageDriver(clientv1, *f.storage.MigrateFrom, cr.GetNamespace()))
+	if err := migrateReleaseHistory(previousBackend, storageBackend, names...); err != nil {
+		return err
+	}
+	for _, name := range names {
+		f.migrated.Store(apitypes.NamespacedName{Namespace: cr.GetNamespace(), Name: name}, struct{}{})
+	}
+	return nil
 }
 
 // getReleaseName returns a release name for the CR.
 //
-// getReleaseName searches for a release using the CR name. If a release
-// cannot be found, or if it is found and was created by the chart managed
-// by this manager, the CR name is returned.
+// If no release name template is configured, getReleaseName searches for a
+// release using the CR name. If a release cannot be found, or if it is found
+// and was created by the chart managed by this manager, the CR name is
+// returned.
 //
 // If a release is found but it was created by another chart, that means we
 // have a release name collision, so return an error. This case is possible
 // because Kubernetes allows instances of different types to have the same name
-// in the same namespace.
+// in the same namespace. Configuring a release name template that includes the
+// kind and group avoids such collisions, and the optional release name webhook
+// rejects colliding CRs before they are created.
 //
-// TODO(jlanford): As noted above, using the CR name as the release name raises
-//   the possibility of collision. We should move this logic to a validating
-//   admission webhook so that the CR owner receives immediate feedback of the
-//   collision. As is, the only indication of collision will be in the CR status
-//   and operator logs.
+// When a release name template is configured, releases that already exist
+// keep their current name. The release recorded in the CR status is used
+// first, followed by a release named after the CR that was created by this
+// chart. Only CRs without an existing release get a name from the template.
 func getReleaseName(storageBackend *storage.Storage, crChartName string,
-	cr *unstructured.Unstructured) (string, error) {
-	// If a release with the CR name does not exist, return the CR name.
-	releaseName := cr.GetName()
-	history, exists, err := releaseHistory(storageBackend, releaseName)
+	cr *unstructured.Unstructured, nameTemplate string) (string, error) {
+	if nameTemplate == "" {
+		releaseName := cr.GetName()
+		if err := verifyReleaseChart(storageBackend, crChartName, releaseName); err != nil {
+			return "", err
+		}
+		return releaseName, nil
+	}
+
+	// Prefer the name of the release that was last deployed for this CR.
+	status := types.StatusFor(cr)
+	if status.DeployedRelease != nil && status.DeployedRelease.Name != "" {
+		releaseName := status.DeployedRelease.Name
+		if err := verifyReleaseChart(storageBackend, crChartName, releaseName); err != nil {
+			return "", err
+		}
+		return releaseName, nil
+	}
+
+	// Fall back to a release that was named after the CR before the template
+	// was configured. A release with that name created by a different chart
+	// belongs to something else, so it is ignored in favor of the template.
+	legacyName := cr.GetName()
+	history, exists, err := releaseHistory(storageBackend, legacyName)
 	if err != nil {
 		return "", err
 	}
+	if exists && history[0].Chart != nil && history[0].Chart.Name() == crChartName {
+		return legacyName, nil
+	}
+
+	releaseName, err := renderReleaseName(nameTemplate, cr)
+	if err != nil {
+		return "", err
+	}
+	if err := verifyReleaseChart(storageBackend, crChartName, releaseName); err != nil {
+		return "", err
+	}
+	return releaseName, nil
+}
+
+// migrationCandidates returns the names that a release of cr may have, which
+// are the names getReleaseName may return.
+func migrationCandidates(cr *unstructured.Unstructured, nameTemplate string) []string {
+	names := []string{cr.GetName()}
+	if name, err := releaseNameFor(cr, nameTemplate); err == nil && name != cr.GetName() {
+		names = append(names, name)
+	}
+	return names
+}
+
+// verifyReleaseChart returns an error if a release with the given name exists
+// and was created by a chart other than crChartName.
+func verifyReleaseChart(storageBackend *storage.Storage, crChartName, releaseName string) error {
+	history, exists, err := releaseHistory(storageBackend, releaseName)
+	if err != nil {
+		return err
+	}
 	if !exists {
-		return releaseName, nil
+		return nil
 	}
 
-	// If a release name with the CR name exists, but the release's chart is
+	// If a release with this name exists, but the release's chart is
 	// different than the chart managed by this operator, return an error
 	// because something else created the existing release.
 	if history[0].Chart == nil {
-		return "", fmt.Errorf("could not find chart metadata in release with name %q", releaseName)
+		return fmt.Errorf("could not find chart metadata in release with name %q", releaseName)
 	}
 	existingChartName := history[0].Chart.Name()
 	if existingChartName != crChartName {
-		return "", fmt.Errorf("duplicate release name: found existing release with name %q for chart %q",
+		return fmt.Errorf("duplicate release name: found existing release with name %q for chart %q",
 			releaseName, existingChartName)
 	}
+	return nil
+}
+
+// releaseNameData holds the variables available to a release name template.
+type releaseNameData struct {
+	Kind      string
+	Group     string
+	Name      string
+	Namespace string
+}
+
+var releaseNameFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// ParseReleaseNameTemplate parses a release name template. Templates use Go
+// text/template syntax and may reference the CR's {{ .Kind }}, {{ .Group }},
+// {{ .Name }} and {{ .Namespace }}. The lower and upper functions are
+// available to adjust case, since release names must be lowercase.
+func ParseReleaseNameTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("releaseName").Funcs(releaseNameFuncs).Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	// Execute the template once to catch references to unknown variables,
+	// which are otherwise only reported when a release is named.
+	if err := tmpl.Execute(ioutil.Discard, releaseNameData{}); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+// releaseNameFor returns the name of the release recorded in cr's status if
+// there is one. Otherwise, it returns the name rendered from nameTemplate, or
+// cr's name if nameTemplate is empty.
+func releaseNameFor(cr *unstructured.Unstructured, nameTemplate string) (string, error) {
+	status := types.StatusFor(cr)
+	if status.DeployedRelease != nil && status.DeployedRelease.Name != "" {
+		return status.DeployedRelease.Name, nil
+	}
+	if nameTemplate == "" {
+		return cr.GetName(), nil
+	}
+	return renderReleaseName(nameTemplate, cr)
+}
 
+func renderReleaseName(nameTemplate string, cr *unstructured.Unstructured) (string, error) {
+	tmpl, err := ParseReleaseNameTemplate(nameTemplate)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse release name template: %w", err)
+	}
+	gvk := cr.GroupVersionKind()
+	data := releaseNameData{
+		Kind:      gvk.Kind,
+		Group:     gvk.Group,
+		Name:      cr.GetName(),
+		Namespace: cr.GetNamespace(),
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("failed to render release name template: %w", err)
+	}
+	releaseName := strings.TrimSpace(buf.String())
+	if releaseName == "" {
+		return "", fmt.Errorf("release name template %q rendered an empty name", nameTemplate)
+	}
 	return releaseName, nil
 }
 
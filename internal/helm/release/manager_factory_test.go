@@ -0,0 +1,148 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	cpb "helm.sh/helm/v3/pkg/chart"
+	rpb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func newTestCR(kind, name string, status map[string]interface{}) *unstructured.Unstructured {
+	cr := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"apiVersion": "example.com/v1alpha1",
+			"kind":       kind,
+			"metadata": map[string]interface{}{
+				"name":      name,
+				"namespace": "ns",
+			},
+			"spec": map[string]interface{}{},
+		},
+	}
+	if status != nil {
+		cr.Object["status"] = status
+	}
+	return cr
+}
+
+func newTestStorage(t *testing.T, releases ...*rpb.Release) *storage.Storage {
+	s := storage.Init(driver.NewMemory())
+	for _, r := range releases {
+		if err := s.Create(r); err != nil {
+			t.Fatalf("Failed to create release %q: %v", r.Name, err)
+		}
+	}
+	return s
+}
+
+func newTestRelease(name, chartName string) *rpb.Release {
+	return &rpb.Release{
+		Name:      name,
+		Namespace: "ns",
+		Version:   1,
+		Info:      &rpb.Info{Status: rpb.StatusDeployed},
+		Chart:     &cpb.Chart{Metadata: &cpb.Metadata{Name: chartName}},
+	}
+}
+
+func TestGetReleaseName(t *testing.T) {
+	const tmpl = "{{ .Kind | lower }}-{{ .Name }}"
+	testCases := []struct {
+		name        string
+		cr          *unstructured.Unstructured
+		releases    []*rpb.Release
+		template    string
+		expectName  string
+		expectError bool
+	}{
+		{
+			name:       "no template uses CR name",
+			cr:         newTestCR("Foo", "test", nil),
+			expectName: "test",
+		},
+		{
+			name:        "no template collides with other chart",
+			cr:          newTestCR("Foo", "test", nil),
+			releases:    []*rpb.Release{newTestRelease("test", "other")},
+			expectError: true,
+		},
+		{
+			name:       "template names new release",
+			cr:         newTestCR("Foo", "test", nil),
+			template:   tmpl,
+			expectName: "foo-test",
+		},
+		{
+			name:       "template ignores legacy release of other chart",
+			cr:         newTestCR("Foo", "test", nil),
+			releases:   []*rpb.Release{newTestRelease("test", "other")},
+			template:   tmpl,
+			expectName: "foo-test",
+		},
+		{
+			name:       "template keeps legacy release of same chart",
+			cr:         newTestCR("Foo", "test", nil),
+			releases:   []*rpb.Release{newTestRelease("test", "chart")},
+			template:   tmpl,
+			expectName: "test",
+		},
+		{
+			name: "template keeps release from status",
+			cr: newTestCR("Foo", "test", map[string]interface{}{
+				"deployedRelease": map[string]interface{}{"name": "custom"},
+			}),
+			releases:   []*rpb.Release{newTestRelease("custom", "chart")},
+			template:   tmpl,
+			expectName: "custom",
+		},
+		{
+			name:        "template collides with other chart",
+			cr:          newTestCR("Foo", "test", nil),
+			releases:    []*rpb.Release{newTestRelease("foo-test", "other")},
+			template:    tmpl,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			s := newTestStorage(t, tc.releases...)
+			releaseName, err := getReleaseName(s, "chart", tc.cr, tc.template)
+			if tc.expectError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectName, releaseName)
+		})
+	}
+}
+
+func TestParseReleaseNameTemplate(t *testing.T) {
+	_, err := ParseReleaseNameTemplate("{{ .Group }}-{{ .Kind | lower }}-{{ .Namespace }}-{{ .Name }}")
+	assert.NoError(t, err)
+
+	_, err = ParseReleaseNameTemplate("{{ .Unknown }}")
+	assert.Error(t, err)
+
+	_, err = ParseReleaseNameTemplate("{{ .Name ")
+	assert.Error(t, err)
+}
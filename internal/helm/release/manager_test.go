@@ -15,8 +15,18 @@
 package release
 
 import (
+	"context"
+	"errors"
+	"io/ioutil"
 	"testing"
 
+	"helm.sh/helm/v3/pkg/action"
+	cpb "helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	apitypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/cli-runtime/pkg/resource"
@@ -213,3 +223,67 @@ func TestManagerGenerateStrategicMergePatch(t *testing.T) {
 		assert.Equal(t, test.patch, string(diff))
 	}
 }
+
+func newSyncTestManager(t *testing.T, values map[string]interface{}) *manager {
+	chart := &cpb.Chart{
+		Metadata: &cpb.Metadata{APIVersion: cpb.APIVersionV2, Name: "test", Version: "0.1.0"},
+		Values:   map[string]interface{}{"color": "red"},
+		Templates: []*cpb.File{{
+			Name: "templates/configmap.yaml",
+			Data: []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: test\ndata:\n  color: {{ .Values.color }}\n"),
+		}},
+	}
+	storageBackend := storage.Init(driver.NewMemory())
+	actionConfig := &action.Configuration{
+		Releases:     storageBackend,
+		KubeClient:   &kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log:          func(string, ...interface{}) {},
+	}
+
+	install := action.NewInstall(actionConfig)
+	install.ReleaseName = "test"
+	install.Namespace = "ns"
+	if _, err := install.Run(chart, map[string]interface{}{"color": "blue"}); err != nil {
+		t.Fatalf("failed to install release: %v", err)
+	}
+
+	return &manager{
+		actionConfig:   actionConfig,
+		storageBackend: storageBackend,
+		kubeClient:     actionConfig.KubeClient,
+		releaseName:    "test",
+		namespace:      "ns",
+		chart:          chart,
+		values:         values,
+	}
+}
+
+func TestManagerSyncUpgradeOptions(t *testing.T) {
+	m := newSyncTestManager(t, map[string]interface{}{"size": "small"})
+	assert.NoError(t, m.Sync(context.TODO()))
+	assert.True(t, m.IsUpgradeRequired(), "dropping the deployed values should require an upgrade")
+
+	m = newSyncTestManager(t, map[string]interface{}{"size": "small"})
+	reuseValues := true
+	opts := ActionOptions{ReuseValues: &reuseValues}
+	assert.NoError(t, m.Sync(context.TODO(), opts.UpgradeOption()))
+	assert.False(t, m.IsUpgradeRequired(), "reusing the deployed values should render the deployed manifest")
+	assert.Equal(t, m.deployedRelease.Manifest, m.candidateRelease.Manifest)
+}
+
+func TestManagerPreviewReleaseInstallOptions(t *testing.T) {
+	m := newSyncTestManager(t, map[string]interface{}{"color": "green"})
+	m.releaseName = "preview"
+	var applied bool
+	_, candidate, err := m.PreviewRelease(context.TODO(), func(i *action.Install) error {
+		applied = true
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, applied, "install options must be applied to the preview")
+	assert.Contains(t, candidate.Manifest, "color: green")
+
+	_, _, err = m.PreviewRelease(context.TODO(), func(*action.Install) error { return errors.New("invalid") })
+	assert.EqualError(t, err, "failed to apply install option: invalid")
+}
@@ -0,0 +1,138 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"errors"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DefaultWaitTimeout is the timeout used when waiting is enabled without a
+// timeout, matching the default of the helm CLI.
+const DefaultWaitTimeout = 5 * time.Minute
+
+// ActionOptions configures the Helm install, upgrade and uninstall actions.
+// Unset fields keep the action's default.
+type ActionOptions struct {
+	Wait         *bool            `json:"wait,omitempty"`
+	Timeout      *metav1.Duration `json:"timeout,omitempty"`
+	DisableHooks *bool            `json:"disableHooks,omitempty"`
+	SkipCRDs     *bool            `json:"skipCRDs,omitempty"`
+	RecreatePods *bool            `json:"recreatePods,omitempty"`
+	ResetValues  *bool            `json:"resetValues,omitempty"`
+	ReuseValues  *bool            `json:"reuseValues,omitempty"`
+	Description  *string          `json:"description,omitempty"`
+}
+
+// Validate returns an error if o contains invalid or conflicting settings.
+func (o ActionOptions) Validate() error {
+	if o.Timeout != nil && o.Timeout.Duration < 0 {
+		return errors.New("timeout must not be negative")
+	}
+	if isTrue(o.ResetValues) && isTrue(o.ReuseValues) {
+		return errors.New("resetValues and reuseValues must not both be true")
+	}
+	return nil
+}
+
+// Merge returns a copy of o in which the fields that are set in overlay
+// replace those of o.
+func (o ActionOptions) Merge(overlay ActionOptions) ActionOptions {
+	if overlay.Wait != nil {
+		o.Wait = overlay.Wait
+	}
+	if overlay.Timeout != nil {
+		o.Timeout = overlay.Timeout
+	}
+	if overlay.DisableHooks != nil {
+		o.DisableHooks = overlay.DisableHooks
+	}
+	if overlay.SkipCRDs != nil {
+		o.SkipCRDs = overlay.SkipCRDs
+	}
+	if overlay.RecreatePods != nil {
+		o.RecreatePods = overlay.RecreatePods
+	}
+	if overlay.ResetValues != nil {
+		o.ResetValues = overlay.ResetValues
+	}
+	if overlay.ReuseValues != nil {
+		o.ReuseValues = overlay.ReuseValues
+	}
+	if overlay.Description != nil {
+		o.Description = overlay.Description
+	}
+	return o
+}
+
+// InstallOption returns an InstallOption that applies o.
+func (o ActionOptions) InstallOption() InstallOption {
+	return func(i *action.Install) error {
+		i.Wait = isTrue(o.Wait)
+		i.Timeout = o.timeout()
+		i.DisableHooks = isTrue(o.DisableHooks)
+		i.SkipCRDs = isTrue(o.SkipCRDs)
+		if o.Description != nil {
+			i.Description = *o.Description
+		}
+		return nil
+	}
+}
+
+// UpgradeOption returns an UpgradeOption that applies o.
+func (o ActionOptions) UpgradeOption() UpgradeOption {
+	return func(u *action.Upgrade) error {
+		u.Wait = isTrue(o.Wait)
+		u.Timeout = o.timeout()
+		u.DisableHooks = isTrue(o.DisableHooks)
+		u.SkipCRDs = isTrue(o.SkipCRDs)
+		u.Recreate = isTrue(o.RecreatePods)
+		u.ResetValues = isTrue(o.ResetValues)
+		u.ReuseValues = isTrue(o.ReuseValues)
+		if o.Description != nil {
+			u.Description = *o.Description
+		}
+		return nil
+	}
+}
+
+// UninstallOption returns an UninstallOption that applies o.
+func (o ActionOptions) UninstallOption() UninstallOption {
+	return func(u *action.Uninstall) error {
+		u.Timeout = o.timeout()
+		u.DisableHooks = isTrue(o.DisableHooks)
+		if o.Description != nil {
+			u.Description = *o.Description
+		}
+		return nil
+	}
+}
+
+func (o ActionOptions) timeout() time.Duration {
+	if o.Timeout != nil {
+		return o.Timeout.Duration
+	}
+	if isTrue(o.Wait) {
+		return DefaultWaitTimeout
+	}
+	return 0
+}
+
+func isTrue(b *bool) bool {
+	return b != nil && *b
+}
@@ -0,0 +1,75 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func boolPtr(b bool) *bool { return &b }
+
+func TestActionOptionsValidate(t *testing.T) {
+	assert.NoError(t, ActionOptions{}.Validate())
+	assert.NoError(t, ActionOptions{ResetValues: boolPtr(true), ReuseValues: boolPtr(false)}.Validate())
+	assert.Error(t, ActionOptions{ResetValues: boolPtr(true), ReuseValues: boolPtr(true)}.Validate())
+	assert.Error(t, ActionOptions{Timeout: &metav1.Duration{Duration: -time.Second}}.Validate())
+}
+
+func TestActionOptionsMerge(t *testing.T) {
+	description := "from annotation"
+	base := ActionOptions{Wait: boolPtr(true), SkipCRDs: boolPtr(true)}
+	merged := base.Merge(ActionOptions{Wait: boolPtr(false), Description: &description})
+	assert.Equal(t, ActionOptions{Wait: boolPtr(false), SkipCRDs: boolPtr(true), Description: &description}, merged)
+	assert.True(t, *base.Wait, "Merge must not modify the receiver")
+}
+
+func TestActionOptionsApply(t *testing.T) {
+	description := "test"
+	opts := ActionOptions{
+		Wait:         boolPtr(true),
+		DisableHooks: boolPtr(true),
+		SkipCRDs:     boolPtr(true),
+		RecreatePods: boolPtr(true),
+		ReuseValues:  boolPtr(true),
+		Description:  &description,
+	}
+
+	install := &action.Install{}
+	assert.NoError(t, opts.InstallOption()(install))
+	assert.True(t, install.Wait)
+	assert.Equal(t, DefaultWaitTimeout, install.Timeout)
+	assert.True(t, install.DisableHooks)
+	assert.True(t, install.SkipCRDs)
+	assert.Equal(t, description, install.Description)
+
+	upgrade := &action.Upgrade{}
+	assert.NoError(t, opts.UpgradeOption()(upgrade))
+	assert.True(t, upgrade.Wait)
+	assert.True(t, upgrade.Recreate)
+	assert.True(t, upgrade.ReuseValues)
+	assert.False(t, upgrade.ResetValues)
+	assert.Equal(t, description, upgrade.Description)
+
+	opts.Timeout = &metav1.Duration{Duration: time.Minute}
+	uninstall := &action.Uninstall{}
+	assert.NoError(t, opts.UninstallOption()(uninstall))
+	assert.Equal(t, time.Minute, uninstall.Timeout)
+	assert.True(t, uninstall.DisableHooks)
+}
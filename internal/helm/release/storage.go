@@ -0,0 +1,202 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	rpb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/util/validation"
+	v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// Storage drivers that can hold release records.
+const (
+	StorageDriverSecrets    = "secrets"
+	StorageDriverConfigMaps = "configmaps"
+)
+
+// StorageConfig configures where release records are stored.
+type StorageConfig struct {
+	// Driver is the storage driver, either "secrets" (the default) or
+	// "configmaps".
+	Driver string `json:"driver,omitempty"`
+	// Namespace is a dedicated namespace that holds the release records of
+	// all custom resources. By default, records are stored in the namespace
+	// of their custom resource.
+	Namespace string `json:"namespace,omitempty"`
+	// MigrateFrom is the storage that release records were kept in before.
+	// Records found there are moved to this storage the next time their
+	// custom resource is reconciled.
+	MigrateFrom *StorageConfig `json:"migrateFrom,omitempty"`
+}
+
+// Validate returns an error if the storage configuration is invalid.
+func (c StorageConfig) Validate() error {
+	if err := c.validateLocation(); err != nil {
+		return err
+	}
+	if c.MigrateFrom == nil {
+		return nil
+	}
+	if c.MigrateFrom.MigrateFrom != nil {
+		return errors.New("storage migrateFrom cannot itself have a migrateFrom")
+	}
+	if err := c.MigrateFrom.validateLocation(); err != nil {
+		return fmt.Errorf("invalid storage migrateFrom: %w", err)
+	}
+	if c.MigrateFrom.driver() == c.driver() && c.MigrateFrom.Namespace == c.Namespace {
+		return errors.New("storage migrateFrom must differ from the storage it migrates to")
+	}
+	return nil
+}
+
+func (c StorageConfig) validateLocation() error {
+	switch c.Driver {
+	case "", StorageDriverSecrets, StorageDriverConfigMaps:
+	default:
+		return fmt.Errorf("unknown storage driver %q: must be %q or %q",
+			c.Driver, StorageDriverSecrets, StorageDriverConfigMaps)
+	}
+	if c.Namespace != "" {
+		if errs := validation.IsDNS1123Label(c.Namespace); len(errs) > 0 {
+			return fmt.Errorf("invalid storage namespace %q: %s", c.Namespace, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+func (c StorageConfig) driver() string {
+	if c.Driver == "" {
+		return StorageDriverSecrets
+	}
+	return c.Driver
+}
+
+// newStorageDriver returns the driver for the release records of custom
+// resources in crNamespace.
+func newStorageDriver(client v1.CoreV1Interface, c StorageConfig, crNamespace string) driver.Driver {
+	namespace := crNamespace
+	if c.Namespace != "" {
+		namespace = c.Namespace
+	}
+
+	var d driver.Driver
+	switch c.driver() {
+	case StorageDriverConfigMaps:
+		d = driver.NewConfigMaps(client.ConfigMaps(namespace))
+	default:
+		d = driver.NewSecrets(client.Secrets(namespace))
+	}
+	if c.Namespace != "" {
+		d = namespacedDriver{Driver: d, namespace: crNamespace}
+	}
+	return d
+}
+
+// namespacedDriver shares a storage namespace between the releases of
+// several namespaces. Release names are only unique within a namespace, so
+// the namespace is added to the key of each record, and queries only return
+// the releases of the namespace.
+type namespacedDriver struct {
+	driver.Driver
+	namespace string
+}
+
+const releaseKeyPrefix = "sh.helm.release.v1."
+
+func (d namespacedDriver) key(key string) string {
+	return releaseKeyPrefix + d.namespace + "." + strings.TrimPrefix(key, releaseKeyPrefix)
+}
+
+func (d namespacedDriver) Get(key string) (*rpb.Release, error) {
+	return d.Driver.Get(d.key(key))
+}
+
+func (d namespacedDriver) Create(key string, rls *rpb.Release) error {
+	return d.Driver.Create(d.key(key), rls)
+}
+
+func (d namespacedDriver) Update(key string, rls *rpb.Release) error {
+	return d.Driver.Update(d.key(key), rls)
+}
+
+func (d namespacedDriver) Delete(key string) (*rpb.Release, error) {
+	return d.Driver.Delete(d.key(key))
+}
+
+func (d namespacedDriver) List(filter func(*rpb.Release) bool) ([]*rpb.Release, error) {
+	return d.Driver.List(func(rls *rpb.Release) bool {
+		return rls.Namespace == d.namespace && filter(rls)
+	})
+}
+
+func (d namespacedDriver) Query(labels map[string]string) ([]*rpb.Release, error) {
+	all, err := d.Driver.Query(labels)
+	if err != nil {
+		return nil, err
+	}
+	var releases []*rpb.Release
+	for _, rls := range all {
+		if rls.Namespace == d.namespace {
+			releases = append(releases, rls)
+		}
+	}
+	if len(releases) == 0 {
+		return nil, driver.ErrReleaseNotFound
+	}
+	return releases, nil
+}
+
+// migrateReleaseHistory moves the records of the named releases from one
+// storage to another, without changing the releases themselves. Records that
+// already exist in the destination are not overwritten, so an interrupted
+// migration is completed the next time it runs. Records are only deleted from
+// the source once every record of the release was copied.
+func migrateReleaseHistory(from, to *storage.Storage, releaseNames ...string) error {
+	for _, name := range releaseNames {
+		history, exists, err := releaseHistory(from, name)
+		if err != nil {
+			return fmt.Errorf("failed to get release history to migrate: %w", err)
+		}
+		if !exists {
+			continue
+		}
+		releaseutil.SortByRevision(history)
+		for _, rls := range history {
+			_, err := to.Get(rls.Name, rls.Version)
+			if err == nil {
+				continue
+			}
+			if !notFoundErr(err) {
+				return fmt.Errorf("failed to get migrated release %s.v%d: %w", rls.Name, rls.Version, err)
+			}
+			if err := to.Create(rls); err != nil {
+				return fmt.Errorf("failed to migrate release %s.v%d: %w", rls.Name, rls.Version, err)
+			}
+		}
+		for _, rls := range history {
+			if _, err := from.Delete(rls.Name, rls.Version); err != nil && !notFoundErr(err) {
+				return fmt.Errorf("failed to delete migrated release %s.v%d: %w", rls.Name, rls.Version, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package release
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	rpb "helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func newStorageTestRelease(namespace, name string, version int, status rpb.Status) *rpb.Release {
+	return &rpb.Release{
+		Name:      name,
+		Namespace: namespace,
+		Version:   version,
+		Info:      &rpb.Info{Status: status},
+	}
+}
+
+func TestStorageConfigValidate(t *testing.T) {
+	assert.NoError(t, StorageConfig{}.Validate())
+	assert.NoError(t, StorageConfig{Driver: StorageDriverConfigMaps, Namespace: "helm"}.Validate())
+	assert.NoError(t, StorageConfig{Driver: StorageDriverConfigMaps, MigrateFrom: &StorageConfig{}}.Validate())
+	assert.NoError(t, StorageConfig{Namespace: "helm", MigrateFrom: &StorageConfig{Driver: StorageDriverSecrets}}.Validate())
+
+	assert.Error(t, StorageConfig{Driver: "sql"}.Validate())
+	assert.Error(t, StorageConfig{Namespace: "Not_A_Namespace"}.Validate())
+	assert.Error(t, StorageConfig{MigrateFrom: &StorageConfig{Driver: StorageDriverSecrets}}.Validate())
+	assert.Error(t, StorageConfig{Driver: StorageDriverConfigMaps, MigrateFrom: &StorageConfig{
+		MigrateFrom: &StorageConfig{Driver: StorageDriverConfigMaps},
+	}}.Validate())
+}
+
+func TestSharedStorageNamespace(t *testing.T) {
+	client := fake.NewSimpleClientset().CoreV1()
+	c := StorageConfig{Driver: StorageDriverConfigMaps, Namespace: "helm"}
+	storageA := storage.Init(newStorageDriver(client, c, "a"))
+	storageB := storage.Init(newStorageDriver(client, c, "b"))
+
+	require.NoError(t, storageA.Create(newStorageTestRelease("a", "foo", 1, rpb.StatusDeployed)))
+	require.NoError(t, storageB.Create(newStorageTestRelease("b", "foo", 1, rpb.StatusSuperseded)))
+	require.NoError(t, storageB.Create(newStorageTestRelease("b", "foo", 2, rpb.StatusDeployed)))
+
+	historyA, err := storageA.History("foo")
+	require.NoError(t, err)
+	assert.Len(t, historyA, 1)
+	historyB, err := storageB.History("foo")
+	require.NoError(t, err)
+	assert.Len(t, historyB, 2)
+
+	deployed, err := storageA.Deployed("foo")
+	require.NoError(t, err)
+	assert.Equal(t, "a", deployed.Namespace)
+	assert.Equal(t, 1, deployed.Version)
+
+	cms, err := client.ConfigMaps("helm").List(context.TODO(), metav1.ListOptions{})
+	require.NoError(t, err)
+	assert.Len(t, cms.Items, 3)
+	_, err = client.ConfigMaps("helm").Get(context.TODO(), "sh.helm.release.v1.a.foo.v1", metav1.GetOptions{})
+	assert.NoError(t, err)
+
+	_, err = storageA.Delete("foo", 1)
+	require.NoError(t, err)
+	_, err = storageA.History("foo")
+	assert.True(t, notFoundErr(err))
+	historyB, err = storageB.History("foo")
+	require.NoError(t, err)
+	assert.Len(t, historyB, 2)
+}
+
+func TestMigrateReleaseHistory(t *testing.T) {
+	client := fake.NewSimpleClientset().CoreV1()
+	from := storage.Init(newStorageDriver(client, StorageConfig{}, "default"))
+	to := storage.Init(newStorageDriver(client, StorageConfig{Driver: StorageDriverConfigMaps}, "default"))
+
+	require.NoError(t, from.Create(newStorageTestRelease("default", "foo", 1, rpb.StatusSuperseded)))
+	require.NoError(t, from.Create(newStorageTestRelease("default", "foo", 2, rpb.StatusDeployed)))
+	require.NoError(t, from.Create(newStorageTestRelease("default", "other", 1, rpb.StatusDeployed)))
+	// A record that was already copied by an interrupted migration.
+	require.NoError(t, to.Create(newStorageTestRelease("default", "foo", 1, rpb.StatusSuperseded)))
+
+	require.NoError(t, migrateReleaseHistory(from, to, "foo", "missing"))
+
+	history, err := to.History("foo")
+	require.NoError(t, err)
+	assert.Len(t, history, 2)
+	deployed, err := to.Deployed("foo")
+	require.NoError(t, err)
+	assert.Equal(t, 2, deployed.Version)
+
+	_, err = from.History("foo")
+	assert.True(t, notFoundErr(err))
+	_, err = from.History("other")
+	assert.NoError(t, err, "releases that are not migrated must be kept")
+
+	// Migrating again is a no-op.
+	require.NoError(t, migrateReleaseHistory(from, to, "foo"))
+}
+
+func TestManagerFactoryMigrateStorage(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+	client := clientset.CoreV1()
+	f := managerFactory{
+		storage:  StorageConfig{Driver: StorageDriverConfigMaps, MigrateFrom: &StorageConfig{}},
+		migrated: &sync.Map{},
+	}
+	from := storage.Init(newStorageDriver(client, *f.storage.MigrateFrom, "default"))
+	to := storage.Init(newStorageDriver(client, f.storage, "default"))
+	require.NoError(t, from.Create(newStorageTestRelease("default", "bar", 1, rpb.StatusDeployed)))
+
+	cr := newTestCR("Foo", "bar", nil)
+	cr.SetNamespace("default")
+	require.NoError(t, f.migrateStorage(client, to, cr))
+	_, err := to.History("bar")
+	assert.NoError(t, err)
+
+	// The previous storage is not looked up again for a migrated release.
+	actions := len(clientset.Actions())
+	require.NoError(t, f.migrateStorage(client, to, cr))
+	assert.Len(t, clientset.Actions(), actions)
+}
+
+func TestMigrationCandidates(t *testing.T) {
+	cr := newTestCR("Foo", "bar", nil)
+	assert.Equal(t, []string{"bar"}, migrationCandidates(cr, ""))
+	assert.Equal(t, []string{"bar", "foo-bar"}, migrationCandidates(cr, "{{ .Kind | lower }}-{{ .Name }}"))
+}
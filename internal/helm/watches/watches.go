@@ -22,8 +22,12 @@ import (
 	"os"
 
 	"helm.sh/helm/v3/pkg/chartutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"sigs.k8s.io/yaml"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/postrender"
+	"github.com/operator-framework/operator-sdk/internal/helm/release"
 )
 
 const WatchesFile = "watches.yaml"
@@ -31,10 +35,22 @@ const WatchesFile = "watches.yaml"
 // Watch defines options for configuring a watch for a Helm-based
 // custom resource.
 type Watch struct {
-	schema.GroupVersionKind `json:",inline"`
-	ChartDir                string            `json:"chart"`
-	WatchDependentResources *bool             `json:"watchDependentResources,omitempty"`
-	OverrideValues          map[string]string `json:"overrideValues,omitempty"`
+	schema.GroupVersionKind     `json:",inline"`
+	ChartDir                    string                    `json:"chart"`
+	WatchDependentResources     *bool                     `json:"watchDependentResources,omitempty"`
+	OverrideValues              map[string]string         `json:"overrideValues,omitempty"`
+	ReleaseNameTemplate         string                    `json:"releaseNameTemplate,omitempty"`
+	Selector                    metav1.LabelSelector      `json:"selector,omitempty"`
+	ReconcilePeriod             *metav1.Duration          `json:"reconcilePeriod,omitempty"`
+	MaxConcurrentReconciles     *int                      `json:"maxConcurrentReconciles,omitempty"`
+	Blacklist                   []schema.GroupVersionKind `json:"blacklist,omitempty"`
+	MaxHistory                  int                       `json:"maxHistory,omitempty"`
+	PostRenderer                *postrender.Config        `json:"postRenderer,omitempty"`
+	CheckReadiness              bool                      `json:"checkReadiness,omitempty"`
+	ActionOptions               release.ActionOptions     `json:"actionOptions,omitempty"`
+	ManageCRDs                  bool                      `json:"manageCRDs,omitempty"`
+	Storage                     release.StorageConfig     `json:"storage,omitempty"`
+	WatchClusterScopedResources bool                      `json:"watchClusterScopedResources,omitempty"`
 }
 
 // UnmarshalYAML unmarshals an individual watch from the Helm watches.yaml file
@@ -102,6 +118,42 @@ func LoadReader(reader io.Reader) ([]Watch, error) {
 			return nil, fmt.Errorf("invalid chart directory %s: %w", w.ChartDir, err)
 		}
 
+		if w.ReleaseNameTemplate != "" {
+			if _, err := release.ParseReleaseNameTemplate(w.ReleaseNameTemplate); err != nil {
+				return nil, fmt.Errorf("invalid release name template for %s: %w", gvk, err)
+			}
+		}
+
+		if _, err := metav1.LabelSelectorAsSelector(&w.Selector); err != nil {
+			return nil, fmt.Errorf("invalid selector for %s: %w", gvk, err)
+		}
+
+		if w.ReconcilePeriod != nil && w.ReconcilePeriod.Duration < 0 {
+			return nil, fmt.Errorf("invalid reconcile period for %s: must not be negative", gvk)
+		}
+
+		if w.MaxConcurrentReconciles != nil && *w.MaxConcurrentReconciles < 1 {
+			return nil, fmt.Errorf("invalid max concurrent reconciles for %s: must be at least 1", gvk)
+		}
+
+		if w.MaxHistory < 0 {
+			return nil, fmt.Errorf("invalid max history for %s: must not be negative", gvk)
+		}
+
+		if w.PostRenderer != nil {
+			if _, err := postrender.New(*w.PostRenderer); err != nil {
+				return nil, fmt.Errorf("invalid post-renderer for %s: %w", gvk, err)
+			}
+		}
+
+		if err := w.ActionOptions.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid action options for %s: %w", gvk, err)
+		}
+
+		if err := w.Storage.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid storage for %s: %w", gvk, err)
+		}
+
 		if _, ok := watchesMap[gvk]; ok {
 			return nil, fmt.Errorf("duplicate GVK: %s", gvk)
 		}
@@ -19,13 +19,19 @@ import (
 	"io/ioutil"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/operator-framework/operator-sdk/internal/helm/postrender"
+	"github.com/operator-framework/operator-sdk/internal/helm/release"
 )
 
 func TestLoadReader(t *testing.T) {
 	trueVal, falseVal := true, false
+	two := 2
 	testCases := []struct {
 		name          string
 		data          string
@@ -154,6 +160,242 @@ func TestLoadReader(t *testing.T) {
   overrideValues:
     key1:
 		key2: value
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid release name template",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseNameTemplate: "{{ .Kind | lower }}-{{ .Name }}"
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					ReleaseNameTemplate:     "{{ .Kind | lower }}-{{ .Name }}",
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid release name template",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  releaseNameTemplate: "{{ .Chart }}-{{ .Name }}"
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid per-watch options",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  selector:
+    matchLabels:
+      app: foo
+  reconcilePeriod: 30s
+  maxConcurrentReconciles: 2
+  maxHistory: 5
+  checkReadiness: true
+  blacklist:
+  - group: apps
+    version: v1
+    kind: Deployment
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					Selector:                metav1.LabelSelector{MatchLabels: map[string]string{"app": "foo"}},
+					ReconcilePeriod:         &metav1.Duration{Duration: 30 * time.Second},
+					MaxConcurrentReconciles: &two,
+					Blacklist:               []schema.GroupVersionKind{{Group: "apps", Version: "v1", Kind: "Deployment"}},
+					MaxHistory:              5,
+					CheckReadiness:          true,
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid selector",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  selector:
+    matchExpressions:
+    - key: app
+      operator: Bogus
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid max concurrent reconciles",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  maxConcurrentReconciles: 0
+`,
+			expectErr: true,
+		},
+		{
+			name: "invalid max history",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  maxHistory: -1
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid post-renderer",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  postRenderer:
+    patches:
+    - target:
+        kind: Deployment
+      patch: |
+        metadata:
+          labels:
+            team: foo
+    images:
+      docker.io/nginx: registry.example.com/nginx
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					PostRenderer: &postrender.Config{
+						Patches: []postrender.Patch{{
+							Target: postrender.Target{Kind: "Deployment"},
+							Patch:  "metadata:\n  labels:\n    team: foo\n",
+						}},
+						Images: map[string]string{"docker.io/nginx": "registry.example.com/nginx"},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid post-renderer",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  postRenderer:
+    patches:
+    - patch: not a patch
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid action options",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  actionOptions:
+    wait: true
+    timeout: 10m
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					ActionOptions: release.ActionOptions{
+						Wait:    &trueVal,
+						Timeout: &metav1.Duration{Duration: 10 * time.Minute},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid action options",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  actionOptions:
+    resetValues: true
+    reuseValues: true
+`,
+			expectErr: true,
+		},
+		{
+			name: "valid storage",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  storage:
+    driver: configmaps
+    namespace: helm-releases
+    migrateFrom:
+      driver: secrets
+`,
+			expectWatches: []Watch{
+				{
+					GroupVersionKind:        schema.GroupVersionKind{Group: "mygroup", Version: "v1alpha1", Kind: "MyKind"},
+					ChartDir:                "../../../internal/plugins/helm/v1/chartutil/testdata/test-chart",
+					WatchDependentResources: &trueVal,
+					Storage: release.StorageConfig{
+						Driver:      release.StorageDriverConfigMaps,
+						Namespace:   "helm-releases",
+						MigrateFrom: &release.StorageConfig{Driver: release.StorageDriverSecrets},
+					},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "invalid storage driver",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  storage:
+    driver: sql
+`,
+			expectErr: true,
+		},
+		{
+			name: "storage migrating from itself",
+			data: `---
+- group: mygroup
+  version: v1alpha1
+  kind: MyKind
+  chart: ../../../internal/plugins/helm/v1/chartutil/testdata/test-chart
+  storage:
+    migrateFrom:
+      driver: secrets
 `,
 			expectErr: true,
 		},
@@ -0,0 +1,141 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// ReleaseNamePath is the path at which the release name webhook is served.
+const ReleaseNamePath = "/validate-helm-release-name"
+
+var log = logf.Log.WithName("helm.webhook")
+
+// blank assignment to verify that ReleaseNameValidator implements admission.Handler
+var _ admission.Handler = &ReleaseNameValidator{}
+
+// ReleaseNamer returns the name of the Helm release that manages a custom
+// resource. It is implemented by release.ManagerFactory.
+type ReleaseNamer interface {
+	ReleaseName(cr *unstructured.Unstructured) (string, error)
+}
+
+// ReleaseNameValidator rejects custom resources whose Helm release name would
+// collide with the release of another custom resource in the same namespace
+// that is managed by this operator.
+type ReleaseNameValidator struct {
+	// Client is used to list the custom resources of every watched kind.
+	Client client.Reader
+	// ReleaseNamers maps each watched GVK to the namer of its releases.
+	ReleaseNamers map[schema.GroupVersionKind]ReleaseNamer
+}
+
+// Handle validates the release name of a created custom resource, or of an
+// updated custom resource whose release name changes. Updates of custom
+// resources that are being deleted are always allowed, so that their
+// finalizers can be removed.
+func (v *ReleaseNameValidator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	if req.Operation != admissionv1.Create && req.Operation != admissionv1.Update {
+		return admission.Allowed("")
+	}
+
+	cr, err := decodeCR(req.Object.Raw, req.Namespace)
+	if err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+	namer, ok := v.ReleaseNamers[cr.GroupVersionKind()]
+	if !ok {
+		return admission.Allowed("")
+	}
+	if req.Operation == admissionv1.Update && cr.GetDeletionTimestamp() != nil {
+		return admission.Allowed("")
+	}
+
+	releaseName, err := namer.ReleaseName(cr)
+	if err != nil {
+		return admission.Denied(err.Error())
+	}
+
+	if req.Operation == admissionv1.Update {
+		old, err := decodeCR(req.OldObject.Raw, req.Namespace)
+		if err != nil {
+			return admission.Errored(http.StatusBadRequest, err)
+		}
+		if oldName, err := namer.ReleaseName(old); err == nil && oldName == releaseName {
+			return admission.Allowed("")
+		}
+	}
+
+	owner, err := v.findReleaseOwner(ctx, cr, releaseName)
+	if err != nil {
+		log.Error(err, "Failed to check for release name collisions", "release", releaseName)
+		return admission.Errored(http.StatusInternalServerError, err)
+	}
+	if owner != nil {
+		return admission.Denied(fmt.Sprintf("release name %q is already used by %s %s/%s",
+			releaseName, owner.GetKind(), owner.GetNamespace(), owner.GetName()))
+	}
+	return admission.Allowed("")
+}
+
+// decodeCR decodes a custom resource of an admission request in namespace.
+func decodeCR(raw []byte, namespace string) (*unstructured.Unstructured, error) {
+	cr := &unstructured.Unstructured{}
+	if err := json.Unmarshal(raw, &cr.Object); err != nil {
+		return nil, err
+	}
+	if cr.GetNamespace() == "" {
+		cr.SetNamespace(namespace)
+	}
+	return cr, nil
+}
+
+// findReleaseOwner returns the custom resource, other than cr, in cr's
+// namespace whose release is named releaseName, or nil if there is none.
+func (v *ReleaseNameValidator) findReleaseOwner(ctx context.Context, cr *unstructured.Unstructured,
+	releaseName string) (*unstructured.Unstructured, error) {
+	for gvk, namer := range v.ReleaseNamers {
+		list := &unstructured.UnstructuredList{}
+		list.SetGroupVersionKind(gvk.GroupVersion().WithKind(gvk.Kind + "List"))
+		if err := v.Client.List(ctx, list, client.InNamespace(cr.GetNamespace())); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvk, err)
+		}
+		for i := range list.Items {
+			item := &list.Items[i]
+			if item.GroupVersionKind().GroupKind() == cr.GroupVersionKind().GroupKind() && item.GetName() == cr.GetName() {
+				continue
+			}
+			name, err := namer.ReleaseName(item)
+			if err != nil {
+				// The item's own release cannot be named, so it cannot collide.
+				continue
+			}
+			if name == releaseName {
+				return item, nil
+			}
+		}
+	}
+	return nil, nil
+}
@@ -0,0 +1,195 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+var (
+	fooGVK = schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Foo"}
+	barGVK = schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Bar"}
+)
+
+func newCR(gvk schema.GroupVersionKind, name string) *unstructured.Unstructured {
+	cr := &unstructured.Unstructured{}
+	cr.SetGroupVersionKind(gvk)
+	cr.SetNamespace("ns")
+	cr.SetName(name)
+	return cr
+}
+
+// namerFunc names releases with a function.
+type namerFunc func(cr *unstructured.Unstructured) (string, error)
+
+func (f namerFunc) ReleaseName(cr *unstructured.Unstructured) (string, error) { return f(cr) }
+
+var (
+	// byName names releases after their custom resource.
+	byName = namerFunc(func(cr *unstructured.Unstructured) (string, error) { return cr.GetName(), nil })
+	// byKind names releases after the kind and name of their custom resource.
+	byKind = namerFunc(func(cr *unstructured.Unstructured) (string, error) {
+		return strings.ToLower(cr.GetKind()) + "-" + cr.GetName(), nil
+	})
+	// byStatus names releases after the release deployed for their custom
+	// resource, if any, and otherwise like byKind.
+	byStatus = namerFunc(func(cr *unstructured.Unstructured) (string, error) {
+		if name, _, _ := unstructured.NestedString(cr.Object, "status", "deployedRelease", "name"); name != "" {
+			return name, nil
+		}
+		return byKind(cr)
+	})
+)
+
+func withDeployedRelease(cr *unstructured.Unstructured, name string) *unstructured.Unstructured {
+	cr = cr.DeepCopy()
+	_ = unstructured.SetNestedField(cr.Object, name, "status", "deployedRelease", "name")
+	return cr
+}
+
+func newRequest(t *testing.T, old, cr *unstructured.Unstructured) admission.Request {
+	req := admission.Request{AdmissionRequest: admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: cr.GetNamespace(),
+		Object:    runtime.RawExtension{Raw: marshalCR(t, cr)},
+	}}
+	if old != nil {
+		req.Operation = admissionv1.Update
+		req.OldObject = runtime.RawExtension{Raw: marshalCR(t, old)}
+	}
+	return req
+}
+
+func marshalCR(t *testing.T, cr *unstructured.Unstructured) []byte {
+	raw, err := json.Marshal(cr.Object)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return raw
+}
+
+func TestReleaseNameValidator(t *testing.T) {
+	deleting := newCR(fooGVK, "test")
+	now := metav1.Now()
+	deleting.SetDeletionTimestamp(&now)
+
+	testCases := []struct {
+		name        string
+		namers      map[schema.GroupVersionKind]ReleaseNamer
+		existing    []*unstructured.Unstructured
+		old         *unstructured.Unstructured
+		cr          *unstructured.Unstructured
+		expectAllow bool
+	}{
+		{
+			name:        "no existing resources",
+			namers:      map[schema.GroupVersionKind]ReleaseNamer{fooGVK: byName, barGVK: byName},
+			cr:          newCR(fooGVK, "test"),
+			expectAllow: true,
+		},
+		{
+			name:        "same name in other kind collides",
+			namers:      map[schema.GroupVersionKind]ReleaseNamer{fooGVK: byName, barGVK: byName},
+			existing:    []*unstructured.Unstructured{newCR(barGVK, "test")},
+			cr:          newCR(fooGVK, "test"),
+			expectAllow: false,
+		},
+		{
+			name:        "same name in other kind with template",
+			namers:      map[schema.GroupVersionKind]ReleaseNamer{fooGVK: byKind, barGVK: byKind},
+			existing:    []*unstructured.Unstructured{newCR(barGVK, "test")},
+			cr:          newCR(fooGVK, "test"),
+			expectAllow: true,
+		},
+		{
+			name:        "deployed release of other kind collides",
+			namers:      map[schema.GroupVersionKind]ReleaseNamer{fooGVK: byStatus, barGVK: byStatus},
+			existing:    []*unstructured.Unstructured{withDeployedRelease(newCR(barGVK, "other"), "foo-test")},
+			cr:          newCR(fooGVK, "test"),
+			expectAllow: false,
+		},
+		{
+			name:        "update of existing resource",
+			namers:      map[schema.GroupVersionKind]ReleaseNamer{fooGVK: byName},
+			existing:    []*unstructured.Unstructured{newCR(fooGVK, "test")},
+			old:         newCR(fooGVK, "test"),
+			cr:          newCR(fooGVK, "test"),
+			expectAllow: true,
+		},
+		{
+			name:        "update that keeps a colliding release name",
+			namers:      map[schema.GroupVersionKind]ReleaseNamer{fooGVK: byName, barGVK: byName},
+			existing:    []*unstructured.Unstructured{newCR(barGVK, "test")},
+			old:         newCR(fooGVK, "test"),
+			cr:          newCR(fooGVK, "test"),
+			expectAllow: true,
+		},
+		{
+			name:        "update that renames the release to a colliding name",
+			namers:      map[schema.GroupVersionKind]ReleaseNamer{fooGVK: byStatus, barGVK: byStatus},
+			existing:    []*unstructured.Unstructured{withDeployedRelease(newCR(barGVK, "other"), "foo-test")},
+			old:         withDeployedRelease(newCR(fooGVK, "test"), "test"),
+			cr:          newCR(fooGVK, "test"),
+			expectAllow: false,
+		},
+		{
+			name:        "update of resource being deleted",
+			namers:      map[schema.GroupVersionKind]ReleaseNamer{fooGVK: byStatus, barGVK: byStatus},
+			existing:    []*unstructured.Unstructured{withDeployedRelease(newCR(barGVK, "other"), "foo-test")},
+			old:         withDeployedRelease(deleting, "test"),
+			cr:          deleting,
+			expectAllow: true,
+		},
+		{
+			name:        "unwatched kind",
+			namers:      map[schema.GroupVersionKind]ReleaseNamer{barGVK: byName},
+			existing:    []*unstructured.Unstructured{newCR(barGVK, "test")},
+			cr:          newCR(fooGVK, "test"),
+			expectAllow: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			for gvk := range tc.namers {
+				scheme.AddKnownTypeWithName(gvk, &unstructured.Unstructured{})
+				scheme.AddKnownTypeWithName(gvk.GroupVersion().WithKind(gvk.Kind+"List"), &unstructured.UnstructuredList{})
+			}
+			objs := make([]runtime.Object, 0, len(tc.existing))
+			for _, o := range tc.existing {
+				objs = append(objs, o)
+			}
+			v := &ReleaseNameValidator{
+				Client:        fake.NewFakeClientWithScheme(scheme, objs...),
+				ReleaseNamers: tc.namers,
+			}
+			resp := v.Handle(context.TODO(), newRequest(t, tc.old, tc.cr))
+			assert.Equal(t, tc.expectAllow, resp.Allowed, resp.Result)
+		})
+	}
+}
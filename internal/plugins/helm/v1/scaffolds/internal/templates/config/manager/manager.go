@@ -94,5 +94,5 @@ spec:
           requests:
             cpu: 100m
             memory: 60Mi
-      terminationGracePeriodSeconds: 10
+      terminationGracePeriodSeconds: 40
 `
@@ -0,0 +1,218 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	log "github.com/sirupsen/logrus"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// artifactsIndexFile is the summary index of the artifacts of a run, relative to the artifacts directory.
+	artifactsIndexFile = "index.json"
+	// artifactsResourcesFile holds the resources labelled with a run's testrun label.
+	artifactsResourcesFile = "resources.yaml"
+
+	// artifactsTimeout is the time given to collect the artifacts of a test,
+	// regardless of how long the test's deadline is.
+	artifactsTimeout = 30 * time.Second
+)
+
+// artifactsIndex is the summary index of the artifacts collected during a run.
+type artifactsIndex struct {
+	// Namespace is the namespace tests were run in.
+	Namespace string `json:"namespace"`
+	// TestRun is the value of the testrun label of the run's pods, which is
+	// the name of the bundle ConfigMap.
+	TestRun string `json:"testRun"`
+	// Resources is the file the resources labelled with TestRun were saved to before cleanup.
+	Resources string `json:"resources,omitempty"`
+	// Tests are the artifacts of each test pod, in the order the pods finished.
+	Tests []testArtifacts `json:"tests"`
+	// Errors are the errors that occurred collecting artifacts of the run.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// testArtifacts are the artifacts of one test pod.
+type testArtifacts struct {
+	Name       string         `json:"name,omitempty"`
+	Image      string         `json:"image"`
+	Entrypoint []string       `json:"entrypoint,omitempty"`
+	Pod        string         `json:"pod"`
+	State      v1alpha3.State `json:"state"`
+	// Files are the collected files, relative to the artifacts directory.
+	Files []string `json:"files"`
+	// Errors are the errors that occurred collecting the artifacts.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// artifactsCollector saves the artifacts of test pods to a directory.
+type artifactsCollector struct {
+	dir string
+
+	mu    sync.Mutex
+	index artifactsIndex
+}
+
+// newArtifactsCollector returns a collector that saves the artifacts of a run
+// in namespace to dir.
+func newArtifactsCollector(dir, namespace string) (*artifactsCollector, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("error creating artifacts directory: %v", err)
+	}
+	c := &artifactsCollector{
+		dir:   dir,
+		index: artifactsIndex{Namespace: namespace, Tests: []testArtifacts{}},
+	}
+	return c, c.writeIndex()
+}
+
+// collectTestArtifacts saves the container logs, status and events of pod,
+// which ran test and finished with status, or nil if the test did not finish.
+func (r PodTestRunner) collectTestArtifacts(test TestConfiguration, pod *v1.Pod, status *v1alpha3.TestStatus) {
+	if r.artifacts == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), artifactsTimeout)
+	defer cancel()
+
+	entry := testArtifacts{
+		Name:       test.Labels["test"],
+		Image:      test.Image,
+		Entrypoint: test.Entrypoint,
+		Pod:        pod.Name,
+		State:      statusState(status),
+		Files:      []string{},
+	}
+	save := func(name string, data []byte, err error) {
+		if err == nil {
+			err = r.artifacts.write(filepath.Join(pod.Name, name), data)
+		}
+		if err != nil {
+			entry.Errors = append(entry.Errors, fmt.Sprintf("%s: %v", name, err))
+			return
+		}
+		entry.Files = append(entry.Files, filepath.Join(pod.Name, name))
+	}
+
+	current, err := r.Client.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if err == nil {
+		current.APIVersion, current.Kind = "v1", "Pod"
+		data, err := yaml.Marshal(current)
+		save("pod.yaml", data, err)
+	} else {
+		save("pod.yaml", nil, err)
+	}
+
+	for _, container := range append(pod.Spec.InitContainers, pod.Spec.Containers...) {
+		data, err := getContainerLog(ctx, r.Client, pod, container.Name)
+		save(container.Name+".log", data, err)
+	}
+
+	selector := fields.Set{"involvedObject.kind": "Pod", "involvedObject.name": pod.Name}.AsSelector()
+	events, err := r.Client.CoreV1().Events(pod.Namespace).List(ctx, metav1.ListOptions{FieldSelector: selector.String()})
+	if err == nil {
+		events.APIVersion, events.Kind = "v1", "EventList"
+		data, err := yaml.Marshal(events)
+		save("events.yaml", data, err)
+	} else {
+		save("events.yaml", nil, err)
+	}
+
+	r.artifacts.update(func(index *artifactsIndex) {
+		index.Tests = append(index.Tests, entry)
+	})
+}
+
+// collectRunArtifacts saves the resources labelled with the run's testrun label.
+func (r PodTestRunner) collectRunArtifacts(ctx context.Context) {
+	if r.artifacts == nil {
+		return
+	}
+	selector := fmt.Sprintf("testrun=%s", r.configMapName)
+	pods, err := r.Client.CoreV1().Pods(r.Namespace).List(ctx, metav1.ListOptions{LabelSelector: selector})
+	if err == nil {
+		pods.APIVersion, pods.Kind = "v1", "PodList"
+		var data []byte
+		if data, err = yaml.Marshal(pods); err == nil {
+			err = r.artifacts.write(artifactsResourcesFile, data)
+		}
+	}
+
+	r.artifacts.update(func(index *artifactsIndex) {
+		if err != nil {
+			index.Errors = append(index.Errors, fmt.Sprintf("%s: error saving resources (label selector %q): %v",
+				artifactsResourcesFile, selector, err))
+		} else {
+			index.Resources = artifactsResourcesFile
+		}
+	})
+}
+
+// update applies change to the index and rewrites it. Errors writing the
+// index cannot be recorded in it, so they are logged.
+func (c *artifactsCollector) update(change func(*artifactsIndex)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	change(&c.index)
+	if err := c.writeIndex(); err != nil {
+		log.Errorf("error writing scorecard artifacts index: %v", err)
+	}
+}
+
+// write writes data to name, relative to the artifacts directory.
+func (c *artifactsCollector) write(name string, data []byte) error {
+	path := filepath.Join(c.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, data, 0644)
+}
+
+// writeIndex writes the index. The caller must hold the lock once the collector is shared.
+func (c *artifactsCollector) writeIndex() error {
+	data, err := json.MarshalIndent(c.index, "", "  ")
+	if err != nil {
+		return err
+	}
+	return c.write(artifactsIndexFile, append(data, '\n'))
+}
+
+// statusState returns the state of the first result of status that did not
+// pass, pass if all passed, or error if the test did not finish.
+func statusState(status *v1alpha3.TestStatus) v1alpha3.State {
+	if status == nil || len(status.Results) == 0 {
+		return v1alpha3.ErrorState
+	}
+	for _, result := range status.Results {
+		if result.State != v1alpha3.PassState {
+			return result.State
+		}
+	}
+	return v1alpha3.PassState
+}
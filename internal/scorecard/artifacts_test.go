@@ -0,0 +1,186 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/yaml"
+)
+
+func readArtifactsIndex(t *testing.T, dir string) artifactsIndex {
+	data, err := ioutil.ReadFile(filepath.Join(dir, artifactsIndexFile))
+	require.NoError(t, err)
+	var index artifactsIndex
+	require.NoError(t, json.Unmarshal(data, &index))
+	return index
+}
+
+func TestPodTestRunnerArtifacts(t *testing.T) {
+	dir := t.TempDir()
+	client := fake.NewSimpleClientset()
+	// Finish test pods as soon as they are created.
+	client.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		pod := action.(clienttesting.CreateAction).GetObject().(*v1.Pod)
+		if pod.Labels["finish"] != "false" {
+			pod.Status.Phase = v1.PodSucceeded
+		}
+		return false, nil, nil
+	})
+	r := &PodTestRunner{
+		Namespace:    "scorecard",
+		BundlePath:   "testdata/bundle",
+		Client:       client,
+		ArtifactsDir: dir,
+	}
+	require.NoError(t, r.Initialize(context.Background()))
+
+	test := TestConfiguration{TestConfiguration: v1alpha3.TestConfiguration{
+		Image:      "quay.io/operator-framework/scorecard-test:dev",
+		Entrypoint: []string{"scorecard-test", "basic-check-spec"},
+		Labels:     map[string]string{"test": "basic-check-spec-test"},
+	}}
+	// The fake client's pod logs are not a test status, so the test fails.
+	status, err := r.RunTest(context.Background(), test)
+	require.NoError(t, err)
+	assert.Equal(t, v1alpha3.FailState, status.Results[0].State)
+
+	index := readArtifactsIndex(t, dir)
+	assert.Equal(t, "scorecard", index.Namespace)
+	assert.Equal(t, r.configMapName, index.TestRun)
+	require.Len(t, index.Tests, 1)
+	entry := index.Tests[0]
+	assert.Equal(t, "basic-check-spec-test", entry.Name)
+	assert.Equal(t, test.Image, entry.Image)
+	assert.Equal(t, test.Entrypoint, entry.Entrypoint)
+	assert.Equal(t, v1alpha3.FailState, entry.State)
+	assert.Empty(t, entry.Errors)
+	assert.Equal(t, []string{
+		filepath.Join(entry.Pod, "pod.yaml"),
+		filepath.Join(entry.Pod, "scorecard-untar.log"),
+		filepath.Join(entry.Pod, "scorecard-test.log"),
+		filepath.Join(entry.Pod, "events.yaml"),
+	}, entry.Files)
+
+	var pod v1.Pod
+	data, err := ioutil.ReadFile(filepath.Join(dir, entry.Pod, "pod.yaml"))
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, &pod))
+	assert.Equal(t, "Pod", pod.Kind)
+	assert.Equal(t, v1.PodSucceeded, pod.Status.Phase)
+	data, err = ioutil.ReadFile(filepath.Join(dir, entry.Pod, "scorecard-test.log"))
+	require.NoError(t, err)
+	assert.Equal(t, "fake logs", string(data))
+
+	// A test that times out is saved as an error.
+	test.Labels = map[string]string{"test": "slow-test"}
+	test.Pod = &PodConfiguration{}
+	client.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		action.(clienttesting.CreateAction).GetObject().(*v1.Pod).Labels["finish"] = "false"
+		return false, nil, nil
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = r.RunTest(ctx, test)
+	require.Error(t, err)
+	index = readArtifactsIndex(t, dir)
+	require.Len(t, index.Tests, 2)
+	assert.Equal(t, "slow-test", index.Tests[1].Name)
+	assert.Equal(t, v1alpha3.ErrorState, index.Tests[1].State)
+	// The pod of the test that timed out is deleted once it is saved.
+	assert.Contains(t, index.Tests[1].Files, filepath.Join(index.Tests[1].Pod, "pod.yaml"))
+	_, err = client.CoreV1().Pods("scorecard").Get(context.Background(), index.Tests[1].Pod, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "the pod of the test that timed out was not deleted")
+
+	r.collectRunArtifacts(context.Background())
+	index = readArtifactsIndex(t, dir)
+	assert.Equal(t, artifactsResourcesFile, index.Resources)
+	assert.Empty(t, index.Errors)
+	var pods v1.PodList
+	data, err = ioutil.ReadFile(filepath.Join(dir, artifactsResourcesFile))
+	require.NoError(t, err)
+	require.NoError(t, yaml.Unmarshal(data, &pods))
+	assert.Len(t, pods.Items, 1)
+}
+
+func TestScorecardArtifacts(t *testing.T) {
+	for _, skipCleanup := range []bool{false, true} {
+		dir := t.TempDir()
+		client := fake.NewSimpleClientset()
+		client.PrependReactor("create", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			action.(clienttesting.CreateAction).GetObject().(*v1.Pod).Status.Phase = v1.PodSucceeded
+			return false, nil, nil
+		})
+		r := &PodTestRunner{
+			Namespace:    "scorecard",
+			BundlePath:   "testdata/bundle",
+			Client:       client,
+			ArtifactsDir: dir,
+		}
+		o := Scorecard{
+			Config: Configuration{Stages: []StageConfiguration{{Tests: []TestConfiguration{{
+				TestConfiguration: v1alpha3.TestConfiguration{
+					Image:      "quay.io/operator-framework/scorecard-test:dev",
+					Entrypoint: []string{"scorecard-test", "basic-check-spec"},
+				},
+			}}}}},
+			TestRunner:  r,
+			SkipCleanup: skipCleanup,
+		}
+		_, err := o.Run(context.Background())
+		require.NoError(t, err)
+
+		// Resources are saved before cleanup deletes them, and when the run is
+		// not cleaned up.
+		index := readArtifactsIndex(t, dir)
+		assert.Equal(t, artifactsResourcesFile, index.Resources, "skipCleanup: %t", skipCleanup)
+		var pods v1.PodList
+		data, err := ioutil.ReadFile(filepath.Join(dir, artifactsResourcesFile))
+		require.NoError(t, err)
+		require.NoError(t, yaml.Unmarshal(data, &pods))
+		assert.Len(t, pods.Items, 1, "skipCleanup: %t", skipCleanup)
+
+		configMaps, err := client.CoreV1().ConfigMaps("scorecard").List(context.Background(), metav1.ListOptions{})
+		require.NoError(t, err)
+		if skipCleanup {
+			assert.Len(t, configMaps.Items, 1, "the run was cleaned up")
+		} else {
+			assert.Empty(t, configMaps.Items, "the run was not cleaned up")
+		}
+	}
+}
+
+func TestPodTestRunnerWithoutArtifacts(t *testing.T) {
+	r := &PodTestRunner{Namespace: "scorecard", BundlePath: "testdata/bundle", Client: fake.NewSimpleClientset()}
+	require.NoError(t, r.Initialize(context.Background()))
+	assert.Nil(t, r.artifacts)
+	// Collecting is a no-op without an artifacts directory.
+	r.collectTestArtifacts(TestConfiguration{}, &v1.Pod{}, nil)
+	r.collectRunArtifacts(context.Background())
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+// Comparison is the result of comparing the output of a run with the output
+// of a previous run, its baseline.
+type Comparison struct {
+	// Regressions are the tests that did not pass and passed in the baseline.
+	Regressions []v1alpha3.Test
+	// NewFailures are the tests that did not pass and are not in the baseline.
+	NewFailures []v1alpha3.Test
+	// Fixes are the tests that passed and did not pass in the baseline.
+	Fixes []v1alpha3.Test
+	// UnchangedFailures are the tests that did not pass, as in the baseline.
+	UnchangedFailures []v1alpha3.Test
+}
+
+// HasRegressions returns true if a test regressed or a new test did not pass.
+func (c Comparison) HasRegressions() bool {
+	return len(c.Regressions) > 0 || len(c.NewFailures) > 0
+}
+
+// LoadBaseline reads a v1alpha3.TestList, the JSON output of a previous run, from path.
+func LoadBaseline(path string) (v1alpha3.TestList, error) {
+	var list v1alpha3.TestList
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return list, fmt.Errorf("error reading baseline: %w", err)
+	}
+	if err := json.Unmarshal(b, &list); err != nil {
+		return list, fmt.Errorf("error parsing baseline %s: %w", path, err)
+	}
+	return list, nil
+}
+
+// Compare compares list, the output of Run, with baseline, the output of a
+// previous run, which may have selected other tests. Tests are matched by
+// stage, image, entrypoint and labels; tests of baseline that are not in list,
+// and skipped tests of list, are ignored.
+func (o Scorecard) Compare(baseline, list v1alpha3.TestList) Comparison {
+	previous := make(map[string][]v1alpha3.Test)
+	for _, stage := range o.Stages(baseline) {
+		for _, test := range stage.Tests {
+			key := baselineKey(stage.Name, test)
+			previous[key] = append(previous[key], test)
+		}
+	}
+
+	var c Comparison
+	for _, stage := range o.Stages(list) {
+		for _, test := range stage.Tests {
+			if skipped(test.Status) {
+				continue
+			}
+			key := baselineKey(stage.Name, test)
+			matches := previous[key]
+			found := len(matches) > 0
+			if found {
+				// Match duplicate tests in order.
+				previous[key] = matches[1:]
+			}
+			switch {
+			case !found:
+				if !passed(test.Status) {
+					c.NewFailures = append(c.NewFailures, test)
+				}
+			case passed(test.Status) && !passed(matches[0].Status):
+				c.Fixes = append(c.Fixes, test)
+			case !passed(test.Status) && passed(matches[0].Status):
+				c.Regressions = append(c.Regressions, test)
+			case !passed(test.Status):
+				c.UnchangedFailures = append(c.UnchangedFailures, test)
+			}
+		}
+	}
+	return c
+}
+
+// baselineKey identifies test of stage across runs. Labels set by scorecard
+// on the output of a test, like FlakyLabel and StageLabel, are not part of the key.
+func baselineKey(stage string, test v1alpha3.Test) string {
+	var labels []string
+	for k, v := range test.Spec.Labels {
+		if k == FlakyLabel || k == StageLabel {
+			continue
+		}
+		labels = append(labels, k+"="+v)
+	}
+	sort.Strings(labels)
+	return strings.Join([]string{
+		stage,
+		test.Spec.Image,
+		strings.Join(test.Spec.Entrypoint, " "),
+		strings.Join(labels, ","),
+	}, "\x00")
+}
+
+// MarshalText returns a summary of c that lists regressions, new failures,
+// fixes and unchanged failures separately.
+func (c Comparison) MarshalText() string {
+	var sb strings.Builder
+	sb.WriteString("Comparison with baseline:\n")
+	writeComparisonSection(&sb, "Regressions", c.Regressions)
+	writeComparisonSection(&sb, "New failures", c.NewFailures)
+	writeComparisonSection(&sb, "Fixes", c.Fixes)
+	writeComparisonSection(&sb, "Unchanged failures", c.UnchangedFailures)
+	return sb.String()
+}
+
+func writeComparisonSection(sb *strings.Builder, title string, tests []v1alpha3.Test) {
+	sb.WriteString(fmt.Sprintf("\t%s (%d)\n", title, len(tests)))
+	for _, test := range tests {
+		sb.WriteString(fmt.Sprintf("\t\t%s\n", testName(test, v1alpha3.TestResult{})))
+	}
+}
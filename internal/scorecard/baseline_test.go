@@ -0,0 +1,202 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func newBaselineList(states ...v1alpha3.State) v1alpha3.TestList {
+	names := []string{"basic-check-spec", "olm-bundle-validation", "olm-crds-have-validation"}
+	list := v1alpha3.NewTestList()
+	for i, state := range states {
+		list.Items = append(list.Items, newReportTest(names[i], "olm", v1alpha3.TestResult{Name: names[i], State: state}))
+	}
+	return list
+}
+
+func newBaselineScorecard() Scorecard {
+	list := newBaselineList("", "", "")
+	return Scorecard{
+		Config: Configuration{Stages: []StageConfiguration{
+			{Tests: []TestConfiguration{{TestConfiguration: list.Items[0].Spec}}},
+			{Tests: []TestConfiguration{{TestConfiguration: list.Items[1].Spec}, {TestConfiguration: list.Items[2].Spec}}},
+		}},
+		Selector: labels.Everything(),
+	}
+}
+
+func comparedNames(tests []v1alpha3.Test) (names []string) {
+	for _, test := range tests {
+		names = append(names, test.Status.Results[0].Name)
+	}
+	return names
+}
+
+func TestCompare(t *testing.T) {
+	o := newBaselineScorecard()
+	baseline := newBaselineList(v1alpha3.PassState, v1alpha3.FailState, v1alpha3.ErrorState)
+	list := newBaselineList(v1alpha3.FailState, v1alpha3.PassState, v1alpha3.FailState)
+
+	c := o.Compare(baseline, list)
+	assert.Equal(t, []string{"basic-check-spec"}, comparedNames(c.Regressions))
+	assert.Empty(t, c.NewFailures)
+	assert.Equal(t, []string{"olm-bundle-validation"}, comparedNames(c.Fixes))
+	assert.Equal(t, []string{"olm-crds-have-validation"}, comparedNames(c.UnchangedFailures))
+	assert.True(t, c.HasRegressions())
+
+	assert.Equal(t, "Comparison with baseline:\n"+
+		"\tRegressions (1)\n\t\tbasic-check-spec-test\n"+
+		"\tNew failures (0)\n"+
+		"\tFixes (1)\n\t\tolm-bundle-validation-test\n"+
+		"\tUnchanged failures (1)\n\t\tolm-crds-have-validation-test\n", c.MarshalText())
+}
+
+func TestCompareUnchanged(t *testing.T) {
+	o := newBaselineScorecard()
+	baseline := newBaselineList(v1alpha3.PassState, v1alpha3.FailState, v1alpha3.PassState)
+	list := newBaselineList(v1alpha3.PassState, v1alpha3.FailState, v1alpha3.PassState)
+	list.Items[2].Spec.Labels = map[string]string{FlakyLabel: "true"}
+	for k, v := range baseline.Items[2].Spec.Labels {
+		list.Items[2].Spec.Labels[k] = v
+	}
+
+	c := o.Compare(baseline, list)
+	assert.False(t, c.HasRegressions())
+	assert.Equal(t, []string{"olm-bundle-validation"}, comparedNames(c.UnchangedFailures))
+	assert.Empty(t, c.Fixes)
+}
+
+func TestCompareNewFailures(t *testing.T) {
+	o := newBaselineScorecard()
+	// The baseline predates the second test.
+	baseline := newBaselineList(v1alpha3.PassState, v1alpha3.PassState, v1alpha3.PassState)
+	baseline.Items = append(baseline.Items[:1], baseline.Items[2])
+	list := newBaselineList(v1alpha3.PassState, v1alpha3.FailState, v1alpha3.PassState)
+
+	c := o.Compare(baseline, list)
+	assert.Empty(t, c.Regressions)
+	assert.Equal(t, []string{"olm-bundle-validation"}, comparedNames(c.NewFailures))
+	assert.True(t, c.HasRegressions())
+}
+
+func TestCompareStages(t *testing.T) {
+	o := newBaselineScorecard()
+	// The same test in a different stage of the baseline is a new test.
+	o.Config.Stages[0].Tests = append(o.Config.Stages[0].Tests, o.Config.Stages[1].Tests[0])
+	o.Config.Stages[1].Tests = o.Config.Stages[1].Tests[1:]
+	baseline := newBaselineList(v1alpha3.PassState, v1alpha3.PassState, v1alpha3.PassState)
+	baseline.Items[1], baseline.Items[2] = baseline.Items[2], baseline.Items[1]
+	list := newBaselineList(v1alpha3.PassState, v1alpha3.FailState, v1alpha3.PassState)
+
+	c := o.Compare(baseline, list)
+	assert.Equal(t, []string{"olm-bundle-validation"}, comparedNames(c.NewFailures))
+}
+
+func TestCompareDuplicates(t *testing.T) {
+	// The run has the same test twice, e.g. with other pod settings, and the
+	// baseline has it once.
+	spec := newBaselineList("").Items[0].Spec
+	o := Scorecard{
+		Config: Configuration{Stages: []StageConfiguration{
+			{Tests: []TestConfiguration{{TestConfiguration: spec}, {TestConfiguration: spec}}},
+		}},
+		Selector: labels.Everything(),
+	}
+	baseline := newBaselineList(v1alpha3.PassState)
+	list := newBaselineList(v1alpha3.PassState)
+	list.Items = append(list.Items, newBaselineList(v1alpha3.FailState).Items[0])
+
+	c := o.Compare(baseline, list)
+	assert.Empty(t, c.Regressions)
+	assert.Equal(t, []string{"basic-check-spec"}, comparedNames(c.NewFailures))
+}
+
+func TestCompareImages(t *testing.T) {
+	// Custom tests with the same test label but other images are different tests.
+	o := newBaselineScorecard()
+	o.Config.Stages[0].Tests[0].Image = "quay.io/example/custom-test:v1"
+	baseline := newBaselineList(v1alpha3.FailState, v1alpha3.PassState, v1alpha3.PassState)
+	list := newBaselineList(v1alpha3.FailState, v1alpha3.PassState, v1alpha3.PassState)
+	list.Items[0].Spec.Image = "quay.io/example/custom-test:v1"
+
+	c := o.Compare(baseline, list)
+	assert.Empty(t, c.UnchangedFailures)
+	assert.Equal(t, []string{"basic-check-spec"}, comparedNames(c.NewFailures))
+}
+
+func TestCompareOtherConfiguration(t *testing.T) {
+	// The baseline was recorded with a configuration whose first stage had
+	// another test, which selecting by position would match with the tests of
+	// the current run.
+	baseline := newBaselineList(v1alpha3.PassState, v1alpha3.PassState, v1alpha3.FailState)
+	extra := newReportTest("olm-spec-descriptors", "olm", v1alpha3.TestResult{
+		Name:  "olm-spec-descriptors",
+		State: v1alpha3.FailState,
+	})
+	baseline.Items = append(baseline.Items[:1], append([]v1alpha3.Test{extra}, baseline.Items[1:]...)...)
+	for i, stage := range []string{"basic", "basic", "olm", "olm"} {
+		baseline.Items[i].Spec.Labels[StageLabel] = stage
+	}
+
+	o := newBaselineScorecard()
+	o.Config.Stages[0].Name = "basic"
+	o.Config.Stages[1].Name = "olm"
+	list := newBaselineList(v1alpha3.PassState, v1alpha3.PassState, v1alpha3.FailState)
+	for i, stage := range []string{"basic", "olm", "olm"} {
+		list.Items[i].Spec.Labels[StageLabel] = stage
+	}
+
+	c := o.Compare(baseline, list)
+	assert.False(t, c.HasRegressions())
+	assert.Empty(t, c.Fixes)
+	assert.Equal(t, []string{"olm-crds-have-validation"}, comparedNames(c.UnchangedFailures))
+
+	// A fix is found even if the current run selects fewer tests.
+	o.Selector = labels.SelectorFromSet(labels.Set{"test": "olm-crds-have-validation-test"})
+	list.Items = list.Items[2:]
+	list.Items[0].Status.Results[0].State = v1alpha3.PassState
+	c = o.Compare(baseline, list)
+	assert.False(t, c.HasRegressions())
+	assert.Equal(t, []string{"olm-crds-have-validation"}, comparedNames(c.Fixes))
+}
+
+func TestLoadBaseline(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "baseline.json")
+	baseline := newBaselineList(v1alpha3.PassState, v1alpha3.FailState)
+	b, err := json.Marshal(baseline)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(path, b, 0644))
+
+	list, err := LoadBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, baseline, list)
+
+	require.NoError(t, ioutil.WriteFile(path, []byte("{"), 0644))
+	_, err = LoadBaseline(path)
+	assert.Error(t, err)
+
+	_, err = LoadBaseline(filepath.Join(dir, "missing.json"))
+	assert.Error(t, err)
+}
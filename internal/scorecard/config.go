@@ -15,9 +15,12 @@
 package scorecard
 
 import (
+	"fmt"
 	"io/ioutil"
 
 	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"sigs.k8s.io/yaml"
 )
 
@@ -28,20 +31,201 @@ const (
 	DefaultConfigDir = "tests/scorecard/"
 )
 
+// Configuration is a v1alpha3.Configuration whose stages and tests can
+// also customise the pods that tests are run in.
+type Configuration struct {
+	metav1.TypeMeta `json:",inline"`
+
+	// Do not use metav1.ObjectMeta because this "object" should not be treated as an actual object.
+	Metadata struct {
+		// Name is a required field for kustomize-able manifests, and is not used on-cluster (nor is the config itself).
+		Name string `json:"name,omitempty"`
+	} `json:"metadata,omitempty"`
+
+	// Stages is a set of test stages to run. Once a stage is finished, the next stage in the slice will be run.
+	Stages []StageConfiguration `json:"stages"`
+}
+
+// StageConfiguration is a v1alpha3.StageConfiguration with pod settings.
+type StageConfiguration struct {
+	// Name identifies the stage in output and in DependsOn of later stages.
+	// The default is "stage-N" for the Nth stage.
+	Name string `json:"name,omitempty"`
+	// Parallel, if true, will run each test in tests in parallel.
+	// The default is to wait until a test finishes to run the next.
+	Parallel bool `json:"parallel,omitempty"`
+	// MaxParallel, if positive, limits how many tests of a parallel stage run at once.
+	MaxParallel int `json:"maxParallel,omitempty"`
+	// DependsOn are the names of earlier stages. Tests of this stage are
+	// skipped unless all tests of those stages passed.
+	DependsOn []string `json:"dependsOn,omitempty"`
+	// FailFast, if true, skips all later stages if a test of this stage does not pass.
+	FailFast bool `json:"failFast,omitempty"`
+	// Pod customises the pods of all tests in the stage.
+	Pod *PodConfiguration `json:"pod,omitempty"`
+	// Tests are a list of tests to run.
+	Tests []TestConfiguration `json:"tests"`
+}
+
+// TestConfiguration is a v1alpha3.TestConfiguration with retry and pod settings.
+type TestConfiguration struct {
+	v1alpha3.TestConfiguration `json:",inline"`
+
+	// Timeout is how long each attempt to run the test may take before it fails.
+	// By default attempts are only bounded by the deadline of the whole run.
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Retries is how many more times the test is run if it does not pass. A test
+	// that passes after a retry is labelled with FlakyLabel.
+	Retries int `json:"retries,omitempty"`
+	// Pod customises the test's pod. Fields set here override those set by the test's stage.
+	Pod *PodConfiguration `json:"pod,omitempty"`
+}
+
+// PodConfiguration customises the pod that a test is run in.
+type PodConfiguration struct {
+	// ServiceAccount runs the pod as this service account instead of the one set by --service-account.
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+	// Resources are the compute resources of both the test container and the untar init container.
+	Resources *v1.ResourceRequirements `json:"resources,omitempty"`
+	// NodeSelector constrains the nodes the pod is scheduled to.
+	NodeSelector map[string]string `json:"nodeSelector,omitempty"`
+	// Tolerations allow the pod to be scheduled to nodes with matching taints.
+	Tolerations []v1.Toleration `json:"tolerations,omitempty"`
+	// ImagePullPolicy is the pull policy of both containers. Defaults to IfNotPresent.
+	ImagePullPolicy v1.PullPolicy `json:"imagePullPolicy,omitempty"`
+	// ImagePullSecrets are used to pull the test and untar images.
+	ImagePullSecrets []v1.LocalObjectReference `json:"imagePullSecrets,omitempty"`
+	// Env is added to the test container's environment.
+	Env []v1.EnvVar `json:"env,omitempty"`
+	// Volumes are added to the pod, and mounted in the test container by VolumeMounts.
+	Volumes []v1.Volume `json:"volumes,omitempty"`
+	// VolumeMounts are added to the test container.
+	VolumeMounts []v1.VolumeMount `json:"volumeMounts,omitempty"`
+	// UntarImage is the image of the init container that unpacks the bundle. It must
+	// have a tar binary on its PATH, and sh and cat to unpack a bundle that is split
+	// across several ConfigMaps. Defaults to busybox.
+	UntarImage string `json:"untarImage,omitempty"`
+}
+
+// merge returns p with the fields set in override replaced by override's.
+func (p *PodConfiguration) merge(override *PodConfiguration) *PodConfiguration {
+	if p == nil {
+		return override
+	}
+	if override == nil {
+		return p
+	}
+	merged := *p
+	if override.ServiceAccount != "" {
+		merged.ServiceAccount = override.ServiceAccount
+	}
+	if override.Resources != nil {
+		merged.Resources = override.Resources
+	}
+	if override.NodeSelector != nil {
+		merged.NodeSelector = override.NodeSelector
+	}
+	if override.Tolerations != nil {
+		merged.Tolerations = override.Tolerations
+	}
+	if override.ImagePullPolicy != "" {
+		merged.ImagePullPolicy = override.ImagePullPolicy
+	}
+	if override.ImagePullSecrets != nil {
+		merged.ImagePullSecrets = override.ImagePullSecrets
+	}
+	if override.Env != nil {
+		merged.Env = override.Env
+	}
+	if override.Volumes != nil {
+		merged.Volumes = override.Volumes
+	}
+	if override.VolumeMounts != nil {
+		merged.VolumeMounts = override.VolumeMounts
+	}
+	if override.UntarImage != "" {
+		merged.UntarImage = override.UntarImage
+	}
+	return &merged
+}
+
+// validate returns an error if p sets a volume or volume mount that would
+// conflict with those that every test pod has.
+func (p *PodConfiguration) validate() error {
+	if p == nil {
+		return nil
+	}
+	for _, volume := range p.Volumes {
+		if volume.Name == bundleVolumeName || volume.Name == untarVolumeName {
+			return fmt.Errorf("volume name %q is reserved for the bundle", volume.Name)
+		}
+	}
+	for _, mount := range p.VolumeMounts {
+		if mount.MountPath == PodBundleRoot {
+			return fmt.Errorf("volume mount path %q is reserved for the bundle", mount.MountPath)
+		}
+	}
+	return nil
+}
+
 // LoadConfig will find and return the scorecard config, the config file
 // is found from a bundle location (TODO bundle image)
 // scorecard config.yaml is expected to be in the bundle at the following
 // location:  tests/scorecard/config.yaml
 // the user can override this location using the --config CLI flag
 // TODO: version this.
-func LoadConfig(configFilePath string) (v1alpha3.Configuration, error) {
-	c := v1alpha3.Configuration{}
+func LoadConfig(configFilePath string) (Configuration, error) {
+	c := Configuration{}
 
 	yamlFile, err := ioutil.ReadFile(configFilePath)
 	if err != nil {
 		return c, err
 	}
 
-	err = yaml.Unmarshal(yamlFile, &c)
-	return c, err
+	if err := yaml.Unmarshal(yamlFile, &c); err != nil {
+		return c, err
+	}
+	return c, c.validate()
+}
+
+// stageName returns the name of the stage at index i of c.
+func (c Configuration) stageName(i int) string {
+	if c.Stages[i].Name != "" {
+		return c.Stages[i].Name
+	}
+	return fmt.Sprintf("stage-%d", i+1)
+}
+
+func (c Configuration) validate() error {
+	names := make(map[string]bool, len(c.Stages))
+	for i, stage := range c.Stages {
+		for _, dep := range stage.DependsOn {
+			if !names[dep] {
+				return fmt.Errorf("invalid dependsOn of stage %d: %q is not the name of an earlier stage", i+1, dep)
+			}
+		}
+		name := c.stageName(i)
+		if names[name] {
+			return fmt.Errorf("invalid name of stage %d: %q is the name of an earlier stage", i+1, name)
+		}
+		names[name] = true
+		if stage.MaxParallel < 0 {
+			return fmt.Errorf("invalid maxParallel of stage %d: must not be negative", i+1)
+		}
+		if err := stage.Pod.validate(); err != nil {
+			return fmt.Errorf("invalid pod of stage %d: %v", i+1, err)
+		}
+		for j, test := range stage.Tests {
+			if test.Timeout != nil && test.Timeout.Duration <= 0 {
+				return fmt.Errorf("invalid timeout of test %d of stage %d: must be positive", j+1, i+1)
+			}
+			if test.Retries < 0 {
+				return fmt.Errorf("invalid retries of test %d of stage %d: must not be negative", j+1, i+1)
+			}
+			if err := test.Pod.validate(); err != nil {
+				return fmt.Errorf("invalid pod of test %d of stage %d: %v", j+1, i+1, err)
+			}
+		}
+	}
+	return nil
 }
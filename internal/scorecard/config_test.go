@@ -15,7 +15,13 @@
 package scorecard
 
 import (
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
 	"testing"
+	"time"
+
+	v1 "k8s.io/api/core/v1"
 )
 
 func TestInvalidConfigPath(t *testing.T) {
@@ -43,3 +49,194 @@ func TestInvalidConfigPath(t *testing.T) {
 
 	}
 }
+
+func TestLoadConfigPod(t *testing.T) {
+	cases := []struct {
+		name      string
+		config    string
+		wantError string
+	}{
+		{
+			name: "stage and test pods",
+			config: `stages:
+- pod:
+    untarImage: registry.example.com/busybox
+    nodeSelector:
+      kubernetes.io/os: linux
+  tests:
+  - image: quay.io/operator-framework/scorecard-test:dev
+    pod:
+      resources:
+        limits:
+          cpu: 100m
+          memory: 64Mi
+`,
+		},
+		{
+			name: "reserved volume name",
+			config: `stages:
+- tests:
+  - image: quay.io/operator-framework/scorecard-test:dev
+    pod:
+      volumes:
+      - name: scorecard-untar
+        emptyDir: {}
+`,
+			wantError: `invalid pod of test 1 of stage 1: volume name "scorecard-untar" is reserved for the bundle`,
+		},
+		{
+			name: "reserved mount path",
+			config: `stages:
+- pod:
+    volumeMounts:
+    - name: data
+      mountPath: /bundle
+  tests: []
+`,
+			wantError: `invalid pod of stage 1: volume mount path "/bundle" is reserved for the bundle`,
+		},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ConfigFileName)
+			if err := ioutil.WriteFile(path, []byte(c.config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadConfig(path)
+			if c.wantError != "" {
+				if err == nil || err.Error() != c.wantError {
+					t.Fatalf("Wanted error %q, got: %v", c.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Wanted result but got error: %v", err)
+			}
+			test := cfg.Stages[0].Tests[0]
+			if test.Image != "quay.io/operator-framework/scorecard-test:dev" {
+				t.Errorf("Wanted test image to be loaded, got %q", test.Image)
+			}
+			if cfg.Stages[0].Pod.UntarImage != "registry.example.com/busybox" {
+				t.Errorf("Wanted stage untar image to be loaded, got %q", cfg.Stages[0].Pod.UntarImage)
+			}
+			if test.Pod.Resources.Limits.Cpu().String() != "100m" {
+				t.Errorf("Wanted test resources to be loaded, got %v", test.Pod.Resources)
+			}
+		})
+	}
+}
+
+func TestPodConfigurationMerge(t *testing.T) {
+	stage := &PodConfiguration{
+		ServiceAccount: "stage-sa",
+		UntarImage:     "registry.example.com/busybox",
+		Env:            []v1.EnvVar{{Name: "STAGE", Value: "1"}},
+	}
+	test := &PodConfiguration{
+		ServiceAccount:  "test-sa",
+		ImagePullPolicy: v1.PullAlways,
+	}
+
+	if got := (*PodConfiguration)(nil).merge(test); got != test {
+		t.Errorf("Wanted test pod without a stage pod, got %v", got)
+	}
+	if got := stage.merge(nil); got != stage {
+		t.Errorf("Wanted stage pod without a test pod, got %v", got)
+	}
+
+	got := stage.merge(test)
+	want := &PodConfiguration{
+		ServiceAccount:  "test-sa",
+		UntarImage:      "registry.example.com/busybox",
+		ImagePullPolicy: v1.PullAlways,
+		Env:             []v1.EnvVar{{Name: "STAGE", Value: "1"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Wanted merged pod %+v, got %+v", want, got)
+	}
+	if stage.ServiceAccount != "stage-sa" {
+		t.Errorf("Wanted stage pod to be unchanged, got service account %q", stage.ServiceAccount)
+	}
+}
+
+func TestLoadConfigRetries(t *testing.T) {
+	cases := []struct {
+		name      string
+		test      string
+		wantError string
+	}{
+		{"valid", "timeout: 1m30s\n    retries: 2", ""},
+		{"zero timeout", "timeout: 0s", "invalid timeout of test 1 of stage 1: must be positive"},
+		{"negative retries", "retries: -1", "invalid retries of test 1 of stage 1: must not be negative"},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			config := "stages:\n- failFast: true\n  tests:\n  - image: test-image\n    " + c.test + "\n"
+			path := filepath.Join(t.TempDir(), ConfigFileName)
+			if err := ioutil.WriteFile(path, []byte(config), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadConfig(path)
+			if c.wantError != "" {
+				if err == nil || err.Error() != c.wantError {
+					t.Fatalf("Wanted error %q, got: %v", c.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Wanted result but got error: %v", err)
+			}
+			stage := cfg.Stages[0]
+			if !stage.FailFast || stage.Tests[0].Timeout.Duration != 90*time.Second || stage.Tests[0].Retries != 2 {
+				t.Errorf("Wanted fail fast stage with timeout and retries, got %+v", stage)
+			}
+		})
+	}
+}
+
+func TestLoadConfigStages(t *testing.T) {
+	cases := []struct {
+		name      string
+		stages    string
+		wantError string
+	}{
+		{"valid", "- name: install\n  parallel: true\n  maxParallel: 2\n" +
+			"- dependsOn: [install]\n- dependsOn: [install, stage-2]\n", ""},
+		{"negative maxParallel", "- maxParallel: -1\n", "invalid maxParallel of stage 1: must not be negative"},
+		{"duplicate name", "- name: install\n- name: install\n",
+			`invalid name of stage 2: "install" is the name of an earlier stage`},
+		{"duplicate default name", "- name: stage-2\n- {}\n",
+			`invalid name of stage 2: "stage-2" is the name of an earlier stage`},
+		{"later dependency", "- dependsOn: [upgrade]\n- name: upgrade\n",
+			`invalid dependsOn of stage 1: "upgrade" is not the name of an earlier stage`},
+		{"own dependency", "- name: install\n  dependsOn: [install]\n",
+			`invalid dependsOn of stage 1: "install" is not the name of an earlier stage`},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ConfigFileName)
+			if err := ioutil.WriteFile(path, []byte("stages:\n"+c.stages), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := LoadConfig(path)
+			if c.wantError != "" {
+				if err == nil || err.Error() != c.wantError {
+					t.Fatalf("Wanted error %q, got: %v", c.wantError, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Wanted result but got error: %v", err)
+			}
+			if name := cfg.stageName(0); name != "install" || cfg.Stages[0].MaxParallel != 2 {
+				t.Errorf("Wanted stage install with maxParallel 2, got %q: %+v", name, cfg.Stages[0])
+			}
+			if name := cfg.stageName(1); name != "stage-2" {
+				t.Errorf("Wanted default name stage-2, got %q", name)
+			}
+		})
+	}
+}
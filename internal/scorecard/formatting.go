@@ -45,7 +45,7 @@ func (o Scorecard) List() v1alpha3.TestList {
 		tests := o.selectTests(stage)
 		for _, test := range tests {
 			item := v1alpha3.NewTest()
-			item.Spec = test
+			item.Spec = test.TestConfiguration
 			output.Items = append(output.Items, item)
 		}
 	}
@@ -26,7 +26,7 @@ func TestEmptySelector(t *testing.T) {
 	cases := []struct {
 		selectorValue string
 		testsSelected int
-		config        v1alpha3.Configuration
+		config        Configuration
 		wantError     bool
 	}{
 		{"", 7, testConfig, false},
@@ -62,11 +62,11 @@ func TestEmptySelector(t *testing.T) {
 	}
 }
 
-var testConfig = v1alpha3.Configuration{
-	Stages: []v1alpha3.StageConfiguration{
+var testConfig = Configuration{
+	Stages: []StageConfiguration{
 		{
-			Tests: []v1alpha3.TestConfiguration{
-				{Image: "quay.io/someuser/customtest1:v0.0.1",
+			Tests: []TestConfiguration{
+				{TestConfiguration: v1alpha3.TestConfiguration{Image: "quay.io/someuser/customtest1:v0.0.1",
 					Entrypoint: []string{
 						"custom-test",
 					},
@@ -74,9 +74,9 @@ var testConfig = v1alpha3.Configuration{
 						"suite": "custom",
 						"test":  "customtest1",
 					},
-				},
+				}},
 
-				{Image: "quay.io/someuser/customtest2:v0.0.1",
+				{TestConfiguration: v1alpha3.TestConfiguration{Image: "quay.io/someuser/customtest2:v0.0.1",
 					Entrypoint: []string{
 						"custom-test",
 					},
@@ -84,9 +84,9 @@ var testConfig = v1alpha3.Configuration{
 						"suite": "custom",
 						"test":  "customtest2",
 					},
-				},
+				}},
 
-				{Image: "quay.io/redhat/basictests:v0.0.1",
+				{TestConfiguration: v1alpha3.TestConfiguration{Image: "quay.io/redhat/basictests:v0.0.1",
 					Entrypoint: []string{
 						"scorecard-test",
 						"basic-check-spec",
@@ -95,9 +95,9 @@ var testConfig = v1alpha3.Configuration{
 						"suite": "basic",
 						"test":  "basic-check-spec-test",
 					},
-				},
+				}},
 
-				{Image: "quay.io/redhat/basictests:v0.0.1",
+				{TestConfiguration: v1alpha3.TestConfiguration{Image: "quay.io/redhat/basictests:v0.0.1",
 					Entrypoint: []string{
 						"scorecard-test",
 						"basic-check-status",
@@ -106,9 +106,9 @@ var testConfig = v1alpha3.Configuration{
 						"suite": "basic",
 						"test":  "basic-check-status-test",
 					},
-				},
+				}},
 
-				{Image: "quay.io/redhat/olmtests:v0.0.1",
+				{TestConfiguration: v1alpha3.TestConfiguration{Image: "quay.io/redhat/olmtests:v0.0.1",
 					Entrypoint: []string{
 						"scorecard-test",
 						"olm-bundle-validation",
@@ -117,9 +117,9 @@ var testConfig = v1alpha3.Configuration{
 						"suite": "olm",
 						"test":  "olm-bundle-validation-test",
 					},
-				},
+				}},
 
-				{Image: "quay.io/redhat/olmtests:v0.0.1",
+				{TestConfiguration: v1alpha3.TestConfiguration{Image: "quay.io/redhat/olmtests:v0.0.1",
 					Entrypoint: []string{
 						"scorecard-test",
 						"olm-crds-have-validation",
@@ -128,8 +128,8 @@ var testConfig = v1alpha3.Configuration{
 						"suite": "olm",
 						"test":  "olm-crds-have-validation-test",
 					},
-				},
-				{Image: "quay.io/redhat/kuttltests:v0.0.1",
+				}},
+				{TestConfiguration: v1alpha3.TestConfiguration{Image: "quay.io/redhat/kuttltests:v0.0.1",
 					Entrypoint: []string{
 						"kuttl-test",
 						"olm-status-descriptors",
@@ -137,8 +137,30 @@ var testConfig = v1alpha3.Configuration{
 					Labels: map[string]string{
 						"suite": "kuttl",
 					},
-				},
+				}},
 			},
 		},
 	},
 }
+
+func TestSelectTestsMergesStagePod(t *testing.T) {
+	o := Scorecard{Selector: labels.Everything()}
+	stage := StageConfiguration{
+		Pod: &PodConfiguration{ServiceAccount: "stage-sa", UntarImage: "registry.example.com/busybox"},
+		Tests: []TestConfiguration{
+			{},
+			{Pod: &PodConfiguration{ServiceAccount: "test-sa"}},
+		},
+	}
+
+	tests := o.selectTests(stage)
+	if tests[0].Pod != stage.Pod {
+		t.Errorf("Wanted stage pod for a test without a pod, got %+v", tests[0].Pod)
+	}
+	if sa := tests[1].Pod.ServiceAccount; sa != "test-sa" {
+		t.Errorf("Wanted test service account to override the stage's, got %q", sa)
+	}
+	if image := tests[1].Pod.UntarImage; image != "registry.example.com/busybox" {
+		t.Errorf("Wanted stage untar image to be inherited, got %q", image)
+	}
+}
@@ -0,0 +1,99 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+	"github.com/operator-framework/operator-sdk/internal/scorecard/tests"
+)
+
+// BuiltinTestImageRepository is the repository of the image of the built-in
+// tests, which LocalTestRunner runs in-process for any tag of the image.
+const BuiltinTestImageRepository = "quay.io/operator-framework/scorecard-test"
+
+// LocalTestRunner runs the built-in tests that only inspect the bundle in-process
+// against the bundle on disk, so they can be run without a cluster. Other tests
+// finish in the error state.
+type LocalTestRunner struct {
+	BundlePath     string
+	BundleMetadata registryutil.Labels
+
+	bundle *apimanifests.Bundle
+}
+
+// Initialize reads the bundle under test.
+func (r *LocalTestRunner) Initialize(ctx context.Context) (err error) {
+	r.bundle, err = apimanifests.GetBundleFromDir(r.BundlePath)
+	if err != nil {
+		return fmt.Errorf("error reading bundle: %w", err)
+	}
+	return nil
+}
+
+// RunTest runs a built-in test that only inspects the bundle in-process.
+func (r LocalTestRunner) RunTest(ctx context.Context, test TestConfiguration) (*v1alpha3.TestStatus, error) {
+	if imageRepository(test.Image) != BuiltinTestImageRepository || len(test.Entrypoint) != 2 ||
+		test.Entrypoint[0] != "scorecard-test" {
+		return localError(fmt.Sprintf("test image %q with entrypoint %q cannot be run locally: "+
+			"the local runner only runs the built-in tests of %s, use --runner pod to run it in a cluster",
+			test.Image, strings.Join(test.Entrypoint, " "), BuiltinTestImageRepository)), nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, name := range tests.ClusterNames {
+		if test.Entrypoint[1] == name {
+			return localError(fmt.Sprintf("test %q deploys CRs to a cluster and cannot be run locally, "+
+				"use --runner pod to run it in a cluster", name)), nil
+		}
+	}
+	result, ok := tests.Run(test.Entrypoint[1], r.BundlePath, r.bundle, r.BundleMetadata)
+	if !ok {
+		return localError(fmt.Sprintf("%s has no built-in test %q, valid tests are: %s",
+			BuiltinTestImageRepository, test.Entrypoint[1], strings.Join(tests.Names, ", "))), nil
+	}
+	return &result, nil
+}
+
+// Cleanup does nothing, as running tests locally creates no resources.
+func (r LocalTestRunner) Cleanup(ctx context.Context) error {
+	return nil
+}
+
+// imageRepository returns image without its tag or digest.
+func imageRepository(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		image = image[:i]
+	}
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+func localError(message string) *v1alpha3.TestStatus {
+	return &v1alpha3.TestStatus{Results: []v1alpha3.TestResult{{
+		State:  v1alpha3.ErrorState,
+		Errors: []string{message},
+	}}}
+}
@@ -0,0 +1,109 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+func TestLocalTestRunner(t *testing.T) {
+	metadata, _, err := registryutil.FindBundleMetadata("testdata/bundle")
+	require.NoError(t, err)
+	cfg, err := LoadConfig("testdata/bundle/tests/scorecard/config.yaml")
+	require.NoError(t, err)
+
+	o := Scorecard{
+		Config:      cfg,
+		Selector:    labels.Everything(),
+		TestRunner:  &LocalTestRunner{BundlePath: "testdata/bundle", BundleMetadata: metadata},
+		SkipCleanup: true,
+	}
+	list, err := o.Run(context.Background())
+	require.NoError(t, err)
+	require.Len(t, list.Items, 6)
+	for _, test := range list.Items {
+		require.NotEmpty(t, test.Status.Results, test.Spec.Entrypoint)
+		for _, result := range test.Status.Results {
+			assert.NotEqual(t, v1alpha3.ErrorState, result.State, "%s: %v", test.Spec.Entrypoint, result.Errors)
+		}
+	}
+}
+
+func TestLocalTestRunnerRejectsTests(t *testing.T) {
+	r := &LocalTestRunner{BundlePath: "testdata/bundle"}
+	require.NoError(t, r.Initialize(context.Background()))
+
+	cases := []struct {
+		name       string
+		test       TestConfiguration
+		wantPrefix string
+	}{
+		{
+			name: "custom image",
+			test: TestConfiguration{TestConfiguration: v1alpha3.TestConfiguration{
+				Image:      "quay.io/example/custom-scorecard-tests:v0.1.0",
+				Entrypoint: []string{"custom-scorecard-tests", "customtest1"},
+			}},
+			wantPrefix: `test image "quay.io/example/custom-scorecard-tests:v0.1.0" with entrypoint ` +
+				`"custom-scorecard-tests customtest1" cannot be run locally`,
+		},
+		{
+			name: "cluster test",
+			test: TestConfiguration{TestConfiguration: v1alpha3.TestConfiguration{
+				Image:      BuiltinTestImageRepository + ":dev",
+				Entrypoint: []string{"scorecard-test", "basic-check-cleanup"},
+			}},
+			wantPrefix: `test "basic-check-cleanup" deploys CRs to a cluster and cannot be run locally`,
+		},
+		{
+			name: "unknown built-in test",
+			test: TestConfiguration{TestConfiguration: v1alpha3.TestConfiguration{
+				Image:      BuiltinTestImageRepository + "@sha256:abc",
+				Entrypoint: []string{"scorecard-test", "no-such-test"},
+			}},
+			wantPrefix: BuiltinTestImageRepository + ` has no built-in test "no-such-test"`,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			status, err := r.RunTest(context.Background(), c.test)
+			require.NoError(t, err)
+			require.Len(t, status.Results, 1)
+			assert.Equal(t, v1alpha3.ErrorState, status.Results[0].State)
+			require.Len(t, status.Results[0].Errors, 1)
+			assert.Contains(t, status.Results[0].Errors[0], c.wantPrefix)
+		})
+	}
+}
+
+func TestImageRepository(t *testing.T) {
+	for image, want := range map[string]string{
+		"quay.io/operator-framework/scorecard-test:v1.3.0":     "quay.io/operator-framework/scorecard-test",
+		"quay.io/operator-framework/scorecard-test@sha256:abc": "quay.io/operator-framework/scorecard-test",
+		"quay.io/operator-framework/scorecard-test":            "quay.io/operator-framework/scorecard-test",
+		"localhost:5000/scorecard-test":                        "localhost:5000/scorecard-test",
+		"localhost:5000/scorecard-test:dev":                    "localhost:5000/scorecard-test",
+	} {
+		assert.Equal(t, want, imageRepository(image), image)
+	}
+}
@@ -0,0 +1,299 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"sigs.k8s.io/yaml"
+)
+
+// StageOutput is the output of the tests of a stage.
+type StageOutput struct {
+	// Name identifies the stage: its configured name, or e.g. "stage-1" for
+	// the first stage of the configuration if it has none.
+	Name  string
+	Tests []v1alpha3.Test
+}
+
+// Stages splits list, the output of Run or List, into the stages of the
+// configuration that its tests were selected from. Stages without selected
+// tests are omitted. Tests are assigned to stages by their StageLabel, or, in
+// output written before tests were labelled with their stage, by position.
+func (o Scorecard) Stages(list v1alpha3.TestList) []StageOutput {
+	if stages, ok := labelledStages(list); ok {
+		return stages
+	}
+	var stages []StageOutput
+	items := list.Items
+	for i, stage := range o.Config.Stages {
+		n := len(o.selectTests(stage))
+		if n > len(items) {
+			n = len(items)
+		}
+		if n == 0 {
+			continue
+		}
+		stages = append(stages, StageOutput{Name: o.Config.stageName(i), Tests: items[:n]})
+		items = items[n:]
+	}
+	return stages
+}
+
+// labelledStages groups the tests of list by their StageLabel, in order. It
+// returns false if a test has no StageLabel.
+func labelledStages(list v1alpha3.TestList) ([]StageOutput, bool) {
+	var stages []StageOutput
+	index := make(map[string]int)
+	for _, test := range list.Items {
+		name, ok := test.Spec.Labels[StageLabel]
+		if !ok {
+			return nil, false
+		}
+		i, found := index[name]
+		if !found {
+			i = len(stages)
+			index[name] = i
+			stages = append(stages, StageOutput{Name: name})
+		}
+		stages[i].Tests = append(stages[i].Tests, test)
+	}
+	return stages, true
+}
+
+// MarshalText returns test, an item of the output of Run, as text. Unlike
+// test.MarshalText, it prints the states that extend v1alpha3.TestResult's.
+func MarshalText(test v1alpha3.Test) string {
+	text := test.MarshalText()
+	// test.MarshalText prints a state it does not know as "unknown", without
+	// the empty line that follows known states.
+	const unknown = "\tState: unknown\n"
+	var sb strings.Builder
+	for _, result := range test.Status.Results {
+		switch result.State {
+		case v1alpha3.PassState, v1alpha3.FailState, v1alpha3.ErrorState:
+			continue
+		}
+		i := strings.Index(text, unknown)
+		if i < 0 {
+			break
+		}
+		sb.WriteString(text[:i])
+		if result.State == SkippedState {
+			fmt.Fprintf(&sb, "\tState: %s\n\n", result.State)
+		} else {
+			sb.WriteString(unknown)
+		}
+		text = text[i+len(unknown):]
+	}
+	sb.WriteString(text)
+	return sb.String()
+}
+
+// testName returns the name of the test case of result, a result of test.
+func testName(test v1alpha3.Test, result v1alpha3.TestResult) string {
+	switch {
+	case result.Name != "":
+		return result.Name
+	case test.Spec.Labels["test"] != "":
+		return test.Spec.Labels["test"]
+	default:
+		return strings.Join(append([]string{test.Spec.Image}, test.Spec.Entrypoint...), " ")
+	}
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Errors   int              `xml:"errors,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr,omitempty"`
+	TestCases []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message  string `xml:"message,attr"`
+	Contents string `xml:",chardata"`
+}
+
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// MarshalJUnit returns list, the output of Run, as JUnit XML. Each stage is a
+// test suite, and each test result is a test case whose class is the test's
+// suite label, or image. Errors are written as failure messages, and
+// suggestions and logs as the test case's output. Skipped results are
+// skipped test cases with the reason they were skipped.
+func (o Scorecard) MarshalJUnit(list v1alpha3.TestList) ([]byte, error) {
+	suites := junitTestSuites{Name: "scorecard"}
+	for _, stage := range o.Stages(list) {
+		suite := junitTestSuite{Name: stage.Name}
+		for _, test := range stage.Tests {
+			className := test.Spec.Labels["suite"]
+			if className == "" {
+				className = test.Spec.Image
+			}
+			for _, result := range test.Status.Results {
+				tc := junitTestCase{
+					Name:      testName(test, result),
+					ClassName: className,
+				}
+				switch result.State {
+				case SkippedState:
+					tc.Skipped = &junitSkipped{Message: result.Log}
+					suite.Skipped++
+					suite.TestCases = append(suite.TestCases, tc)
+					continue
+				case v1alpha3.PassState:
+				case v1alpha3.FailState:
+					tc.Failure = newJUnitFailure(result, "test failed")
+					suite.Failures++
+				default:
+					tc.Error = newJUnitFailure(result, fmt.Sprintf("test finished in state %q", result.State))
+					suite.Errors++
+				}
+				tc.SystemOut = junitSystemOut(result)
+				suite.TestCases = append(suite.TestCases, tc)
+			}
+		}
+		suite.Tests = len(suite.TestCases)
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Errors += suite.Errors
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	out, err := xml.MarshalIndent(suites, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
+
+func newJUnitFailure(result v1alpha3.TestResult, message string) *junitFailure {
+	if len(result.Errors) > 0 {
+		message = result.Errors[0]
+	}
+	return &junitFailure{Message: message, Contents: strings.Join(result.Errors, "\n")}
+}
+
+func junitSystemOut(result v1alpha3.TestResult) string {
+	var sb strings.Builder
+	if len(result.Suggestions) > 0 {
+		sb.WriteString("Suggestions:\n")
+		for _, suggestion := range result.Suggestions {
+			fmt.Fprintf(&sb, "  %s\n", suggestion)
+		}
+	}
+	if result.Log != "" {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("Log:\n")
+		sb.WriteString(result.Log)
+	}
+	return sb.String()
+}
+
+// tapDiagnostic is the YAML diagnostic block of a TAP test line.
+type tapDiagnostic struct {
+	State       v1alpha3.State `json:"state"`
+	Image       string         `json:"image"`
+	Entrypoint  []string       `json:"entrypoint,omitempty"`
+	Errors      []string       `json:"errors,omitempty"`
+	Suggestions []string       `json:"suggestions,omitempty"`
+	Log         string         `json:"log,omitempty"`
+}
+
+// MarshalTAP returns list, the output of Run, in the Test Anything Protocol
+// version 13. Each test result is a test line, preceded by a comment naming
+// its stage. Results that did not pass have a YAML diagnostic block with their
+// errors, suggestions and log, and flaky results are followed by a comment.
+// Skipped results are test lines with a SKIP directive giving the reason.
+func (o Scorecard) MarshalTAP(list v1alpha3.TestList) ([]byte, error) {
+	stages := o.Stages(list)
+	count := 0
+	for _, stage := range stages {
+		for _, test := range stage.Tests {
+			count += len(test.Status.Results)
+		}
+	}
+	var buf bytes.Buffer
+	buf.WriteString("TAP version 13\n")
+	fmt.Fprintf(&buf, "1..%d\n", count)
+
+	n := 0
+	for _, stage := range stages {
+		fmt.Fprintf(&buf, "# %s\n", stage.Name)
+		for _, test := range stage.Tests {
+			for _, result := range test.Status.Results {
+				n++
+				name := testName(test, result)
+				if result.State == SkippedState {
+					fmt.Fprintf(&buf, "ok %d - %s # SKIP %s\n", n, name, result.Log)
+					continue
+				}
+				if result.State == v1alpha3.PassState {
+					fmt.Fprintf(&buf, "ok %d - %s\n", n, name)
+					if test.Spec.Labels[FlakyLabel] == "true" {
+						fmt.Fprintf(&buf, "# %s is flaky: it passed only after a retry\n", name)
+					}
+					continue
+				}
+				fmt.Fprintf(&buf, "not ok %d - %s\n", n, name)
+				diag, err := yaml.Marshal(tapDiagnostic{
+					State:       result.State,
+					Image:       test.Spec.Image,
+					Entrypoint:  test.Spec.Entrypoint,
+					Errors:      result.Errors,
+					Suggestions: result.Suggestions,
+					Log:         result.Log,
+				})
+				if err != nil {
+					return nil, err
+				}
+				buf.WriteString("  ---\n")
+				for _, line := range strings.Split(strings.TrimSuffix(string(diag), "\n"), "\n") {
+					fmt.Fprintf(&buf, "  %s\n", line)
+				}
+				buf.WriteString("  ...\n")
+			}
+		}
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,199 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"testing"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func newReportTest(name, suite string, result v1alpha3.TestResult) v1alpha3.Test {
+	test := v1alpha3.NewTest()
+	test.Spec = v1alpha3.TestConfiguration{
+		Image:      "quay.io/operator-framework/scorecard-test:dev",
+		Entrypoint: []string{"scorecard-test", name},
+		Labels:     map[string]string{"suite": suite, "test": name + "-test"},
+	}
+	test.Status.Results = []v1alpha3.TestResult{result}
+	return test
+}
+
+func newReportScorecard() (Scorecard, v1alpha3.TestList) {
+	spec := newReportTest("basic-check-spec", "basic", v1alpha3.TestResult{})
+	bundle := newReportTest("olm-bundle-validation", "olm", v1alpha3.TestResult{})
+	crds := newReportTest("olm-crds-have-validation", "olm", v1alpha3.TestResult{})
+	o := Scorecard{
+		Config: Configuration{Stages: []StageConfiguration{
+			{Tests: []TestConfiguration{{TestConfiguration: spec.Spec}}},
+			{Tests: []TestConfiguration{{TestConfiguration: bundle.Spec}, {TestConfiguration: crds.Spec}}},
+		}},
+		Selector: labels.Everything(),
+	}
+
+	list := v1alpha3.NewTestList()
+	list.Items = []v1alpha3.Test{
+		newReportTest("basic-check-spec", "basic", v1alpha3.TestResult{
+			Name:  "basic-check-spec",
+			State: v1alpha3.PassState,
+		}),
+		newReportTest("olm-bundle-validation", "olm", v1alpha3.TestResult{
+			Name:  "olm-bundle-validation",
+			State: v1alpha3.ErrorState,
+			Log:   "pod timed out",
+		}),
+		newReportTest("olm-crds-have-validation", "olm", v1alpha3.TestResult{
+			State:       v1alpha3.FailState,
+			Errors:      []string{"spec.size has no validation", "status has no validation"},
+			Suggestions: []string{"Add validation to spec.size"},
+		}),
+	}
+	return o, list
+}
+
+func TestStages(t *testing.T) {
+	o, list := newReportScorecard()
+	stages := o.Stages(list)
+	require.Len(t, stages, 2)
+	assert.Equal(t, "stage-1", stages[0].Name)
+	assert.Equal(t, list.Items[:1], stages[0].Tests)
+	assert.Equal(t, "stage-2", stages[1].Name)
+	assert.Equal(t, list.Items[1:], stages[1].Tests)
+
+	// Stages without selected tests are omitted, but keep their position in the name.
+	o.Selector = labels.SelectorFromSet(labels.Set{"suite": "olm"})
+	stages = o.Stages(v1alpha3.TestList{Items: list.Items[1:]})
+	require.Len(t, stages, 1)
+	assert.Equal(t, "stage-2", stages[0].Name)
+	assert.Len(t, stages[0].Tests, 2)
+}
+
+func TestMarshalJUnit(t *testing.T) {
+	o, list := newReportScorecard()
+	out, err := o.MarshalJUnit(list)
+	require.NoError(t, err)
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites name="scorecard" tests="3" failures="1" errors="1">
+  <testsuite name="stage-1" tests="1" failures="0" errors="0">
+    <testcase name="basic-check-spec" classname="basic"></testcase>
+  </testsuite>
+  <testsuite name="stage-2" tests="2" failures="1" errors="1">
+    <testcase name="olm-bundle-validation" classname="olm">
+      <error message="test finished in state &#34;error&#34;"></error>
+      <system-out>Log:&#xA;pod timed out</system-out>
+    </testcase>
+    <testcase name="olm-crds-have-validation-test" classname="olm">
+      <failure message="spec.size has no validation">spec.size has no validation&#xA;status has no validation</failure>
+      <system-out>Suggestions:&#xA;  Add validation to spec.size&#xA;</system-out>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, string(out))
+}
+
+func TestMarshalTAP(t *testing.T) {
+	o, list := newReportScorecard()
+	out, err := o.MarshalTAP(list)
+	require.NoError(t, err)
+	assert.Equal(t, `TAP version 13
+1..3
+# stage-1
+ok 1 - basic-check-spec
+# stage-2
+not ok 2 - olm-bundle-validation
+  ---
+  entrypoint:
+  - scorecard-test
+  - olm-bundle-validation
+  image: quay.io/operator-framework/scorecard-test:dev
+  log: pod timed out
+  state: error
+  ...
+not ok 3 - olm-crds-have-validation-test
+  ---
+  entrypoint:
+  - scorecard-test
+  - olm-crds-have-validation
+  errors:
+  - spec.size has no validation
+  - status has no validation
+  image: quay.io/operator-framework/scorecard-test:dev
+  state: fail
+  suggestions:
+  - Add validation to spec.size
+  ...
+`, string(out))
+}
+
+func TestMarshalTAPFlaky(t *testing.T) {
+	o, list := newReportScorecard()
+	list.Items[0].Spec.Labels[FlakyLabel] = "true"
+	out, err := o.MarshalTAP(list)
+	require.NoError(t, err)
+	assert.Contains(t, string(out), "ok 1 - basic-check-spec\n# basic-check-spec is flaky: it passed only after a retry\n# stage-2\n")
+}
+
+// newSkippedReportList returns the output of the report scorecard where the
+// tests of its second stage were skipped.
+func newSkippedReportList(list v1alpha3.TestList) v1alpha3.TestList {
+	for i := range list.Items[1:] {
+		list.Items[i+1].Status.Results = []v1alpha3.TestResult{{
+			State: SkippedState,
+			Log:   `skipped because stage "stage-1", which it depends on, did not pass`,
+		}}
+	}
+	return list
+}
+
+func TestMarshalJUnitSkipped(t *testing.T) {
+	o, list := newReportScorecard()
+	out, err := o.MarshalJUnit(newSkippedReportList(list))
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `  <testsuite name="stage-2" tests="2" failures="0" errors="0" skipped="2">
+    <testcase name="olm-bundle-validation-test" classname="olm">
+      <skipped message="skipped because stage &#34;stage-1&#34;, which it depends on, did not pass"></skipped>
+    </testcase>
+`)
+}
+
+func TestMarshalTAPSkipped(t *testing.T) {
+	o, list := newReportScorecard()
+	out, err := o.MarshalTAP(newSkippedReportList(list))
+	require.NoError(t, err)
+	assert.Contains(t, string(out), `# stage-2
+ok 2 - olm-bundle-validation-test # SKIP skipped because stage "stage-1", which it depends on, did not pass
+ok 3 - olm-crds-have-validation-test # SKIP skipped because stage "stage-1", which it depends on, did not pass
+`)
+}
+
+func TestMarshalText(t *testing.T) {
+	_, list := newReportScorecard()
+	test := list.Items[1]
+	test.Status.Results = []v1alpha3.TestResult{
+		{Name: "other", State: "other"},
+		{Name: "skipped", State: SkippedState, Log: "skipped because of a dependency"},
+		{Name: "passed", State: v1alpha3.PassState},
+	}
+	out := MarshalText(test)
+	assert.Contains(t, out, "\tName: other\n\tState: unknown\n\n\tName: skipped\n\tState: skipped\n\n"+
+		"\tLog:\n\t\tskipped because of a dependency\n\n\tName: passed\n\tState: pass\n\n")
+
+	// Labels are printed in random order.
+	list.Items[0].Spec.Labels = nil
+	assert.Equal(t, list.Items[0].MarshalText(), MarshalText(list.Items[0]))
+}
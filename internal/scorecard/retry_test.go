@@ -0,0 +1,165 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// scriptedTestRunner returns the states scripted for a test, by its "test" label,
+// one per attempt. Tests without a scripted state pass, and tests labelled
+// "slow" block until their context is done.
+type scriptedTestRunner struct {
+	mu       sync.Mutex
+	states   map[string][]v1alpha3.State
+	attempts map[string]int
+}
+
+func (r *scriptedTestRunner) Initialize(context.Context) error { return nil }
+func (r *scriptedTestRunner) Cleanup(context.Context) error    { return nil }
+
+func (r *scriptedTestRunner) RunTest(ctx context.Context, test TestConfiguration) (*v1alpha3.TestStatus, error) {
+	name := test.Labels["test"]
+	r.mu.Lock()
+	attempt := r.attempts[name]
+	r.attempts[name]++
+	r.mu.Unlock()
+
+	if test.Labels["slow"] == "true" {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	state := v1alpha3.PassState
+	if states := r.states[name]; attempt < len(states) {
+		state = states[attempt]
+	}
+	result := v1alpha3.TestResult{Name: name, State: state, Log: "test log\n"}
+	if state != v1alpha3.PassState {
+		result.Errors = []string{"attempt failed"}
+	}
+	return &v1alpha3.TestStatus{Results: []v1alpha3.TestResult{result}}, nil
+}
+
+func newScriptedTest(name string, retries int) TestConfiguration {
+	return TestConfiguration{
+		TestConfiguration: v1alpha3.TestConfiguration{Image: "test-image", Labels: map[string]string{"test": name}},
+		Retries:           retries,
+	}
+}
+
+func runScripted(t *testing.T, cfg Configuration, states map[string][]v1alpha3.State) (v1alpha3.TestList, *scriptedTestRunner) {
+	runner := &scriptedTestRunner{states: states, attempts: map[string]int{}}
+	o := Scorecard{Config: cfg, Selector: labels.Everything(), TestRunner: runner, SkipCleanup: true}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	list, err := o.Run(ctx)
+	require.NoError(t, err)
+	return list, runner
+}
+
+func TestRunRetries(t *testing.T) {
+	fail, pass := v1alpha3.FailState, v1alpha3.PassState
+	cfg := Configuration{Stages: []StageConfiguration{{Tests: []TestConfiguration{
+		newScriptedTest("flaky", 2),
+		newScriptedTest("failing", 2),
+		newScriptedTest("stable", 2),
+	}}}}
+	list, runner := runScripted(t, cfg, map[string][]v1alpha3.State{
+		"flaky":   {fail, pass},
+		"failing": {fail, fail, fail, pass},
+	})
+	require.Len(t, list.Items, 3)
+
+	flaky := list.Items[0]
+	assert.Equal(t, 2, runner.attempts["flaky"])
+	assert.Equal(t, pass, flaky.Status.Results[0].State)
+	assert.Equal(t, "true", flaky.Spec.Labels[FlakyLabel])
+	assert.Equal(t, "Flaky: passed on attempt 2 of 3 after failing with:\n  attempt 1: attempt failed\ntest log\n",
+		flaky.Status.Results[0].Log)
+	_, labelled := cfg.Stages[0].Tests[0].Labels[FlakyLabel]
+	assert.False(t, labelled, "the configured labels must not be changed")
+
+	failing := list.Items[1]
+	assert.Equal(t, 3, runner.attempts["failing"])
+	assert.Equal(t, fail, failing.Status.Results[0].State)
+	assert.NotContains(t, failing.Spec.Labels, FlakyLabel)
+
+	stable := list.Items[2]
+	assert.Equal(t, 1, runner.attempts["stable"])
+	assert.NotContains(t, stable.Spec.Labels, FlakyLabel)
+	assert.Equal(t, "test log\n", stable.Status.Results[0].Log)
+}
+
+func TestRunTimeout(t *testing.T) {
+	slow := newScriptedTest("slow", 1)
+	slow.Labels["slow"] = "true"
+	slow.Timeout = &metav1.Duration{Duration: 10 * time.Millisecond}
+	cfg := Configuration{Stages: []StageConfiguration{{Tests: []TestConfiguration{
+		slow,
+		newScriptedTest("after", 0),
+	}}}}
+	list, runner := runScripted(t, cfg, nil)
+	require.Len(t, list.Items, 2)
+
+	assert.Equal(t, 2, runner.attempts["slow"])
+	result := list.Items[0].Status.Results[0]
+	assert.Equal(t, v1alpha3.FailState, result.State)
+	require.Len(t, result.Errors, 1)
+	assert.True(t, strings.HasPrefix(result.Errors[0], "test timed out after 10ms: "), result.Errors[0])
+
+	expectPass(t, list.Items[1])
+}
+
+func TestRunFailFast(t *testing.T) {
+	newConfig := func(failFast bool) Configuration {
+		return Configuration{Stages: []StageConfiguration{
+			{FailFast: failFast, Tests: []TestConfiguration{newScriptedTest("install", 0)}},
+			{Tests: []TestConfiguration{newScriptedTest("upgrade", 0), newScriptedTest("uninstall", 0)}},
+		}}
+	}
+	states := map[string][]v1alpha3.State{"install": {v1alpha3.FailState}}
+
+	t.Run("skips later stages", func(t *testing.T) {
+		list, runner := runScripted(t, newConfig(true), states)
+		require.Len(t, list.Items, 3)
+		assert.Equal(t, v1alpha3.FailState, list.Items[0].Status.Results[0].State)
+		for _, test := range list.Items[1:] {
+			assert.Zero(t, runner.attempts[test.Spec.Labels["test"]])
+			assert.Equal(t, []v1alpha3.TestResult{{
+				State: SkippedState,
+				Log:   `skipped because test "install" of fail-fast stage "stage-1" did not pass`,
+			}}, test.Status.Results)
+		}
+	})
+
+	t.Run("runs later stages without fail fast", func(t *testing.T) {
+		list, runner := runScripted(t, newConfig(false), states)
+		require.Len(t, list.Items, 3)
+		for _, test := range list.Items[1:] {
+			assert.Equal(t, 1, runner.attempts[test.Spec.Labels["test"]])
+			expectPass(t, test)
+		}
+	})
+}
@@ -155,11 +155,11 @@ func TestRunSequentialFail(t *testing.T) {
 
 func getFakeScorecard(parallel bool) Scorecard {
 	return Scorecard{
-		Config: v1alpha3.Configuration{
-			Stages: []v1alpha3.StageConfiguration{
+		Config: Configuration{
+			Stages: []StageConfiguration{
 				{
 					Parallel: parallel,
-					Tests: []v1alpha3.TestConfiguration{
+					Tests: []TestConfiguration{
 						{},
 						{},
 					},
@@ -17,6 +17,7 @@ package scorecard
 import (
 	"context"
 	"fmt"
+	"strings"
 	"sync"
 	"time"
 
@@ -32,15 +33,24 @@ import (
 
 type TestRunner interface {
 	Initialize(context.Context) error
-	RunTest(context.Context, v1alpha3.TestConfiguration) (*v1alpha3.TestStatus, error)
+	RunTest(context.Context, TestConfiguration) (*v1alpha3.TestStatus, error)
 	Cleanup(context.Context) error
 }
 
+// runArtifactsCollector is implemented by test runners that save artifacts of
+// the run as a whole, which must be saved before the run is cleaned up.
+type runArtifactsCollector interface {
+	collectRunArtifacts(context.Context)
+}
+
 type Scorecard struct {
-	Config      v1alpha3.Configuration
+	Config      Configuration
 	Selector    labels.Selector
 	TestRunner  TestRunner
 	SkipCleanup bool
+	// Parallelism, if positive, limits how many tests of any parallel stage
+	// run at once. A stage's MaxParallel can limit it further.
+	Parallelism int
 }
 
 type PodTestRunner struct {
@@ -49,8 +59,15 @@ type PodTestRunner struct {
 	BundlePath     string
 	BundleMetadata registryutil.Labels
 	Client         kubernetes.Interface
+	// ArtifactsDir, if set, is the directory the logs, status and events of
+	// test pods are saved to before they are cleaned up.
+	ArtifactsDir string
 
 	configMapName string
+	// bundleParts are the names of the ConfigMaps holding the parts of a
+	// bundle too large for a single ConfigMap, in order.
+	bundleParts []string
+	artifacts   *artifactsCollector
 }
 
 type FakeTestRunner struct {
@@ -59,6 +76,16 @@ type FakeTestRunner struct {
 	Error      error
 }
 
+// FlakyLabel is set to "true" on the output of tests that passed only after a retry.
+const FlakyLabel = "scorecard.operatorframework.io/flaky"
+
+// StageLabel is set to the name of the stage on the output of every test.
+const StageLabel = "scorecard.operatorframework.io/stage"
+
+// SkippedState is the state of the result of a test that was not run, whose
+// log gives the reason. It extends the states of v1alpha3.TestResult.
+const SkippedState v1alpha3.State = "skipped"
+
 // cleanupTimeout is the time given to clean up resources, regardless of how long ctx's deadline is.
 var cleanupTimeout = time.Second * 30
 
@@ -70,21 +97,52 @@ func (o Scorecard) Run(ctx context.Context) (testOutput v1alpha3.TestList, err e
 		return testOutput, err
 	}
 
-	for _, stage := range o.Config.Stages {
+	// failFastReason is set once a fail-fast stage has a test that did not pass.
+	var failFastReason string
+	// notPassed maps the names of stages that had a test that did not pass, or
+	// were skipped, to what happened to them.
+	notPassed := make(map[string]string)
+	for i, stage := range o.Config.Stages {
+		name := o.Config.stageName(i)
 		tests := o.selectTests(stage)
+
+		skipReason := failFastReason
+		for _, dep := range stage.DependsOn {
+			if outcome, ok := notPassed[dep]; ok && skipReason == "" {
+				skipReason = fmt.Sprintf("skipped because stage %q, which it depends on, %s", dep, outcome)
+			}
+		}
+		if skipReason != "" {
+			notPassed[name] = "was skipped"
+			for _, test := range tests {
+				out := skippedTest(test, skipReason)
+				setLabel(&out, StageLabel, name)
+				testOutput.Items = append(testOutput.Items, out)
+			}
+			continue
+		}
 		if len(tests) == 0 {
 			continue
 		}
 
 		output := make(chan v1alpha3.Test, len(tests))
 		if stage.Parallel {
-			o.runStageParallel(ctx, tests, output)
+			o.runStageParallel(ctx, tests, o.maxParallel(stage), output)
 		} else {
 			o.runStageSequential(ctx, tests, output)
 		}
 		close(output)
 		for o := range output {
+			setLabel(&o, StageLabel, name)
 			testOutput.Items = append(testOutput.Items, o)
+			if passed(o.Status) {
+				continue
+			}
+			notPassed[name] = "did not pass"
+			if stage.FailFast && failFastReason == "" {
+				failFastReason = fmt.Sprintf("skipped because test %q of fail-fast stage %q did not pass",
+					testName(o, v1alpha3.TestResult{}), name)
+			}
 		}
 	}
 
@@ -95,10 +153,15 @@ func (o Scorecard) Run(ctx context.Context) (testOutput v1alpha3.TestList, err e
 	default:
 	}
 
+	// Use a separate context for artifacts and cleanup, which need to run regardless of a prior timeout.
+	clctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+	defer cancel()
+	// Run artifacts are saved whether or not the run is cleaned up.
+	if c, ok := o.TestRunner.(runArtifactsCollector); ok {
+		c.collectRunArtifacts(clctx)
+	}
+
 	if !o.SkipCleanup {
-		// Use a separate context for cleanup, which needs to run regardless of a prior timeout.
-		clctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
-		defer cancel()
 		if err := o.TestRunner.Cleanup(clctx); err != nil {
 			return testOutput, err
 		}
@@ -107,43 +170,168 @@ func (o Scorecard) Run(ctx context.Context) (testOutput v1alpha3.TestList, err e
 	return testOutput, err
 }
 
-func (o Scorecard) runStageParallel(ctx context.Context, tests []v1alpha3.TestConfiguration, results chan<- v1alpha3.Test) {
+// maxParallel returns how many tests of stage may run at once, or 0 if there is no limit.
+func (o Scorecard) maxParallel(stage StageConfiguration) int {
+	if stage.MaxParallel > 0 && (o.Parallelism <= 0 || stage.MaxParallel < o.Parallelism) {
+		return stage.MaxParallel
+	}
+	if o.Parallelism > 0 {
+		return o.Parallelism
+	}
+	return 0
+}
+
+// runStageParallel runs tests in parallel, at most maxParallel at once if positive.
+func (o Scorecard) runStageParallel(ctx context.Context, tests []TestConfiguration, maxParallel int,
+	results chan<- v1alpha3.Test) {
+
+	if maxParallel <= 0 || maxParallel > len(tests) {
+		maxParallel = len(tests)
+	}
+	running := make(chan struct{}, maxParallel)
 	var wg sync.WaitGroup
 	for _, t := range tests {
 		wg.Add(1)
-		go func(test v1alpha3.TestConfiguration) {
+		running <- struct{}{}
+		go func(test TestConfiguration) {
 			results <- o.runTest(ctx, test)
+			<-running
 			wg.Done()
 		}(t)
 	}
 	wg.Wait()
 }
 
-func (o Scorecard) runStageSequential(ctx context.Context, tests []v1alpha3.TestConfiguration, results chan<- v1alpha3.Test) {
+func (o Scorecard) runStageSequential(ctx context.Context, tests []TestConfiguration, results chan<- v1alpha3.Test) {
 	for _, test := range tests {
 		results <- o.runTest(ctx, test)
 	}
 }
 
-func (o Scorecard) runTest(ctx context.Context, test v1alpha3.TestConfiguration) v1alpha3.Test {
-	result, err := o.TestRunner.RunTest(ctx, test)
+// runTest runs test until it passes or has been retried as many times as
+// configured. A test that only passes after a retry is labelled as flaky.
+func (o Scorecard) runTest(ctx context.Context, test TestConfiguration) v1alpha3.Test {
+	var result *v1alpha3.TestStatus
+	var failures []string
+	attempts := test.Retries + 1
+	attempt := 1
+	for ; ; attempt++ {
+		result = o.runTestAttempt(ctx, test)
+		if passed(*result) || attempt == attempts || ctx.Err() != nil {
+			break
+		}
+		failures = append(failures, fmt.Sprintf("attempt %d: %s", attempt, failureSummary(*result)))
+	}
+
+	out := v1alpha3.NewTest()
+	out.Spec = test.TestConfiguration
+	out.Status = *result
+	if len(failures) > 0 && passed(*result) {
+		markFlaky(&out, attempt, attempts, failures)
+	}
+	return out
+}
+
+// runTestAttempt runs test once, within its timeout if it has one.
+func (o Scorecard) runTestAttempt(ctx context.Context, test TestConfiguration) *v1alpha3.TestStatus {
+	testCtx := ctx
+	if test.Timeout != nil {
+		var cancel context.CancelFunc
+		testCtx, cancel = context.WithTimeout(ctx, test.Timeout.Duration)
+		defer cancel()
+	}
+
+	result, err := o.TestRunner.RunTest(testCtx, test)
 	if err != nil {
+		if testCtx.Err() != nil && ctx.Err() == nil {
+			err = fmt.Errorf("test timed out after %s: %w", test.Timeout.Duration, err)
+		}
 		result = convertErrorToStatus(err, "")
 	}
+	return result
+}
+
+// skipped returns true if all results of status were skipped.
+func skipped(status v1alpha3.TestStatus) bool {
+	for _, result := range status.Results {
+		if result.State != SkippedState {
+			return false
+		}
+	}
+	return len(status.Results) > 0
+}
+
+// passed returns true if all results of status passed.
+func passed(status v1alpha3.TestStatus) bool {
+	for _, result := range status.Results {
+		if result.State != v1alpha3.PassState {
+			return false
+		}
+	}
+	return true
+}
+
+// failureSummary returns the errors of the results of status that did not pass.
+func failureSummary(status v1alpha3.TestStatus) string {
+	var errs []string
+	for _, result := range status.Results {
+		if result.State == v1alpha3.PassState {
+			continue
+		}
+		if len(result.Errors) == 0 {
+			errs = append(errs, fmt.Sprintf("test finished in state %q", result.State))
+		}
+		errs = append(errs, result.Errors...)
+	}
+	return strings.Join(errs, "; ")
+}
+
+// markFlaky labels test, which passed on attempt after failing as described
+// by failures, as flaky, and prepends the failures to the logs of its results.
+func markFlaky(test *v1alpha3.Test, attempt, attempts int, failures []string) {
+	setLabel(test, FlakyLabel, "true")
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Flaky: passed on attempt %d of %d after failing with:\n", attempt, attempts)
+	for _, failure := range failures {
+		fmt.Fprintf(&sb, "  %s\n", failure)
+	}
+	for i := range test.Status.Results {
+		test.Status.Results[i].Log = sb.String() + test.Status.Results[i].Log
+	}
+}
+
+// setLabel sets a label on the output of a test. The labels are copied, since
+// they are shared with the test's configuration.
+func setLabel(test *v1alpha3.Test, key, value string) {
+	testLabels := make(map[string]string, len(test.Spec.Labels)+1)
+	for k, v := range test.Spec.Labels {
+		testLabels[k] = v
+	}
+	testLabels[key] = value
+	test.Spec.Labels = testLabels
+}
 
+// skippedTest returns the output of test when it is skipped for reason.
+func skippedTest(test TestConfiguration, reason string) v1alpha3.Test {
 	out := v1alpha3.NewTest()
-	out.Spec = test
-	out.Status = *result
+	out.Spec = test.TestConfiguration
+	out.Status = v1alpha3.TestStatus{Results: []v1alpha3.TestResult{{
+		State: SkippedState,
+		Log:   reason,
+	}}}
 	return out
 }
 
 // selectTests applies an optionally passed selector expression
 // against the configured set of tests, returning the selected tests
-func (o *Scorecard) selectTests(stage v1alpha3.StageConfiguration) []v1alpha3.TestConfiguration {
-	selected := make([]v1alpha3.TestConfiguration, 0)
+// with the stage's pod settings merged into their own.
+func (o *Scorecard) selectTests(stage StageConfiguration) []TestConfiguration {
+	selected := make([]TestConfiguration, 0)
 	for _, test := range stage.Tests {
 		if o.Selector == nil || o.Selector.String() == "" || o.Selector.Matches(labels.Set(test.Labels)) {
 			// TODO olm manifests check
+			test.Pod = stage.Pod.merge(test.Pod)
 			selected = append(selected, test)
 		}
 	}
@@ -161,15 +349,27 @@ func (r FakeTestRunner) Initialize(ctx context.Context) error {
 
 // Initialize sets up the bundle configmap for tests
 func (r *PodTestRunner) Initialize(ctx context.Context) error {
+	if r.ArtifactsDir != "" {
+		var err error
+		if r.artifacts, err = newArtifactsCollector(r.ArtifactsDir, r.Namespace); err != nil {
+			return err
+		}
+	}
+
 	bundleData, err := r.getBundleData()
 	if err != nil {
 		return fmt.Errorf("error getting bundle data %w", err)
 	}
 
-	r.configMapName, err = r.CreateConfigMap(ctx, bundleData)
+	r.configMapName, r.bundleParts, err = r.createBundleConfigMaps(ctx, bundleData)
 	if err != nil {
 		return fmt.Errorf("error creating ConfigMap %w", err)
 	}
+	if r.artifacts != nil {
+		r.artifacts.update(func(index *artifactsIndex) {
+			index.TestRun = r.configMapName
+		})
+	}
 	return nil
 
 }
@@ -189,15 +389,19 @@ func (r PodTestRunner) Cleanup(ctx context.Context) (err error) {
 	if err != nil {
 		return err
 	}
-	err = r.deleteConfigMap(ctx, r.configMapName)
-	if err != nil {
-		return err
+	if len(r.bundleParts) == 0 {
+		return r.deleteConfigMap(ctx, r.configMapName)
+	}
+	for _, part := range r.bundleParts {
+		if err := r.deleteConfigMap(ctx, part); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
 // RunTest executes a single test
-func (r PodTestRunner) RunTest(ctx context.Context, test v1alpha3.TestConfiguration) (*v1alpha3.TestStatus, error) {
+func (r PodTestRunner) RunTest(ctx context.Context, test TestConfiguration) (*v1alpha3.TestStatus, error) {
 	// Create a Pod to run the test
 	podDef := getPodDefinition(r.configMapName, test, r)
 	pod, err := r.Client.CoreV1().Pods(r.Namespace).Create(ctx, podDef, metav1.CreateOptions{})
@@ -207,14 +411,22 @@ func (r PodTestRunner) RunTest(ctx context.Context, test v1alpha3.TestConfigurat
 
 	err = r.waitForTestToComplete(ctx, pod)
 	if err != nil {
+		r.collectTestArtifacts(test, pod, nil)
+		if ctx.Err() != nil {
+			// Otherwise the pod of a test that timed out keeps running until
+			// cleanup, next to the pods of its retries and of other tests.
+			r.deletePod(pod)
+		}
 		return nil, err
 	}
 
-	return r.getTestStatus(ctx, pod), nil
+	status := r.getTestStatus(ctx, pod)
+	r.collectTestArtifacts(test, pod, status)
+	return status, nil
 }
 
 // RunTest executes a single test
-func (r FakeTestRunner) RunTest(ctx context.Context, test v1alpha3.TestConfiguration) (result *v1alpha3.TestStatus, err error) {
+func (r FakeTestRunner) RunTest(ctx context.Context, test TestConfiguration) (result *v1alpha3.TestStatus, err error) {
 	select {
 	case <-time.After(r.Sleep):
 		return r.TestStatus, r.Error
@@ -0,0 +1,130 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestRunDependsOn(t *testing.T) {
+	cfg := Configuration{Stages: []StageConfiguration{
+		{Name: "install", Tests: []TestConfiguration{newScriptedTest("install", 0)}},
+		{Name: "lint", Tests: []TestConfiguration{newScriptedTest("lint", 0)}},
+		{Name: "upgrade", DependsOn: []string{"install"}, Tests: []TestConfiguration{newScriptedTest("upgrade", 0)}},
+		{Name: "uninstall", DependsOn: []string{"upgrade"}, Tests: []TestConfiguration{newScriptedTest("uninstall", 0)}},
+		{DependsOn: []string{"lint"}, Tests: []TestConfiguration{newScriptedTest("docs", 0)}},
+	}}
+	list, runner := runScripted(t, cfg, map[string][]v1alpha3.State{"install": {v1alpha3.FailState}})
+	require.Len(t, list.Items, 5)
+
+	assert.Equal(t, v1alpha3.FailState, list.Items[0].Status.Results[0].State)
+	expectPass(t, list.Items[1])
+	assert.Equal(t, []v1alpha3.TestResult{{
+		State: SkippedState,
+		Log:   `skipped because stage "install", which it depends on, did not pass`,
+	}}, list.Items[2].Status.Results)
+	assert.Equal(t, []v1alpha3.TestResult{{
+		State: SkippedState,
+		Log:   `skipped because stage "upgrade", which it depends on, was skipped`,
+	}}, list.Items[3].Status.Results)
+	assert.Zero(t, runner.attempts["upgrade"])
+	assert.Zero(t, runner.attempts["uninstall"])
+	expectPass(t, list.Items[4])
+
+	stages := Scorecard{Config: cfg, Selector: labels.Everything()}.Stages(list)
+	require.Len(t, stages, 5)
+	assert.Equal(t, "upgrade", stages[2].Name)
+	assert.Equal(t, "stage-5", stages[4].Name)
+	assert.Equal(t, "upgrade", list.Items[2].Spec.Labels[StageLabel])
+	// The stages of the output do not depend on the configuration.
+	assert.Equal(t, stages, Scorecard{}.Stages(list))
+}
+
+// concurrencyTestRunner records how many tests run at once.
+type concurrencyTestRunner struct {
+	mu      sync.Mutex
+	running int
+	max     int
+}
+
+func (r *concurrencyTestRunner) Initialize(context.Context) error { return nil }
+func (r *concurrencyTestRunner) Cleanup(context.Context) error    { return nil }
+
+func (r *concurrencyTestRunner) RunTest(ctx context.Context, test TestConfiguration) (*v1alpha3.TestStatus, error) {
+	r.mu.Lock()
+	r.running++
+	if r.running > r.max {
+		r.max = r.running
+	}
+	r.mu.Unlock()
+
+	time.Sleep(10 * time.Millisecond)
+
+	r.mu.Lock()
+	r.running--
+	r.mu.Unlock()
+	return &v1alpha3.TestStatus{Results: []v1alpha3.TestResult{{State: v1alpha3.PassState}}}, nil
+}
+
+func TestRunMaxParallel(t *testing.T) {
+	var tests []TestConfiguration
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		tests = append(tests, newScriptedTest(name, 0))
+	}
+
+	cases := []struct {
+		name        string
+		parallelism int
+		maxParallel int
+		want        int
+	}{
+		{"unlimited", 0, 0, 6},
+		{"parallelism", 2, 0, 2},
+		{"maxParallel", 0, 3, 3},
+		{"lower maxParallel", 3, 1, 1},
+		{"lower parallelism", 2, 4, 2},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			runner := &concurrencyTestRunner{}
+			o := Scorecard{
+				Config: Configuration{Stages: []StageConfiguration{
+					{Parallel: true, MaxParallel: c.maxParallel, Tests: tests},
+				}},
+				Selector:    labels.Everything(),
+				TestRunner:  runner,
+				SkipCleanup: true,
+				Parallelism: c.parallelism,
+			}
+			list, err := o.Run(context.Background())
+			require.NoError(t, err)
+			assert.Len(t, list.Items, len(tests))
+			if c.want == len(tests) {
+				// All tests may start at once, but need not overlap.
+				assert.LessOrEqual(t, runner.max, c.want)
+			} else {
+				assert.Equal(t, c.want, runner.max)
+			}
+		})
+	}
+}
@@ -18,11 +18,21 @@ import (
 	"context"
 	"fmt"
 
+	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 )
 
+const (
+	// bundleKey is the key of the gzipped bundle tarball in the bundle ConfigMap.
+	bundleKey = "bundle.tar.gz"
+	// maxConfigMapBundleSize is the size of the largest bundle, or part of a
+	// bundle, held by one ConfigMap. The API server rejects ConfigMaps with
+	// more than 1 MiB of data; the rest of the object needs some room too.
+	maxConfigMapBundleSize = 1000 * 1024
+)
+
 // CreateConfigMap creates a ConfigMap that will hold the bundle
 // contents to be mounted into the test Pods
 func (r PodTestRunner) CreateConfigMap(ctx context.Context, bundleData []byte) (configMapName string, err error) {
@@ -34,16 +44,65 @@ func (r PodTestRunner) CreateConfigMap(ctx context.Context, bundleData []byte) (
 	return configMap.Name, nil
 }
 
+// createBundleConfigMaps creates the ConfigMaps that hold the bundle contents.
+// A bundle of up to maxConfigMapBundleSize bytes is held by a single ConfigMap
+// created by CreateConfigMap. A larger bundle is split into parts, each held by
+// its own ConfigMap, that the untar init container of test Pods reassembles.
+// It returns the name of the first ConfigMap, which identifies the test run,
+// and the names of the ConfigMaps of the parts, if the bundle was split.
+func (r PodTestRunner) createBundleConfigMaps(ctx context.Context, bundleData []byte) (configMapName string, parts []string, err error) {
+	if len(bundleData) <= maxConfigMapBundleSize {
+		configMapName, err = r.CreateConfigMap(ctx, bundleData)
+		return configMapName, nil, err
+	}
+
+	configMapName = fmt.Sprintf("scorecard-test-%s", rand.String(4))
+	for i := 0; len(bundleData) > 0; i++ {
+		n := maxConfigMapBundleSize
+		if n > len(bundleData) {
+			n = len(bundleData)
+		}
+		name := configMapName
+		if i > 0 {
+			name = fmt.Sprintf("%s-%d", configMapName, i)
+		}
+		data := map[string][]byte{bundlePartKey(i): bundleData[:n]}
+		cfg := newBundleConfigMap(r.Namespace, name, data)
+		if _, err := r.Client.CoreV1().ConfigMaps(r.Namespace).Create(ctx, cfg, metav1.CreateOptions{}); err != nil {
+			// Nothing cleans up after a failed Initialize, so remove the parts created so far.
+			for _, part := range parts {
+				if err := r.deleteConfigMap(ctx, part); err != nil {
+					log.Error(err)
+				}
+			}
+			return "", nil, fmt.Errorf("error creating part %d of the bundle: %w", i, err)
+		}
+		parts = append(parts, name)
+		bundleData = bundleData[n:]
+	}
+	return configMapName, parts, nil
+}
+
+// bundlePartKey returns the key of part i of a split bundle. Keys are
+// zero-padded so that the parts sort in order in the untar init container.
+func bundlePartKey(i int) string {
+	return fmt.Sprintf("%s.%03d", bundleKey, i)
+}
+
 // getConfigMapDefinition returns a ConfigMap definition that
 // will hold the bundle contents and eventually will be mounted
 // into each test Pod
 func getConfigMapDefinition(namespace string, bundleData []byte) *v1.ConfigMap {
 	configMapName := fmt.Sprintf("scorecard-test-%s", rand.String(4))
 	data := make(map[string][]byte)
-	data["bundle.tar.gz"] = bundleData
+	data[bundleKey] = bundleData
+	return newBundleConfigMap(namespace, configMapName, data)
+}
+
+func newBundleConfigMap(namespace, name string, data map[string][]byte) *v1.ConfigMap {
 	return &v1.ConfigMap{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      configMapName,
+			Name:      name,
 			Namespace: namespace,
 			Labels: map[string]string{
 				"app": "scorecard-test",
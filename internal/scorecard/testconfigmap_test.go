@@ -0,0 +1,103 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+func TestCreateBundleConfigMaps(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("small bundle", func(t *testing.T) {
+		r := PodTestRunner{Namespace: "scorecard", Client: fake.NewSimpleClientset()}
+		name, parts, err := r.createBundleConfigMaps(ctx, []byte("bundle"))
+		require.NoError(t, err)
+		assert.Nil(t, parts)
+
+		cm, err := r.Client.CoreV1().ConfigMaps("scorecard").Get(ctx, name, metav1.GetOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, []byte("bundle"), cm.BinaryData[bundleKey])
+	})
+
+	t.Run("large bundle", func(t *testing.T) {
+		r := PodTestRunner{Namespace: "scorecard", Client: fake.NewSimpleClientset()}
+		bundleData := make([]byte, 2*maxConfigMapBundleSize+1)
+		rand.Read(bundleData)
+
+		name, parts, err := r.createBundleConfigMaps(ctx, bundleData)
+		require.NoError(t, err)
+		assert.Equal(t, []string{name, name + "-1", name + "-2"}, parts)
+
+		// Reassemble the parts the way the untar init container does.
+		data := map[string][]byte{}
+		for _, part := range parts {
+			cm, err := r.Client.CoreV1().ConfigMaps("scorecard").Get(ctx, part, metav1.GetOptions{})
+			require.NoError(t, err)
+			assert.Len(t, cm.BinaryData, 1)
+			for key, value := range cm.BinaryData {
+				assert.LessOrEqual(t, len(value), maxConfigMapBundleSize)
+				data[key] = value
+			}
+		}
+		var keys []string
+		for key := range data {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		assert.Equal(t, []string{"bundle.tar.gz.000", "bundle.tar.gz.001", "bundle.tar.gz.002"}, keys)
+		var reassembled bytes.Buffer
+		for _, key := range keys {
+			reassembled.Write(data[key])
+		}
+		assert.Equal(t, bundleData, reassembled.Bytes())
+
+		r.configMapName, r.bundleParts = name, parts
+		require.NoError(t, r.Cleanup(ctx))
+		cms, err := r.Client.CoreV1().ConfigMaps("scorecard").List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, cms.Items)
+	})
+
+	t.Run("failed part", func(t *testing.T) {
+		client := fake.NewSimpleClientset()
+		creates := 0
+		client.PrependReactor("create", "configmaps", func(clienttesting.Action) (bool, runtime.Object, error) {
+			if creates++; creates == 2 {
+				return true, nil, errors.New("etcdserver: request is too large")
+			}
+			return false, nil, nil
+		})
+		r := PodTestRunner{Namespace: "scorecard", Client: client}
+
+		_, _, err := r.createBundleConfigMaps(ctx, make([]byte, maxConfigMapBundleSize+1))
+		assert.EqualError(t, err, "error creating part 1 of the bundle: etcdserver: request is too large")
+		cms, err := r.Client.CoreV1().ConfigMaps("scorecard").List(ctx, metav1.ListOptions{})
+		require.NoError(t, err)
+		assert.Empty(t, cms.Items)
+	})
+}
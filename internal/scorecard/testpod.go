@@ -19,9 +19,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
-	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	log "github.com/sirupsen/logrus"
 	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/kubernetes"
@@ -30,11 +32,41 @@ import (
 const (
 	// PodBundleRoot is the directory containing all bundle data within a test pod.
 	PodBundleRoot = "/bundle"
+
+	// bundleVolumeName is the name of the volume of the bundle ConfigMap.
+	bundleVolumeName = "scorecard-bundle"
+	// untarVolumeName is the name of the volume the bundle is unpacked to.
+	untarVolumeName = "scorecard-untar"
+	// defaultUntarImage is the image of the init container that unpacks the bundle.
+	defaultUntarImage = "busybox"
+	// deletePodTimeout is the time given to delete the pod of a test that did not complete.
+	deletePodTimeout = 30 * time.Second
 )
 
 // getPodDefinition fills out a Pod definition based on
-// information from the test
-func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r PodTestRunner) *v1.Pod {
+// information from the test and its pod settings
+func getPodDefinition(configMapName string, test TestConfiguration, r PodTestRunner) *v1.Pod {
+	podCfg := test.Pod
+	if podCfg == nil {
+		podCfg = &PodConfiguration{}
+	}
+	serviceAccount := r.ServiceAccount
+	if podCfg.ServiceAccount != "" {
+		serviceAccount = podCfg.ServiceAccount
+	}
+	pullPolicy := v1.PullIfNotPresent
+	if podCfg.ImagePullPolicy != "" {
+		pullPolicy = podCfg.ImagePullPolicy
+	}
+	untarImage := defaultUntarImage
+	if podCfg.UntarImage != "" {
+		untarImage = podCfg.UntarImage
+	}
+	var resources v1.ResourceRequirements
+	if podCfg.Resources != nil {
+		resources = *podCfg.Resources
+	}
+
 	return &v1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      fmt.Sprintf("scorecard-test-%s", rand.String(4)),
@@ -45,22 +77,26 @@ func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r P
 			},
 		},
 		Spec: v1.PodSpec{
-			ServiceAccountName: r.ServiceAccount,
+			ServiceAccountName: serviceAccount,
 			RestartPolicy:      v1.RestartPolicyNever,
+			NodeSelector:       podCfg.NodeSelector,
+			Tolerations:        podCfg.Tolerations,
+			ImagePullSecrets:   podCfg.ImagePullSecrets,
 			Containers: []v1.Container{
 				{
 					Name:            "scorecard-test",
 					Image:           test.Image,
-					ImagePullPolicy: v1.PullIfNotPresent,
+					ImagePullPolicy: pullPolicy,
 					Command:         test.Entrypoint,
-					VolumeMounts: []v1.VolumeMount{
+					Resources:       resources,
+					VolumeMounts: append([]v1.VolumeMount{
 						{
 							MountPath: PodBundleRoot,
-							Name:      "scorecard-untar",
+							Name:      untarVolumeName,
 							ReadOnly:  true,
 						},
-					},
-					Env: []v1.EnvVar{
+					}, podCfg.VolumeMounts...),
+					Env: append([]v1.EnvVar{
 						{
 							Name: "SCORECARD_NAMESPACE",
 							ValueFrom: &v1.EnvVarSource{
@@ -69,60 +105,55 @@ func getPodDefinition(configMapName string, test v1alpha3.TestConfiguration, r P
 								},
 							},
 						},
-					},
+					}, podCfg.Env...),
 				},
 			},
 			InitContainers: []v1.Container{
 				{
 					Name:            "scorecard-untar",
-					Image:           "busybox",
-					ImagePullPolicy: v1.PullIfNotPresent,
-					Args: []string{
-						"tar",
-						"xvzf",
-						"/scorecard/bundle.tar.gz",
-						"-C",
-						"/scorecard-bundle",
-					},
+					Image:           untarImage,
+					ImagePullPolicy: pullPolicy,
+					Resources:       resources,
+					Args:            untarArgs(r),
 					VolumeMounts: []v1.VolumeMount{
 						{
 							MountPath: "/scorecard",
-							Name:      "scorecard-bundle",
+							Name:      bundleVolumeName,
 							ReadOnly:  true,
 						},
 						{
 							MountPath: "/scorecard-bundle",
-							Name:      "scorecard-untar",
+							Name:      untarVolumeName,
 							ReadOnly:  false,
 						},
 					},
 				},
 			},
-			Volumes: []v1.Volume{
+			Volumes: append([]v1.Volume{
 				{
-					Name: "scorecard-bundle",
-					VolumeSource: v1.VolumeSource{
-						ConfigMap: &v1.ConfigMapVolumeSource{
-							LocalObjectReference: v1.LocalObjectReference{
-								Name: configMapName,
-							},
-						},
-					},
+					Name:         bundleVolumeName,
+					VolumeSource: bundleVolumeSource(configMapName, r),
 				},
 				{
-					Name: "scorecard-untar",
+					Name: untarVolumeName,
 					VolumeSource: v1.VolumeSource{
 						EmptyDir: &v1.EmptyDirVolumeSource{},
 					},
 				},
-			},
+			}, podCfg.Volumes...),
 		},
 	}
 }
 
 // getPodLog fetches the test results which are found in the pod log
 func getPodLog(ctx context.Context, client kubernetes.Interface, pod *v1.Pod) ([]byte, error) {
-	req := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{})
+	return getContainerLog(ctx, client, pod, "")
+}
+
+// getContainerLog fetches the log of container in pod, or of the pod's only
+// container if container is empty
+func getContainerLog(ctx context.Context, client kubernetes.Interface, pod *v1.Pod, container string) ([]byte, error) {
+	req := client.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &v1.PodLogOptions{Container: container})
 	podLogs, err := req.Stream(ctx)
 	if err != nil {
 		return nil, err
@@ -134,6 +165,55 @@ func getPodLog(ctx context.Context, client kubernetes.Interface, pod *v1.Pod) ([
 	return buf.Bytes(), err
 }
 
+// untarArgs returns the arguments of the init container that unpacks the
+// bundle of r into the volume mounted at PodBundleRoot by the test container.
+// The parts of a split bundle are concatenated in order before unpacking.
+func untarArgs(r PodTestRunner) []string {
+	if len(r.bundleParts) == 0 {
+		return []string{"tar", "xvzf", "/scorecard/" + bundleKey, "-C", "/scorecard-bundle"}
+	}
+	return []string{"sh", "-c", fmt.Sprintf("cat /scorecard/%s.* | tar xvzf - -C /scorecard-bundle", bundleKey)}
+}
+
+// bundleVolumeSource returns the source of the volume holding the bundle of r,
+// which projects the ConfigMaps of all parts of a split bundle into one directory.
+func bundleVolumeSource(configMapName string, r PodTestRunner) v1.VolumeSource {
+	if len(r.bundleParts) == 0 {
+		return v1.VolumeSource{
+			ConfigMap: &v1.ConfigMapVolumeSource{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: configMapName,
+				},
+			},
+		}
+	}
+	sources := make([]v1.VolumeProjection, len(r.bundleParts))
+	for i, part := range r.bundleParts {
+		sources[i] = v1.VolumeProjection{
+			ConfigMap: &v1.ConfigMapProjection{
+				LocalObjectReference: v1.LocalObjectReference{
+					Name: part,
+				},
+			},
+		}
+	}
+	return v1.VolumeSource{
+		Projected: &v1.ProjectedVolumeSource{Sources: sources},
+	}
+}
+
+// deletePod deletes the pod of a test that did not complete, without waiting
+// for its containers to stop gracefully.
+func (r PodTestRunner) deletePod(pod *v1.Pod) {
+	ctx, cancel := context.WithTimeout(context.Background(), deletePodTimeout)
+	defer cancel()
+	var gracePeriod int64
+	err := r.Client.CoreV1().Pods(pod.Namespace).Delete(ctx, pod.Name, metav1.DeleteOptions{GracePeriodSeconds: &gracePeriod})
+	if err != nil && !apierrors.IsNotFound(err) {
+		log.Errorf("error deleting pod %s: %v", pod.Name, err)
+	}
+}
+
 // deletePods deletes a collection of pods that match a predefined selector value
 func (r PodTestRunner) deletePods(ctx context.Context, configMapName string) error {
 	do := metav1.DeleteOptions{}
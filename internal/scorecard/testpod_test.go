@@ -0,0 +1,109 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"testing"
+
+	"github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestGetPodDefinition(t *testing.T) {
+	r := PodTestRunner{Namespace: "scorecard", ServiceAccount: "default"}
+	test := TestConfiguration{TestConfiguration: v1alpha3.TestConfiguration{
+		Image:      "quay.io/operator-framework/scorecard-test:dev",
+		Entrypoint: []string{"scorecard-test", "basic-check-spec"},
+	}}
+
+	t.Run("defaults", func(t *testing.T) {
+		pod := getPodDefinition("scorecard-test-abcd", test, r)
+		assert.Equal(t, "default", pod.Spec.ServiceAccountName)
+		assert.Nil(t, pod.Spec.NodeSelector)
+		assert.Nil(t, pod.Spec.Tolerations)
+		assert.Nil(t, pod.Spec.ImagePullSecrets)
+		assert.Len(t, pod.Spec.Volumes, 2)
+
+		container, untar := pod.Spec.Containers[0], pod.Spec.InitContainers[0]
+		assert.Equal(t, v1.PullIfNotPresent, container.ImagePullPolicy)
+		assert.Equal(t, v1.ResourceRequirements{}, container.Resources)
+		assert.Len(t, container.Env, 1)
+		assert.Len(t, container.VolumeMounts, 1)
+		assert.Equal(t, "busybox", untar.Image)
+		assert.Equal(t, v1.PullIfNotPresent, untar.ImagePullPolicy)
+		assert.Equal(t, []string{"tar", "xvzf", "/scorecard/bundle.tar.gz", "-C", "/scorecard-bundle"}, untar.Args)
+		assert.Equal(t, "scorecard-test-abcd", pod.Spec.Volumes[0].ConfigMap.Name)
+	})
+
+	t.Run("customised", func(t *testing.T) {
+		resources := v1.ResourceRequirements{
+			Limits: v1.ResourceList{v1.ResourceCPU: resource.MustParse("100m")},
+		}
+		test := test
+		test.Pod = &PodConfiguration{
+			ServiceAccount:   "scorecard",
+			Resources:        &resources,
+			NodeSelector:     map[string]string{"kubernetes.io/os": "linux"},
+			Tolerations:      []v1.Toleration{{Key: "dedicated", Operator: v1.TolerationOpExists}},
+			ImagePullPolicy:  v1.PullAlways,
+			ImagePullSecrets: []v1.LocalObjectReference{{Name: "registry"}},
+			Env:              []v1.EnvVar{{Name: "PROXY", Value: "http://proxy"}},
+			Volumes:          []v1.Volume{{Name: "certs", VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}}}},
+			VolumeMounts:     []v1.VolumeMount{{Name: "certs", MountPath: "/certs"}},
+			UntarImage:       "registry.example.com/busybox",
+		}
+		pod := getPodDefinition("scorecard-test-abcd", test, r)
+		assert.Equal(t, "scorecard", pod.Spec.ServiceAccountName)
+		assert.Equal(t, test.Pod.NodeSelector, pod.Spec.NodeSelector)
+		assert.Equal(t, test.Pod.Tolerations, pod.Spec.Tolerations)
+		assert.Equal(t, test.Pod.ImagePullSecrets, pod.Spec.ImagePullSecrets)
+		assert.Equal(t, []string{bundleVolumeName, untarVolumeName, "certs"}, volumeNames(pod.Spec.Volumes))
+
+		container, untar := pod.Spec.Containers[0], pod.Spec.InitContainers[0]
+		assert.Equal(t, v1.PullAlways, container.ImagePullPolicy)
+		assert.Equal(t, resources, container.Resources)
+		assert.Equal(t, "SCORECARD_NAMESPACE", container.Env[0].Name)
+		assert.Equal(t, test.Pod.Env[0], container.Env[1])
+		assert.Equal(t, test.Pod.VolumeMounts[0], container.VolumeMounts[1])
+		assert.Equal(t, "registry.example.com/busybox", untar.Image)
+		assert.Equal(t, v1.PullAlways, untar.ImagePullPolicy)
+		assert.Equal(t, resources, untar.Resources)
+	})
+
+	t.Run("split bundle", func(t *testing.T) {
+		r := r
+		r.bundleParts = []string{"scorecard-test-abcd", "scorecard-test-abcd-1"}
+		pod := getPodDefinition("scorecard-test-abcd", test, r)
+		assert.Equal(t, "scorecard-test-abcd", pod.Labels["testrun"])
+
+		projected := pod.Spec.Volumes[0].Projected
+		if assert.NotNil(t, projected) {
+			assert.Len(t, projected.Sources, 2)
+			assert.Equal(t, "scorecard-test-abcd", projected.Sources[0].ConfigMap.Name)
+			assert.Equal(t, "scorecard-test-abcd-1", projected.Sources[1].ConfigMap.Name)
+		}
+		assert.Equal(t, []string{"sh", "-c", "cat /scorecard/bundle.tar.gz.* | tar xvzf - -C /scorecard-bundle"},
+			pod.Spec.InitContainers[0].Args)
+	})
+}
+
+func volumeNames(volumes []v1.Volume) (names []string) {
+	for _, volume := range volumes {
+		names = append(names, volume.Name)
+	}
+	return names
+}
@@ -0,0 +1,418 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+)
+
+const (
+	BasicCheckStatusConditionsTest = "basic-check-status-conditions"
+	BasicCheckOwnerReferencesTest  = "basic-check-owner-references"
+	BasicCheckCleanupTest          = "basic-check-cleanup"
+
+	// DefaultCRTimeout is how long tests wait for each CR to reach the state they check by default.
+	DefaultCRTimeout = 2 * time.Minute
+
+	// maxOwnerDepth is how many owners are followed to find whether an object is owned by a CR.
+	maxOwnerDepth = 5
+)
+
+// Cluster is the cluster in which tests deploy the CRs of a bundle's alm-examples
+// annotation. The bundle's operator must already be running and watching Namespace.
+type Cluster struct {
+	Client    dynamic.Interface
+	Discovery discovery.DiscoveryInterface
+	// Namespace is the namespace CRs are created in.
+	Namespace string
+	// Timeout is how long to wait for each CR to reach the state a test checks.
+	Timeout time.Duration
+	// PollInterval is how often the state of a CR is checked.
+	PollInterval time.Duration
+}
+
+// NewCluster returns a Cluster for the API server of cfg.
+func NewCluster(cfg *rest.Config, namespace string, timeout time.Duration) (*Cluster, error) {
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dc, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Cluster{
+		Client:       client,
+		Discovery:    memory.NewMemCacheClient(dc),
+		Namespace:    namespace,
+		Timeout:      timeout,
+		PollInterval: time.Second,
+	}, nil
+}
+
+// StatusConditionsTest verifies that each CR gets status conditions.
+func (c Cluster) StatusConditionsTest(ctx context.Context, bundle *apimanifests.Bundle) scapiv1alpha3.TestStatus {
+	return c.runCRTest(ctx, BasicCheckStatusConditionsTest, bundle, c.checkStatusConditions)
+}
+
+// OwnerReferencesTest verifies that each CR owns an object of every kind of resource
+// listed for its CRD in the CSV, directly or through other owned objects.
+func (c Cluster) OwnerReferencesTest(ctx context.Context, bundle *apimanifests.Bundle) scapiv1alpha3.TestStatus {
+	return c.runCRTest(ctx, BasicCheckOwnerReferencesTest, bundle, c.checkOwnerReferences)
+}
+
+// CleanupTest verifies that each CR, once deleted, has its finalizers removed
+// and the objects it owned deleted.
+func (c Cluster) CleanupTest(ctx context.Context, bundle *apimanifests.Bundle) scapiv1alpha3.TestStatus {
+	return c.runCRTest(ctx, BasicCheckCleanupTest, bundle, c.checkCleanup)
+}
+
+// crCheck checks cr, which was just created, and returns an error describing
+// why it failed. resources are the resources listed for cr's CRD in the CSV.
+type crCheck func(ctx context.Context, cr *unstructured.Unstructured,
+	crClient dynamic.ResourceInterface, resources []operatorsv1alpha1.APIResourceReference) error
+
+// runCRTest creates each CR of bundle, runs check on it and deletes it.
+func (c Cluster) runCRTest(ctx context.Context, name string, bundle *apimanifests.Bundle, check crCheck) scapiv1alpha3.TestStatus {
+	r := scapiv1alpha3.TestResult{
+		Name:        name,
+		State:       scapiv1alpha3.PassState,
+		Errors:      make([]string, 0),
+		Suggestions: make([]string, 0),
+	}
+
+	crs, err := GetCRs(bundle)
+	if err != nil {
+		r.Errors = append(r.Errors, err.Error())
+		r.State = scapiv1alpha3.FailState
+	} else if len(crs) == 0 {
+		r.Suggestions = append(r.Suggestions, "Add example CRs to the CSV's alm-examples annotation to test them")
+	}
+
+	for i := range crs {
+		cr := &crs[i]
+		gvk := cr.GroupVersionKind()
+		if err := c.runCRCheck(ctx, cr, bundle, check); err != nil {
+			r.Errors = append(r.Errors, fmt.Sprintf("%s %q: %v", gvk, cr.GetName(), err))
+			r.State = scapiv1alpha3.FailState
+		}
+	}
+
+	return scapiv1alpha3.TestStatus{
+		Results: []scapiv1alpha3.TestResult{r},
+	}
+}
+
+// runCRCheck creates example under a random name, runs check on it and deletes it.
+func (c Cluster) runCRCheck(ctx context.Context, example *unstructured.Unstructured,
+	bundle *apimanifests.Bundle, check crCheck) error {
+
+	gvk := example.GroupVersionKind()
+	gvrs, err := c.resourcesFor(gvk, false)
+	if err != nil {
+		return err
+	}
+	if len(gvrs) == 0 {
+		return errors.New("the API server does not serve this kind, is its CRD installed?")
+	}
+	crClient := c.Client.Resource(gvrs[0]).Namespace(c.Namespace)
+
+	cr := example.DeepCopy()
+	cr.SetName(fmt.Sprintf("%s-%s", example.GetName(), rand.String(5)))
+	cr.SetNamespace(c.Namespace)
+	unstructured.RemoveNestedField(cr.Object, "status")
+	if cr, err = crClient.Create(ctx, cr, metav1.CreateOptions{}); err != nil {
+		return fmt.Errorf("error creating CR: %v", err)
+	}
+	// Delete the CR even if ctx is done, and without waiting for the delete.
+	defer func() {
+		dctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_ = crClient.Delete(dctx, cr.GetName(), metav1.DeleteOptions{})
+	}()
+
+	return check(ctx, cr, crClient, ownedResources(bundle, gvk))
+}
+
+func (c Cluster) checkStatusConditions(ctx context.Context, cr *unstructured.Unstructured,
+	crClient dynamic.ResourceInterface, _ []operatorsv1alpha1.APIResourceReference) error {
+
+	err := c.poll(ctx, func() (bool, error) {
+		current, err := crClient.Get(ctx, cr.GetName(), metav1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		conditions, _, _ := unstructured.NestedSlice(current.Object, "status", "conditions")
+		return len(conditions) > 0, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("CR %s has no status conditions after %s", cr.GetName(), c.Timeout)
+	}
+	return err
+}
+
+func (c Cluster) checkOwnerReferences(ctx context.Context, cr *unstructured.Unstructured,
+	_ dynamic.ResourceInterface, resources []operatorsv1alpha1.APIResourceReference) error {
+
+	if len(resources) == 0 {
+		return nil
+	}
+	var missing []string
+	err := c.poll(ctx, func() (bool, error) {
+		owned, err := c.ownedObjects(ctx, cr, resources)
+		if err != nil {
+			return false, err
+		}
+		missing = missing[:0]
+		for _, resource := range resources {
+			if len(owned[resource]) == 0 {
+				missing = append(missing, fmt.Sprintf("%s (%s)", resource.Kind, resource.Version))
+			}
+		}
+		return len(missing) == 0, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("no object of kind %s in namespace %s is owned by CR %s after %s",
+			strings.Join(missing, ", "), c.Namespace, cr.GetName(), c.Timeout)
+	}
+	return err
+}
+
+func (c Cluster) checkCleanup(ctx context.Context, cr *unstructured.Unstructured,
+	crClient dynamic.ResourceInterface, resources []operatorsv1alpha1.APIResourceReference) error {
+
+	// Wait for the operator to create the objects the CR owns, which should be cleaned up.
+	// Not finding them all is reported by the owner references test, so only the
+	// objects that are found are checked.
+	var owned map[operatorsv1alpha1.APIResourceReference][]unstructured.Unstructured
+	err := c.poll(ctx, func() (done bool, err error) {
+		if owned, err = c.ownedObjects(ctx, cr, resources); err != nil {
+			return false, err
+		}
+		for _, resource := range resources {
+			if len(owned[resource]) == 0 {
+				return false, nil
+			}
+		}
+		return true, nil
+	})
+	if err != nil && !errors.Is(err, wait.ErrWaitTimeout) {
+		return err
+	}
+
+	if err := crClient.Delete(ctx, cr.GetName(), metav1.DeleteOptions{}); err != nil {
+		return fmt.Errorf("error deleting CR: %v", err)
+	}
+	var finalizers []string
+	err = c.poll(ctx, func() (bool, error) {
+		current, err := crClient.Get(ctx, cr.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		finalizers = current.GetFinalizers()
+		return false, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		return fmt.Errorf("CR %s still exists %s after it was deleted, with finalizers %v",
+			cr.GetName(), c.Timeout, finalizers)
+	}
+	if err != nil {
+		return err
+	}
+
+	var remaining []string
+	err = c.poll(ctx, func() (bool, error) {
+		remaining = remaining[:0]
+		for resource, objs := range owned {
+			gvrs, err := c.resourcesFor(schema.GroupVersionKind{Version: resource.Version, Kind: resource.Kind}, true)
+			if err != nil {
+				return false, err
+			}
+			for _, obj := range objs {
+				exists, err := c.exists(ctx, gvrs, obj)
+				if err != nil {
+					return false, err
+				}
+				if exists {
+					remaining = append(remaining, fmt.Sprintf("%s %s", obj.GetKind(), obj.GetName()))
+				}
+			}
+		}
+		return len(remaining) == 0, nil
+	})
+	if errors.Is(err, wait.ErrWaitTimeout) {
+		sort.Strings(remaining)
+		return fmt.Errorf("objects owned by CR %s still exist %s after it was deleted: %s",
+			cr.GetName(), c.Timeout, strings.Join(remaining, ", "))
+	}
+	return err
+}
+
+// poll calls condition until it is done or c.Timeout passes, in which case
+// wait.ErrWaitTimeout is returned.
+func (c Cluster) poll(ctx context.Context, condition wait.ConditionFunc) error {
+	ctx, cancel := context.WithTimeout(ctx, c.Timeout)
+	defer cancel()
+	return wait.PollImmediateUntil(c.PollInterval, condition, ctx.Done())
+}
+
+// ownedResources returns the resources listed for the owned CRD of kind gvk in bundle's CSV.
+func ownedResources(bundle *apimanifests.Bundle, gvk schema.GroupVersionKind) []operatorsv1alpha1.APIResourceReference {
+	if bundle.CSV == nil {
+		return nil
+	}
+	for _, crd := range bundle.CSV.Spec.CustomResourceDefinitions.Owned {
+		if crd.Kind == gvk.Kind && crd.Version == gvk.Version && strings.HasSuffix(crd.Name, "."+gvk.Group) {
+			return crd.Resources
+		}
+	}
+	return nil
+}
+
+// ownedObjects returns the objects of resources in c.Namespace that cr owns,
+// directly or through the objects that own them.
+func (c Cluster) ownedObjects(ctx context.Context, cr *unstructured.Unstructured,
+	resources []operatorsv1alpha1.APIResourceReference) (map[operatorsv1alpha1.APIResourceReference][]unstructured.Unstructured, error) {
+
+	owners := map[types.UID]bool{cr.GetUID(): true}
+	owned := make(map[operatorsv1alpha1.APIResourceReference][]unstructured.Unstructured)
+	for _, resource := range resources {
+		gvrs, err := c.resourcesFor(schema.GroupVersionKind{Version: resource.Version, Kind: resource.Kind}, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, gvr := range gvrs {
+			list, err := c.Client.Resource(gvr).Namespace(c.Namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, fmt.Errorf("error listing %s: %v", gvr.Resource, err)
+			}
+			for _, obj := range list.Items {
+				isOwned, err := c.isOwned(ctx, obj, cr.GetUID(), owners, maxOwnerDepth)
+				if err != nil {
+					return nil, err
+				}
+				if isOwned {
+					owned[resource] = append(owned[resource], obj)
+				}
+			}
+		}
+	}
+	return owned, nil
+}
+
+// isOwned returns true if obj is owned by the object with UID owner, following
+// up to depth owners of obj. owners caches whether objects are owned by owner.
+func (c Cluster) isOwned(ctx context.Context, obj unstructured.Unstructured, owner types.UID,
+	owners map[types.UID]bool, depth int) (bool, error) {
+
+	if isOwned, cached := owners[obj.GetUID()]; cached {
+		return isOwned, nil
+	}
+	isOwned := false
+	for _, ref := range obj.GetOwnerReferences() {
+		if ref.UID == owner {
+			isOwned = true
+			break
+		}
+		if depth == 0 {
+			continue
+		}
+		gv, err := schema.ParseGroupVersion(ref.APIVersion)
+		if err != nil {
+			continue
+		}
+		gvrs, err := c.resourcesFor(gv.WithKind(ref.Kind), false)
+		if err != nil {
+			return false, err
+		}
+		for _, gvr := range gvrs {
+			refObj, err := c.Client.Resource(gvr).Namespace(c.Namespace).Get(ctx, ref.Name, metav1.GetOptions{})
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			if err != nil {
+				return false, err
+			}
+			if isOwned, err = c.isOwned(ctx, *refObj, owner, owners, depth-1); err != nil || isOwned {
+				return isOwned, err
+			}
+		}
+	}
+	owners[obj.GetUID()] = isOwned
+	return isOwned, nil
+}
+
+// exists returns true if obj, of one of gvrs, exists with the same UID.
+func (c Cluster) exists(ctx context.Context, gvrs []schema.GroupVersionResource, obj unstructured.Unstructured) (bool, error) {
+	for _, gvr := range gvrs {
+		current, err := c.Client.Resource(gvr).Namespace(c.Namespace).Get(ctx, obj.GetName(), metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		if current.GetUID() == obj.GetUID() {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// resourcesFor returns the namespaced resources of kind gvk served by the API server.
+// If anyGroup is true, gvk's group is ignored, since the CSV lists owned resources
+// by kind and version only.
+func (c Cluster) resourcesFor(gvk schema.GroupVersionKind, anyGroup bool) ([]schema.GroupVersionResource, error) {
+	_, lists, err := c.Discovery.ServerGroupsAndResources()
+	if err != nil && !discovery.IsGroupDiscoveryFailedError(err) {
+		return nil, fmt.Errorf("error discovering resources: %v", err)
+	}
+	var gvrs []schema.GroupVersionResource
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil || gv.Version != gvk.Version || (!anyGroup && gv.Group != gvk.Group) {
+			continue
+		}
+		for _, resource := range list.APIResources {
+			if resource.Kind == gvk.Kind && resource.Namespaced && !strings.Contains(resource.Name, "/") {
+				gvrs = append(gvrs, gv.WithResource(resource.Name))
+			}
+		}
+	}
+	return gvrs, nil
+}
@@ -0,0 +1,280 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/dynamic"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var (
+	memcachedGVR  = schema.GroupVersionResource{Group: "cache.example.com", Version: "v1alpha1", Resource: "memcacheds"}
+	deploymentGVR = schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	podGVR        = schema.GroupVersionResource{Version: "v1", Resource: "pods"}
+)
+
+// fakeOperator reconciles the Memcached CRs in a fake cluster: it sets their
+// status conditions, creates a Deployment owned by each CR and a Pod owned by
+// the Deployment, and garbage collects objects whose owner no longer exists.
+type fakeOperator struct {
+	client          dynamic.Interface
+	namespace       string
+	setConditions   bool
+	setOwnerRefs    bool
+	collectGarbage  bool
+	stop, finished  chan struct{}
+	reconcilePeriod time.Duration
+}
+
+func (o *fakeOperator) start() {
+	o.stop, o.finished = make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(o.finished)
+		for {
+			select {
+			case <-o.stop:
+				return
+			case <-time.After(o.reconcilePeriod):
+				o.reconcile()
+			}
+		}
+	}()
+}
+
+func (o *fakeOperator) shutdown() {
+	close(o.stop)
+	<-o.finished
+}
+
+func (o *fakeOperator) reconcile() {
+	ctx := context.TODO()
+	crs, err := o.client.Resource(memcachedGVR).Namespace(o.namespace).List(ctx, metav1.ListOptions{})
+	Expect(err).NotTo(HaveOccurred())
+	uids := map[types.UID]bool{}
+	for i := range crs.Items {
+		cr := &crs.Items[i]
+		uids[cr.GetUID()] = true
+		if o.setConditions {
+			conditions := []interface{}{map[string]interface{}{"type": "Available", "status": "True"}}
+			Expect(unstructured.SetNestedSlice(cr.Object, conditions, "status", "conditions")).To(Succeed())
+			_, err := o.client.Resource(memcachedGVR).Namespace(o.namespace).Update(ctx, cr, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+		}
+		dep := o.ensure(deploymentGVR, "Deployment", cr.GetName()+"-deployment", cr)
+		uids[dep.GetUID()] = true
+		o.ensure(podGVR, "Pod", cr.GetName()+"-pod", dep)
+	}
+
+	if !o.collectGarbage {
+		return
+	}
+	for _, gvr := range []schema.GroupVersionResource{deploymentGVR, podGVR} {
+		list, err := o.client.Resource(gvr).Namespace(o.namespace).List(ctx, metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		for _, obj := range list.Items {
+			refs := obj.GetOwnerReferences()
+			if len(refs) > 0 && !uids[refs[0].UID] {
+				Expect(o.client.Resource(gvr).Namespace(o.namespace).Delete(ctx, obj.GetName(), metav1.DeleteOptions{})).To(Succeed())
+			}
+		}
+	}
+}
+
+// ensure creates the object of gvr named name, owned by owner, if it does not exist.
+func (o *fakeOperator) ensure(gvr schema.GroupVersionResource, kind, name string, owner *unstructured.Unstructured) *unstructured.Unstructured {
+	ctx := context.TODO()
+	if obj, err := o.client.Resource(gvr).Namespace(o.namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+		return obj
+	}
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion(gvr.GroupVersion().String())
+	obj.SetKind(kind)
+	obj.SetName(name)
+	obj.SetNamespace(o.namespace)
+	if o.setOwnerRefs {
+		obj.SetOwnerReferences([]metav1.OwnerReference{{
+			APIVersion: owner.GetAPIVersion(),
+			Kind:       owner.GetKind(),
+			Name:       owner.GetName(),
+			UID:        owner.GetUID(),
+		}})
+	}
+	obj, err := o.client.Resource(gvr).Namespace(o.namespace).Create(ctx, obj, metav1.CreateOptions{})
+	Expect(err).NotTo(HaveOccurred())
+	return obj
+}
+
+var _ = Describe("Cluster tests", func() {
+	const namespace = "scorecard"
+
+	var (
+		bundle   *apimanifests.Bundle
+		client   *fakedynamic.FakeDynamicClient
+		cluster  Cluster
+		operator *fakeOperator
+		crPrefix = fmt.Sprintf("%s %q: ", memcachedGVR.GroupVersion().WithKind("Memcached"), "example-memcached")
+	)
+
+	BeforeEach(func() {
+		var err error
+		bundle, err = apimanifests.GetBundleFromDir(filepath.Join("..", "testdata", "bundle"))
+		Expect(err).NotTo(HaveOccurred())
+
+		client = fakedynamic.NewSimpleDynamicClient(runtime.NewScheme())
+		// Set UIDs on create like the API server.
+		client.PrependReactor("create", "*", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			obj := action.(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured)
+			obj.SetUID(types.UID(rand.String(10)))
+			return false, nil, nil
+		})
+		discovery := &fakediscovery.FakeDiscovery{Fake: &clienttesting.Fake{Resources: []*metav1.APIResourceList{
+			{
+				GroupVersion: memcachedGVR.GroupVersion().String(),
+				APIResources: []metav1.APIResource{
+					{Name: "memcacheds", Kind: "Memcached", Namespaced: true},
+					{Name: "memcacheds/status", Kind: "Memcached", Namespaced: true},
+				},
+			},
+			{
+				GroupVersion: deploymentGVR.GroupVersion().String(),
+				APIResources: []metav1.APIResource{{Name: "deployments", Kind: "Deployment", Namespaced: true}},
+			},
+			{
+				GroupVersion: "v1",
+				APIResources: []metav1.APIResource{{Name: "pods", Kind: "Pod", Namespaced: true}},
+			},
+		}}}
+		cluster = Cluster{
+			Client:       client,
+			Discovery:    discovery,
+			Namespace:    namespace,
+			Timeout:      200 * time.Millisecond,
+			PollInterval: 5 * time.Millisecond,
+		}
+		operator = &fakeOperator{
+			client:          client,
+			namespace:       namespace,
+			setConditions:   true,
+			setOwnerRefs:    true,
+			collectGarbage:  true,
+			reconcilePeriod: 5 * time.Millisecond,
+		}
+	})
+
+	run := func(test func(context.Context, *apimanifests.Bundle) scapiv1alpha3.TestStatus) scapiv1alpha3.TestResult {
+		operator.start()
+		defer operator.shutdown()
+		status := test(context.TODO(), bundle)
+		Expect(status.Results).To(HaveLen(1))
+		return status.Results[0]
+	}
+
+	expectCRsDeleted := func() {
+		crs, err := client.Resource(memcachedGVR).Namespace(namespace).List(context.TODO(), metav1.ListOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(crs.Items).To(BeEmpty())
+	}
+
+	Describe("StatusConditionsTest", func() {
+		It("passes when the CR gets status conditions", func() {
+			result := run(cluster.StatusConditionsTest)
+			Expect(result.Name).To(Equal(BasicCheckStatusConditionsTest))
+			Expect(result.Errors).To(BeEmpty())
+			Expect(result.State).To(Equal(scapiv1alpha3.PassState))
+			expectCRsDeleted()
+		})
+
+		It("names the CR that gets no status conditions", func() {
+			operator.setConditions = false
+			result := run(cluster.StatusConditionsTest)
+			Expect(result.State).To(Equal(scapiv1alpha3.FailState))
+			Expect(result.Errors).To(ConsistOf(
+				MatchRegexp(`^` + crPrefix + `CR example-memcached-\w{5} has no status conditions after 200ms$`)))
+			expectCRsDeleted()
+		})
+
+		It("fails when the CR's kind is not served", func() {
+			cluster.Discovery.(*fakediscovery.FakeDiscovery).Resources = nil
+			result := run(cluster.StatusConditionsTest)
+			Expect(result.State).To(Equal(scapiv1alpha3.FailState))
+			Expect(result.Errors).To(ConsistOf(crPrefix + "the API server does not serve this kind, is its CRD installed?"))
+		})
+	})
+
+	Describe("OwnerReferencesTest", func() {
+		It("passes when the CR owns the listed resources through other objects", func() {
+			result := run(cluster.OwnerReferencesTest)
+			Expect(result.Errors).To(BeEmpty())
+			Expect(result.State).To(Equal(scapiv1alpha3.PassState))
+		})
+
+		It("names the CR that owns none of a listed resource", func() {
+			operator.setOwnerRefs = false
+			result := run(cluster.OwnerReferencesTest)
+			Expect(result.State).To(Equal(scapiv1alpha3.FailState))
+			Expect(result.Errors).To(ConsistOf(MatchRegexp(`^` + crPrefix +
+				`no object of kind Pod \(v1\) in namespace scorecard is owned by CR example-memcached-\w{5} after 200ms$`)))
+		})
+	})
+
+	Describe("CleanupTest", func() {
+		It("passes when the CR and its dependents are deleted", func() {
+			result := run(cluster.CleanupTest)
+			Expect(result.Errors).To(BeEmpty())
+			Expect(result.State).To(Equal(scapiv1alpha3.PassState))
+			expectCRsDeleted()
+		})
+
+		It("names the dependents that are not deleted", func() {
+			operator.collectGarbage = false
+			result := run(cluster.CleanupTest)
+			Expect(result.State).To(Equal(scapiv1alpha3.FailState))
+			Expect(result.Errors).To(ConsistOf(MatchRegexp(`^` + crPrefix +
+				`objects owned by CR example-memcached-(\w{5}) still exist 200ms after it was deleted: Pod example-memcached-\w{5}-pod$`)))
+		})
+
+		It("names the CR that is not deleted", func() {
+			client.PrependReactor("create", "memcacheds", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				obj := action.(clienttesting.CreateAction).GetObject().(*unstructured.Unstructured)
+				obj.SetFinalizers([]string{"cache.example.com/finalizer"})
+				return false, nil, nil
+			})
+			client.PrependReactor("delete", "memcacheds", func(clienttesting.Action) (bool, runtime.Object, error) {
+				return true, nil, nil
+			})
+			result := run(cluster.CleanupTest)
+			Expect(result.State).To(Equal(scapiv1alpha3.FailState))
+			Expect(result.Errors).To(ConsistOf(MatchRegexp(`^` + crPrefix +
+				`CR example-memcached-\w{5} still exists 200ms after it was deleted, with finalizers \[cache.example.com/finalizer\]$`)))
+		})
+	})
+})
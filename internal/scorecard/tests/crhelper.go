@@ -15,28 +15,13 @@
 package tests
 
 import (
-	"encoding/json"
-	"fmt"
-
 	apimanifests "github.com/operator-framework/api/pkg/manifests"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	"github.com/operator-framework/operator-sdk/pkg/scorecard"
 )
 
 // GetCRs parses a Bundle's CSV for CRs
 func GetCRs(bundle *apimanifests.Bundle) (crList []unstructured.Unstructured, err error) {
-	if bundle.CSV.GetAnnotations() == nil {
-		return crList, nil
-	}
-
-	// get CRs from CSV's alm-examples annotation, assume single bundle
-	almExamples := bundle.CSV.GetAnnotations()["alm-examples"]
-	if almExamples == "" {
-		return crList, nil
-	}
-
-	err = json.Unmarshal([]byte(almExamples), &crList)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse alm-examples annotation: %v", err)
-	}
-	return crList, nil
+	return scorecard.GetCRs(bundle)
 }
@@ -0,0 +1,84 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"context"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+
+	registryutil "github.com/operator-framework/operator-sdk/internal/registry"
+)
+
+// Names are the names of the built-in tests that only inspect the bundle,
+// in the order they are listed to users.
+var Names = []string{
+	OLMBundleValidationTest,
+	OLMCRDsHaveValidationTest,
+	OLMCRDsHaveResourcesTest,
+	OLMSpecDescriptorsTest,
+	OLMStatusDescriptorsTest,
+	BasicCheckSpecTest,
+}
+
+// Run runs the built-in test named name against bundle, which was read from
+// bundleRoot and has the given metadata. ok is false if there is no built-in
+// test named name.
+func Run(name, bundleRoot string, bundle *apimanifests.Bundle,
+	metadata registryutil.Labels) (result scapiv1alpha3.TestStatus, ok bool) {
+
+	switch name {
+	case OLMBundleValidationTest:
+		return BundleValidationTest(bundleRoot, metadata), true
+	case OLMCRDsHaveValidationTest:
+		return CRDsHaveValidationTest(bundle), true
+	case OLMCRDsHaveResourcesTest:
+		return CRDsHaveResourcesTest(bundle), true
+	case OLMSpecDescriptorsTest:
+		return SpecDescriptorsTest(bundle), true
+	case OLMStatusDescriptorsTest:
+		return StatusDescriptorsTest(bundle), true
+	case BasicCheckSpecTest:
+		return CheckSpecTest(bundle), true
+	default:
+		return scapiv1alpha3.TestStatus{}, false
+	}
+}
+
+// ClusterNames are the names of the built-in tests that deploy the bundle's CRs
+// to a cluster, in the order they are listed to users.
+var ClusterNames = []string{
+	BasicCheckStatusConditionsTest,
+	BasicCheckOwnerReferencesTest,
+	BasicCheckCleanupTest,
+}
+
+// RunCluster runs the built-in test named name, which deploys the CRs of bundle
+// to cluster. ok is false if there is no such test.
+func RunCluster(ctx context.Context, name string, cluster Cluster,
+	bundle *apimanifests.Bundle) (result scapiv1alpha3.TestStatus, ok bool) {
+
+	switch name {
+	case BasicCheckStatusConditionsTest:
+		return cluster.StatusConditionsTest(ctx, bundle), true
+	case BasicCheckOwnerReferencesTest:
+		return cluster.OwnerReferencesTest(ctx, bundle), true
+	case BasicCheckCleanupTest:
+		return cluster.CleanupTest(ctx, bundle), true
+	default:
+		return scapiv1alpha3.TestStatus{}, false
+	}
+}
@@ -23,4 +23,10 @@ const (
 	// which is the namespace where the watch activity happens.
 	// this value is empty if the operator is running with clusterScope.
 	WatchNamespaceEnvVar = "WATCH_NAMESPACE"
+
+	// WatchNamespaceSelectorEnvVar is the constant for env variable
+	// WATCH_NAMESPACE_SELECTOR which is a label selector of the namespaces
+	// where the watch activity happens. The watched namespaces change as
+	// namespaces are labeled. It cannot be used with WATCH_NAMESPACE.
+	WatchNamespaceSelectorEnvVar = "WATCH_NAMESPACE_SELECTOR"
 )
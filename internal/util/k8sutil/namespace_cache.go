@@ -0,0 +1,591 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var nsCacheLog = logf.Log.WithName("namespace-selector-cache")
+
+// NamespaceHandler is called when a namespace starts or stops being watched.
+type NamespaceHandler func(namespace string, watched bool)
+
+// GetWatchNamespaceSelector returns the label selector of the watched
+// namespaces if it is set by the WATCH_NAMESPACE_SELECTOR environment
+// variable, or nil. namespace is the value of WATCH_NAMESPACE, which must not
+// be set together with the selector.
+func GetWatchNamespaceSelector(namespace string) (labels.Selector, error) {
+	value, found := os.LookupEnv(WatchNamespaceSelectorEnvVar)
+	if !found {
+		return nil, nil
+	}
+	if namespace != metav1.NamespaceAll {
+		return nil, fmt.Errorf("%s and %s cannot both be set", WatchNamespaceEnvVar, WatchNamespaceSelectorEnvVar)
+	}
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %v", WatchNamespaceSelectorEnvVar, err)
+	}
+	return selector, nil
+}
+
+// NamespaceSelectorCacheBuilder returns a function that creates a cache of the
+// objects in the namespaces whose labels match selector. Namespaces are
+// watched, and a namespace's cache is started when the namespace starts
+// matching selector and stopped when it stops matching or is deleted.
+// Informers returned by the cache, and therefore the watches of controllers
+// that use it, follow these changes. Objects in namespaces that are not
+// watched are not found. Cluster-scoped objects are cached cluster-wide.
+//
+// handlers are called after a namespace's cache is started or stopped.
+func NamespaceSelectorCacheBuilder(selector labels.Selector, handlers ...NamespaceHandler) cache.NewCacheFunc {
+	return func(config *rest.Config, opts cache.Options) (cache.Cache, error) {
+		if opts.Scheme == nil {
+			opts.Scheme = scheme.Scheme
+		}
+		if opts.Mapper == nil {
+			mapper, err := apiutil.NewDynamicRESTMapper(config)
+			if err != nil {
+				return nil, err
+			}
+			opts.Mapper = mapper
+		}
+		return newNamespaceSelectorCache(selector, opts.Scheme, opts.Mapper,
+			func(namespace string) (cache.Cache, error) {
+				nsOpts := opts
+				nsOpts.Namespace = namespace
+				return cache.New(config, nsOpts)
+			}, handlers...)
+	}
+}
+
+// namespaceSelectorCache is a cache of the objects in the namespaces whose
+// labels match a selector.
+type namespaceSelectorCache struct {
+	selector labels.Selector
+	scheme   *runtime.Scheme
+	mapper   meta.RESTMapper
+	newCache func(namespace string) (cache.Cache, error)
+	handlers []NamespaceHandler
+
+	// clusterCache holds namespaces and other cluster-scoped objects.
+	clusterCache cache.Cache
+
+	// nsMu serializes adding and removing namespaces. Caches and informers are
+	// built without holding mu, which getting informers from started caches
+	// would block until they sync.
+	nsMu sync.Mutex
+	mu   sync.RWMutex
+	// ctx is the context the cache was started with, and is nil before then.
+	ctx        context.Context
+	namespaces map[string]*namespaceCache
+	informers  map[informerKey]*namespaceSelectorInformer
+	indexes    []fieldIndex
+}
+
+var _ cache.Cache = &namespaceSelectorCache{}
+
+type namespaceCache struct {
+	cache.Cache
+	cancel context.CancelFunc
+}
+
+type informerKey struct {
+	gvk          schema.GroupVersionKind
+	unstructured bool
+}
+
+type fieldIndex struct {
+	obj          client.Object
+	field        string
+	extractValue client.IndexerFunc
+}
+
+func newNamespaceSelectorCache(selector labels.Selector, s *runtime.Scheme, mapper meta.RESTMapper,
+	newCache func(namespace string) (cache.Cache, error), handlers ...NamespaceHandler) (*namespaceSelectorCache, error) {
+	clusterCache, err := newCache(metav1.NamespaceAll)
+	if err != nil {
+		return nil, err
+	}
+	return &namespaceSelectorCache{
+		selector:     selector,
+		scheme:       s,
+		mapper:       mapper,
+		newCache:     newCache,
+		handlers:     handlers,
+		clusterCache: clusterCache,
+		namespaces:   map[string]*namespaceCache{},
+		informers:    map[informerKey]*namespaceSelectorInformer{},
+	}, nil
+}
+
+// Start watches namespaces, and starts and stops their caches as they match
+// the selector, until ctx is done.
+func (c *namespaceSelectorCache) Start(ctx context.Context) error {
+	informer, err := c.clusterCache.GetInformer(ctx, &corev1.Namespace{})
+	if err != nil {
+		return fmt.Errorf("failed to get namespace informer: %w", err)
+	}
+	c.mu.Lock()
+	c.ctx = ctx
+	c.mu.Unlock()
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc:    c.syncNamespace,
+		UpdateFunc: func(_, obj interface{}) { c.syncNamespace(obj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(toolscache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if ns, ok := obj.(*corev1.Namespace); ok {
+				c.removeNamespace(ns.GetName())
+			}
+		},
+	})
+	if err := c.clusterCache.Start(ctx); err != nil {
+		return err
+	}
+	<-ctx.Done()
+	return nil
+}
+
+// WaitForCacheSync waits for the caches of the namespaces that match the
+// selector when it is called to be synced.
+func (c *namespaceSelectorCache) WaitForCacheSync(ctx context.Context) bool {
+	if !c.clusterCache.WaitForCacheSync(ctx) {
+		return false
+	}
+	// Namespace events are handled asynchronously, so the namespaces that
+	// already match are added here to be synced before this returns.
+	namespaces := &corev1.NamespaceList{}
+	if err := c.clusterCache.List(ctx, namespaces, client.MatchingLabelsSelector{Selector: c.selector}); err != nil {
+		nsCacheLog.Error(err, "Failed to list namespaces")
+		return false
+	}
+	for _, ns := range namespaces.Items {
+		c.addNamespace(ns.GetName())
+	}
+
+	c.mu.RLock()
+	caches := make([]cache.Cache, 0, len(c.namespaces))
+	for _, nc := range c.namespaces {
+		caches = append(caches, nc.Cache)
+	}
+	c.mu.RUnlock()
+	for _, nc := range caches {
+		if !nc.WaitForCacheSync(ctx) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *namespaceSelectorCache) syncNamespace(obj interface{}) {
+	ns, ok := obj.(*corev1.Namespace)
+	if !ok {
+		return
+	}
+	if c.selector.Matches(labels.Set(ns.GetLabels())) {
+		c.addNamespace(ns.GetName())
+	} else {
+		c.removeNamespace(ns.GetName())
+	}
+}
+
+func (c *namespaceSelectorCache) addNamespace(namespace string) {
+	c.nsMu.Lock()
+	defer c.nsMu.Unlock()
+	c.mu.RLock()
+	_, ok := c.namespaces[namespace]
+	parent := c.ctx
+	c.mu.RUnlock()
+	if ok || parent == nil {
+		return
+	}
+
+	nsCache, ctx, err := c.newNamespaceCache(parent, namespace)
+	if err != nil {
+		c.mu.RLock()
+		for _, informer := range c.informers {
+			informer.removeNamespace(namespace)
+		}
+		c.mu.RUnlock()
+		nsCacheLog.Error(err, "Failed to create cache", "namespace", namespace)
+		return
+	}
+
+	go func() {
+		if err := nsCache.Start(ctx); err != nil {
+			nsCacheLog.Error(err, "Failed to start cache", "namespace", namespace)
+		}
+	}()
+	nsCacheLog.Info("Watching namespace", "namespace", namespace)
+	for _, h := range c.handlers {
+		h(namespace, true)
+	}
+}
+
+// newNamespaceCache creates the cache of namespace with the indexes and
+// informers that were requested from c, and adds it to c once it has all of
+// them. It returns the context to start the cache with.
+func (c *namespaceSelectorCache) newNamespaceCache(parent context.Context,
+	namespace string) (cache.Cache, context.Context, error) {
+	nsCache, err := c.newCache(namespace)
+	if err != nil {
+		return nil, nil, err
+	}
+	indexed := 0
+	added := map[informerKey]bool{}
+	for {
+		// Indexes and informers requested while they are added are added by
+		// the next iteration.
+		c.mu.RLock()
+		indexes := c.indexes[indexed:]
+		informers := map[informerKey]*namespaceSelectorInformer{}
+		for key, informer := range c.informers {
+			if !added[key] {
+				informers[key] = informer
+			}
+		}
+		c.mu.RUnlock()
+
+		for _, idx := range indexes {
+			if err := nsCache.IndexField(parent, idx.obj, idx.field, idx.extractValue); err != nil {
+				return nil, nil, err
+			}
+		}
+		indexed += len(indexes)
+		for key, informer := range informers {
+			if err := informer.addNamespace(parent, namespace, nsCache); err != nil {
+				return nil, nil, err
+			}
+			added[key] = true
+		}
+
+		c.mu.Lock()
+		if len(c.indexes) == indexed && len(c.informers) == len(added) {
+			ctx, cancel := context.WithCancel(parent)
+			c.namespaces[namespace] = &namespaceCache{Cache: nsCache, cancel: cancel}
+			c.mu.Unlock()
+			return nsCache, ctx, nil
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *namespaceSelectorCache) removeNamespace(namespace string) {
+	c.nsMu.Lock()
+	defer c.nsMu.Unlock()
+	c.mu.Lock()
+	nc, ok := c.namespaces[namespace]
+	if !ok {
+		c.mu.Unlock()
+		return
+	}
+	delete(c.namespaces, namespace)
+	for _, informer := range c.informers {
+		informer.removeNamespace(namespace)
+	}
+	c.mu.Unlock()
+
+	nc.cancel()
+	nsCacheLog.Info("Stopped watching namespace", "namespace", namespace)
+	for _, h := range c.handlers {
+		h(namespace, false)
+	}
+}
+
+// mapping returns the REST mapping of obj, which may be a list.
+func (c *namespaceSelectorCache) mapping(obj runtime.Object) (*meta.RESTMapping, error) {
+	gvk, err := apiutil.GVKForObject(obj, c.scheme)
+	if err != nil {
+		return nil, err
+	}
+	if meta.IsListType(obj) {
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+	return c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+}
+
+func isNamespaced(mapping *meta.RESTMapping) bool {
+	return mapping.Scope.Name() == meta.RESTScopeNameNamespace
+}
+
+func (c *namespaceSelectorCache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	mapping, err := c.mapping(obj)
+	if err != nil {
+		return nil, err
+	}
+	if !isNamespaced(mapping) {
+		return c.clusterCache.GetInformer(ctx, obj)
+	}
+	_, isUnstructured := obj.(runtime.Unstructured)
+	key := informerKey{gvk: mapping.GroupVersionKind, unstructured: isUnstructured}
+	return c.informerFor(ctx, key, func(ctx context.Context, nsCache cache.Cache) (cache.Informer, error) {
+		return nsCache.GetInformer(ctx, obj.DeepCopyObject().(client.Object))
+	})
+}
+
+func (c *namespaceSelectorCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (cache.Informer, error) {
+	mapping, err := c.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, err
+	}
+	if !isNamespaced(mapping) {
+		return c.clusterCache.GetInformerForKind(ctx, gvk)
+	}
+	return c.informerFor(ctx, informerKey{gvk: gvk}, func(ctx context.Context, nsCache cache.Cache) (cache.Informer, error) {
+		return nsCache.GetInformerForKind(ctx, gvk)
+	})
+}
+
+// informerFor returns the informer of key, and creates it from the informers
+// get returns for the watched namespaces if it does not exist.
+func (c *namespaceSelectorCache) informerFor(ctx context.Context, key informerKey,
+	get func(context.Context, cache.Cache) (cache.Informer, error)) (cache.Informer, error) {
+	informer := &namespaceSelectorInformer{get: get, informers: map[string]cache.Informer{}}
+	added := map[string]*namespaceCache{}
+	for {
+		// Namespaces that are added while informers are got from their
+		// caches are added by the next iteration.
+		c.mu.RLock()
+		if existing, ok := c.informers[key]; ok {
+			c.mu.RUnlock()
+			return existing, nil
+		}
+		pending := map[string]*namespaceCache{}
+		for ns, nc := range c.namespaces {
+			if added[ns] != nc {
+				pending[ns] = nc
+			}
+		}
+		c.mu.RUnlock()
+
+		for ns, nc := range pending {
+			if err := informer.addNamespace(ctx, ns, nc.Cache); err != nil {
+				return nil, err
+			}
+			added[ns] = nc
+		}
+
+		c.mu.Lock()
+		if existing, ok := c.informers[key]; ok {
+			c.mu.Unlock()
+			return existing, nil
+		}
+		for ns, nc := range added {
+			if c.namespaces[ns] != nc {
+				informer.removeNamespace(ns)
+				delete(added, ns)
+			}
+		}
+		if len(added) == len(c.namespaces) {
+			c.informers[key] = informer
+			c.mu.Unlock()
+			return informer, nil
+		}
+		c.mu.Unlock()
+	}
+}
+
+func (c *namespaceSelectorCache) IndexField(ctx context.Context, obj client.Object, field string,
+	extractValue client.IndexerFunc) error {
+	mapping, err := c.mapping(obj)
+	if err != nil {
+		return err
+	}
+	if !isNamespaced(mapping) {
+		return c.clusterCache.IndexField(ctx, obj, field, extractValue)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, nc := range c.namespaces {
+		if err := nc.IndexField(ctx, obj, field, extractValue); err != nil {
+			return err
+		}
+	}
+	c.indexes = append(c.indexes, fieldIndex{obj: obj, field: field, extractValue: extractValue})
+	return nil
+}
+
+func (c *namespaceSelectorCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	mapping, err := c.mapping(obj)
+	if err != nil {
+		return err
+	}
+	if !isNamespaced(mapping) {
+		return c.clusterCache.Get(ctx, key, obj)
+	}
+	c.mu.RLock()
+	nc, ok := c.namespaces[key.Namespace]
+	c.mu.RUnlock()
+	if !ok {
+		return apierrors.NewNotFound(mapping.Resource.GroupResource(), key.Name)
+	}
+	return nc.Get(ctx, key, obj)
+}
+
+// List lists the objects of the watched namespaces if no namespace is given.
+// Listing the objects of a namespace that is not watched returns no objects.
+func (c *namespaceSelectorCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	mapping, err := c.mapping(list)
+	if err != nil {
+		return err
+	}
+	if !isNamespaced(mapping) {
+		return c.clusterCache.List(ctx, list, opts...)
+	}
+
+	listOpts := client.ListOptions{}
+	listOpts.ApplyOptions(opts)
+	c.mu.RLock()
+	var caches []cache.Cache
+	for ns, nc := range c.namespaces {
+		if listOpts.Namespace == metav1.NamespaceAll || listOpts.Namespace == ns {
+			caches = append(caches, nc.Cache)
+		}
+	}
+	c.mu.RUnlock()
+
+	var allItems []runtime.Object
+	var resourceVersion string
+	for _, nc := range caches {
+		listObj := list.DeepCopyObject().(client.ObjectList)
+		if err := nc.List(ctx, listObj, opts...); err != nil {
+			return err
+		}
+		items, err := meta.ExtractList(listObj)
+		if err != nil {
+			return err
+		}
+		allItems = append(allItems, items...)
+		resourceVersion = listObj.GetResourceVersion()
+	}
+	list.SetResourceVersion(resourceVersion)
+	return meta.SetList(list, allItems)
+}
+
+// namespaceSelectorInformer is an informer of the objects of a kind in the
+// watched namespaces. Its event handlers and indexers are added to the
+// informers of namespaces that are watched later.
+type namespaceSelectorInformer struct {
+	get func(context.Context, cache.Cache) (cache.Informer, error)
+
+	mu        sync.RWMutex
+	informers map[string]cache.Informer
+	handlers  []eventHandler
+	indexers  []toolscache.Indexers
+}
+
+var _ cache.Informer = &namespaceSelectorInformer{}
+
+type eventHandler struct {
+	handler      toolscache.ResourceEventHandler
+	resyncPeriod *time.Duration
+}
+
+func (h eventHandler) addTo(informer cache.Informer) {
+	if h.resyncPeriod != nil {
+		informer.AddEventHandlerWithResyncPeriod(h.handler, *h.resyncPeriod)
+	} else {
+		informer.AddEventHandler(h.handler)
+	}
+}
+
+func (i *namespaceSelectorInformer) addNamespace(ctx context.Context, namespace string, nsCache cache.Cache) error {
+	informer, err := i.get(ctx, nsCache)
+	if err != nil {
+		return err
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, indexers := range i.indexers {
+		if err := informer.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	for _, h := range i.handlers {
+		h.addTo(informer)
+	}
+	i.informers[namespace] = informer
+	return nil
+}
+
+func (i *namespaceSelectorInformer) removeNamespace(namespace string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.informers, namespace)
+}
+
+func (i *namespaceSelectorInformer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	i.addEventHandler(eventHandler{handler: handler})
+}
+
+func (i *namespaceSelectorInformer) AddEventHandlerWithResyncPeriod(handler toolscache.ResourceEventHandler,
+	resyncPeriod time.Duration) {
+	i.addEventHandler(eventHandler{handler: handler, resyncPeriod: &resyncPeriod})
+}
+
+func (i *namespaceSelectorInformer) addEventHandler(h eventHandler) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.handlers = append(i.handlers, h)
+	for _, informer := range i.informers {
+		h.addTo(informer)
+	}
+}
+
+func (i *namespaceSelectorInformer) AddIndexers(indexers toolscache.Indexers) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, informer := range i.informers {
+		if err := informer.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	i.indexers = append(i.indexers, indexers)
+	return nil
+}
+
+func (i *namespaceSelectorInformer) HasSynced() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+	for _, informer := range i.informers {
+		if !informer.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,205 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package k8sutil
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestNamespace(name string, lbls map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: lbls}}
+}
+
+func TestGetWatchNamespaceSelector(t *testing.T) {
+	defer os.Unsetenv(WatchNamespaceSelectorEnvVar)
+
+	selector, err := GetWatchNamespaceSelector("")
+	assert.NoError(t, err)
+	assert.Nil(t, selector)
+
+	os.Setenv(WatchNamespaceSelectorEnvVar, "env=prod")
+	selector, err = GetWatchNamespaceSelector("")
+	require.NoError(t, err)
+	assert.Equal(t, "env=prod", selector.String())
+
+	_, err = GetWatchNamespaceSelector("default")
+	assert.EqualError(t, err, "WATCH_NAMESPACE and WATCH_NAMESPACE_SELECTOR cannot both be set")
+
+	os.Setenv(WatchNamespaceSelectorEnvVar, "env in (")
+	_, err = GetWatchNamespaceSelector("")
+	assert.Error(t, err)
+}
+
+func TestNamespaceSelectorCache(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	caches := map[string]*informertest.FakeInformers{}
+	var events []string
+	c, err := newNamespaceSelectorCache(labels.SelectorFromSet(labels.Set{"env": "prod"}), scheme.Scheme, mapper,
+		func(namespace string) (cache.Cache, error) {
+			caches[namespace] = &informertest.FakeInformers{}
+			return caches[namespace], nil
+		},
+		func(namespace string, watched bool) {
+			if watched {
+				events = append(events, "+"+namespace)
+			} else {
+				events = append(events, "-"+namespace)
+			}
+		})
+	require.NoError(t, err)
+
+	// Start returns once ctx is done, after the namespace handler is added.
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	require.NoError(t, c.Start(ctx))
+	nsInformer, err := caches[""].FakeInformerFor(&corev1.Namespace{})
+	require.NoError(t, err)
+
+	informer, err := c.GetInformer(ctx, &corev1.ConfigMap{})
+	require.NoError(t, err)
+	var added []string
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { added = append(added, obj.(*corev1.ConfigMap).GetNamespace()) },
+	})
+
+	prod := newTestNamespace("a", map[string]string{"env": "prod"})
+	nsInformer.Add(prod)
+	nsInformer.Add(newTestNamespace("b", nil))
+	assert.Equal(t, []string{"+a"}, events)
+	require.Contains(t, caches, "a")
+	assert.NotContains(t, caches, "b")
+
+	// Handlers added before the namespace was watched receive its events.
+	cmInformer, err := caches["a"].FakeInformerFor(&corev1.ConfigMap{})
+	require.NoError(t, err)
+	cmInformer.Add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "foo"}})
+	assert.Equal(t, []string{"a"}, added)
+
+	key := client.ObjectKey{Namespace: "a", Name: "foo"}
+	assert.NoError(t, c.Get(ctx, key, &corev1.ConfigMap{}))
+	err = c.Get(ctx, client.ObjectKey{Namespace: "b", Name: "foo"}, &corev1.ConfigMap{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.NoError(t, c.List(ctx, &corev1.ConfigMapList{}, client.InNamespace("b")))
+
+	// Cluster-scoped objects are served from the cluster-wide cache.
+	clusterInformer, err := c.GetInformer(ctx, &corev1.Namespace{})
+	require.NoError(t, err)
+	assert.Equal(t, nsInformer, clusterInformer)
+
+	unlabeled := prod.DeepCopy()
+	unlabeled.Labels = nil
+	nsInformer.Update(prod, unlabeled)
+	assert.Equal(t, []string{"+a", "-a"}, events)
+	err = c.Get(ctx, key, &corev1.ConfigMap{})
+	assert.True(t, apierrors.IsNotFound(err))
+
+	nsInformer.Update(unlabeled, prod)
+	nsInformer.Delete(prod)
+	assert.Equal(t, []string{"+a", "-a", "+a", "-a"}, events)
+}
+
+// blockingCache is a started cache whose informers block until unblock is
+// closed, like those of a namespace that does not sync. blocked receives a
+// value when an informer is requested.
+type blockingCache struct {
+	*informertest.FakeInformers
+	blocked chan struct{}
+	unblock chan struct{}
+}
+
+func (c blockingCache) GetInformer(ctx context.Context, obj client.Object) (cache.Informer, error) {
+	c.blocked <- struct{}{}
+	<-c.unblock
+	return c.FakeInformers.GetInformer(ctx, obj)
+}
+
+func TestNamespaceSelectorCacheInformerDoesNotBlock(t *testing.T) {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("Namespace"), meta.RESTScopeRoot)
+	mapper.Add(corev1.SchemeGroupVersion.WithKind("ConfigMap"), meta.RESTScopeNamespace)
+
+	blocked, unblock := make(chan struct{}, 1), make(chan struct{})
+	var mu sync.Mutex
+	caches := map[string]*informertest.FakeInformers{}
+	c, err := newNamespaceSelectorCache(labels.Everything(), scheme.Scheme, mapper,
+		func(namespace string) (cache.Cache, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			caches[namespace] = &informertest.FakeInformers{}
+			if namespace == "stuck" {
+				return blockingCache{FakeInformers: caches[namespace], blocked: blocked, unblock: unblock}, nil
+			}
+			return caches[namespace], nil
+		})
+	require.NoError(t, err)
+	ctx, cancel := context.WithCancel(context.TODO())
+	cancel()
+	require.NoError(t, c.Start(ctx))
+	nsInformer, err := caches[""].FakeInformerFor(&corev1.Namespace{})
+	require.NoError(t, err)
+	nsInformer.Add(newTestNamespace("stuck", nil))
+
+	informers := make(chan cache.Informer)
+	go func() {
+		informer, err := c.GetInformer(ctx, &corev1.ConfigMap{})
+		assert.NoError(t, err)
+		informers <- informer
+	}()
+	<-blocked
+
+	// Namespaces are added and objects are read while the informer waits for
+	// the namespace that does not sync.
+	nsInformer.Add(newTestNamespace("a", nil))
+	err = c.Get(ctx, client.ObjectKey{Namespace: "a", Name: "foo"}, &corev1.ConfigMap{})
+	assert.NoError(t, err)
+	select {
+	case <-informers:
+		t.Fatal("informer returned before the namespace synced")
+	default:
+	}
+
+	close(unblock)
+	informer := <-informers
+	var added []string
+	informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { added = append(added, obj.(*corev1.ConfigMap).GetNamespace()) },
+	})
+	mu.Lock()
+	a := caches["a"]
+	mu.Unlock()
+	cmInformer, err := a.FakeInformerFor(&corev1.ConfigMap{})
+	require.NoError(t, err)
+	cmInformer.Add(&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "a", Name: "foo"}})
+	assert.Equal(t, []string{"a"}, added)
+}
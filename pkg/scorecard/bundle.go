@@ -0,0 +1,71 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package scorecard helps write custom scorecard tests: images that scorecard
+// runs in a pod with the bundle under test mounted at PodBundleRoot, and that
+// print a v1alpha3.TestStatus as JSON.
+//
+// An image registers its tests with a Registry and calls its Main function,
+// which runs the test named by the image's entrypoint arguments:
+//
+//	func main() {
+//		registry := scorecard.NewRegistry()
+//		registry.Register("customtest1", CustomTest1)
+//		registry.Main()
+//	}
+//
+// Tests can be unit tested without a cluster with a FakeRunner.
+package scorecard
+
+import (
+	"encoding/json"
+	"fmt"
+
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+// PodBundleRoot is the directory the bundle under test is mounted at in test pods.
+const PodBundleRoot = "/bundle"
+
+// LoadBundle reads the bundle in root, usually PodBundleRoot.
+func LoadBundle(root string) (*apimanifests.Bundle, error) {
+	bundle, err := apimanifests.GetBundleFromDir(root)
+	if err != nil {
+		return nil, fmt.Errorf("error reading bundle from %s: %v", root, err)
+	}
+	return bundle, nil
+}
+
+// GetCRs parses a Bundle's CSV for CRs. It returns an error if bundle has no CSV.
+func GetCRs(bundle *apimanifests.Bundle) (crList []unstructured.Unstructured, err error) {
+	if bundle == nil || bundle.CSV == nil {
+		return nil, fmt.Errorf("bundle has no ClusterServiceVersion")
+	}
+	if bundle.CSV.GetAnnotations() == nil {
+		return crList, nil
+	}
+
+	// get CRs from CSV's alm-examples annotation, assume single bundle
+	almExamples := bundle.CSV.GetAnnotations()["alm-examples"]
+	if almExamples == "" {
+		return crList, nil
+	}
+
+	err = json.Unmarshal([]byte(almExamples), &crList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse alm-examples annotation: %v", err)
+	}
+	return crList, nil
+}
@@ -0,0 +1,70 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"fmt"
+	"os"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// NamespaceEnv is the environment variable scorecard sets in test pods to the
+// namespace they run in.
+const NamespaceEnv = "SCORECARD_NAMESPACE"
+
+// Client is a client for the namespace a test runs in.
+type Client struct {
+	// Kube is a client for built-in resources.
+	Kube kubernetes.Interface
+	// Dynamic is a client for any resource, like the CRs of the bundle.
+	Dynamic dynamic.Interface
+	// Namespace is the namespace the test runs in.
+	Namespace string
+}
+
+// NewClient returns a client for namespace of the cluster cfg connects to.
+func NewClient(cfg *rest.Config, namespace string) (*Client, error) {
+	kube, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	dyn, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{Kube: kube, Dynamic: dyn, Namespace: namespace}, nil
+}
+
+// NewInClusterClient returns a client for the namespace and cluster the test pod runs in.
+func NewInClusterClient() (*Client, error) {
+	cfg, err := rest.InClusterConfig()
+	if err != nil {
+		return nil, fmt.Errorf("error getting in-cluster config: %v", err)
+	}
+	namespace := os.Getenv(NamespaceEnv)
+	if namespace == "" {
+		return nil, fmt.Errorf("%s is not set", NamespaceEnv)
+	}
+	return NewClient(cfg, namespace)
+}
+
+// Resource returns a client for the resource gvr in c's namespace.
+func (c *Client) Resource(gvr schema.GroupVersionResource) dynamic.ResourceInterface {
+	return c.Dynamic.Resource(gvr).Namespace(c.Namespace)
+}
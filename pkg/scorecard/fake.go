@@ -0,0 +1,84 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"fmt"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// FakeRunner runs the tests of a Registry without a test pod or a cluster, to
+// unit test them. Tests get clients backed by in-memory fakes that hold Objects.
+type FakeRunner struct {
+	// BundleRoot is the directory to read the bundle from if Bundle is not set.
+	BundleRoot string
+	// Bundle is the bundle to run tests against.
+	Bundle *apimanifests.Bundle
+	// Namespace is the namespace tests run in, "default" if not set.
+	Namespace string
+	// Objects are the objects in the fake cluster. Built-in types are
+	// available to both clients, unstructured objects only to the dynamic
+	// client. The clients do not see each other's changes.
+	Objects []runtime.Object
+}
+
+// Env returns the environment tests of f run against.
+func (f FakeRunner) Env() (Env, error) {
+	env := Env{BundleRoot: f.BundleRoot, Bundle: f.Bundle}
+	if env.Bundle == nil {
+		var err error
+		if env.Bundle, err = LoadBundle(f.BundleRoot); err != nil {
+			return env, err
+		}
+	}
+
+	namespace := f.Namespace
+	if namespace == "" {
+		namespace = "default"
+	}
+	var typed []runtime.Object
+	for _, obj := range f.Objects {
+		if _, ok := obj.(*unstructured.Unstructured); !ok {
+			typed = append(typed, obj)
+		}
+	}
+	env.Client = &Client{
+		Kube:      kubefake.NewSimpleClientset(typed...),
+		Dynamic:   dynamicfake.NewSimpleDynamicClient(scheme.Scheme, f.Objects...),
+		Namespace: namespace,
+	}
+	return env, nil
+}
+
+// Run runs the test of registry named name.
+func (f FakeRunner) Run(ctx context.Context, registry *Registry, name string) (scapiv1alpha3.TestStatus, error) {
+	env, err := f.Env()
+	if err != nil {
+		return scapiv1alpha3.TestStatus{}, err
+	}
+	status, ok := registry.Run(ctx, name, env)
+	if !ok {
+		return status, fmt.Errorf("no test named %q", name)
+	}
+	return status, nil
+}
@@ -0,0 +1,78 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"testing"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestFakeRunner(t *testing.T) {
+	ctx := context.Background()
+	registry := newTestRegistry()
+
+	t.Run("bundle from disk", func(t *testing.T) {
+		status, err := FakeRunner{BundleRoot: testBundleRoot}.Run(ctx, registry, "count-crs")
+		require.NoError(t, err)
+		assert.Equal(t, scapiv1alpha3.PassState, status.Results[0].State)
+	})
+
+	t.Run("bundle in memory", func(t *testing.T) {
+		bundle := &apimanifests.Bundle{CSV: &v1alpha1.ClusterServiceVersion{}}
+		status, err := FakeRunner{Bundle: bundle}.Run(ctx, registry, "count-crs")
+		require.NoError(t, err)
+		assert.Equal(t, scapiv1alpha3.FailState, status.Results[0].State)
+		assert.Equal(t, []string{"no CRs in alm-examples"}, status.Results[0].Errors)
+	})
+
+	t.Run("missing test", func(t *testing.T) {
+		_, err := FakeRunner{BundleRoot: testBundleRoot}.Run(ctx, registry, "missing")
+		assert.EqualError(t, err, `no test named "missing"`)
+	})
+
+	t.Run("clients", func(t *testing.T) {
+		cr := &unstructured.Unstructured{}
+		cr.SetAPIVersion("cache.example.com/v1alpha1")
+		cr.SetKind("Memcached")
+		cr.SetNamespace("scorecard")
+		cr.SetName("memcached-sample")
+		pod := &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "scorecard", Name: "memcached"}}
+		f := FakeRunner{
+			Bundle:    &apimanifests.Bundle{CSV: &v1alpha1.ClusterServiceVersion{}},
+			Namespace: "scorecard",
+			Objects:   []runtime.Object{cr, pod},
+		}
+
+		env, err := f.Env()
+		require.NoError(t, err)
+		assert.Equal(t, "scorecard", env.Client.Namespace)
+		_, err = env.Client.Kube.CoreV1().Pods(env.Client.Namespace).Get(ctx, "memcached", metav1.GetOptions{})
+		assert.NoError(t, err)
+		gvr := schema.GroupVersionResource{Group: "cache.example.com", Version: "v1alpha1", Resource: "memcacheds"}
+		_, err = env.Client.Resource(gvr).Get(ctx, "memcached-sample", metav1.GetOptions{})
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+)
+
+// Env is what a test runs against.
+type Env struct {
+	// BundleRoot is the directory Bundle was read from.
+	BundleRoot string
+	// Bundle is the bundle under test.
+	Bundle *apimanifests.Bundle
+	// Client is a client for the namespace the test runs in.
+	Client *Client
+}
+
+// TestFunc is a custom test. Its status usually has a result named after the test.
+type TestFunc func(ctx context.Context, env Env) scapiv1alpha3.TestStatus
+
+// Registry holds the tests of a test image by name.
+type Registry struct {
+	names []string
+	tests map[string]TestFunc
+	// newClient returns the client tests run with in Execute.
+	newClient func() (*Client, error)
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{tests: make(map[string]TestFunc), newClient: NewInClusterClient}
+}
+
+// Register adds test to r with name, which panics if r already has a test named name.
+func (r *Registry) Register(name string, test TestFunc) {
+	if _, ok := r.tests[name]; ok {
+		panic(fmt.Sprintf("scorecard: test %q registered twice", name))
+	}
+	r.names = append(r.names, name)
+	r.tests[name] = test
+}
+
+// Names returns the names of the tests of r in the order they were registered.
+func (r *Registry) Names() []string {
+	return append([]string(nil), r.names...)
+}
+
+// Run runs the test named name against env. ok is false if r has no test named name.
+func (r *Registry) Run(ctx context.Context, name string, env Env) (status scapiv1alpha3.TestStatus, ok bool) {
+	test, ok := r.tests[name]
+	if !ok {
+		return scapiv1alpha3.TestStatus{}, false
+	}
+	return test(ctx, env), true
+}
+
+// Execute runs the test named by args[0], the entrypoint arguments of the
+// test image, against the bundle in bundleRoot and writes its status to out
+// as JSON. If args names no test of r, the status is a failure that lists the
+// valid tests. Execute returns an error if it cannot get an in-cluster client.
+func (r *Registry) Execute(ctx context.Context, args []string, bundleRoot string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("test name argument is required")
+	}
+
+	bundle, err := LoadBundle(bundleRoot)
+	if err != nil {
+		return err
+	}
+	client, err := r.newClient()
+	if err != nil {
+		return err
+	}
+	env := Env{BundleRoot: bundleRoot, Bundle: bundle, Client: client}
+
+	status, ok := r.Run(ctx, args[0], env)
+	if !ok {
+		status = r.validTests()
+	}
+
+	prettyJSON, err := json.MarshalIndent(status, "", "    ")
+	if err != nil {
+		return fmt.Errorf("failed to generate json: %v", err)
+	}
+	_, err = fmt.Fprintf(out, "%s\n", prettyJSON)
+	return err
+}
+
+// Main is the main function of a test image: it executes the test named by
+// the image's arguments against the bundle mounted at PodBundleRoot.
+func (r *Registry) Main() {
+	if err := r.Execute(context.Background(), os.Args[1:], PodBundleRoot, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// validTests returns a failure that lists the tests of r, to give a hint to
+// the end user on what the valid tests are.
+func (r *Registry) validTests() scapiv1alpha3.TestStatus {
+	result := scapiv1alpha3.TestResult{}
+	result.State = scapiv1alpha3.FailState
+	result.Errors = []string{fmt.Sprintf("Valid tests for this image include: %s", strings.Join(r.names, ", "))}
+	result.Suggestions = make([]string, 0)
+	return WrapResult(result)
+}
@@ -0,0 +1,114 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+	apimanifests "github.com/operator-framework/api/pkg/manifests"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testBundleRoot = "../../internal/scorecard/testdata/bundle"
+
+func countCRs(ctx context.Context, env Env) scapiv1alpha3.TestStatus {
+	crs, err := GetCRs(env.Bundle)
+	if err != nil {
+		return WrapResult(ErrorResult("count-crs", err))
+	}
+	r := NewResult("count-crs")
+	if len(crs) == 0 {
+		r.State = scapiv1alpha3.FailState
+		r.Errors = append(r.Errors, "no CRs in alm-examples")
+	}
+	return WrapResult(r)
+}
+
+func newTestRegistry() *Registry {
+	registry := NewRegistry()
+	registry.Register("count-crs", countCRs)
+	registry.Register("always-pass", func(context.Context, Env) scapiv1alpha3.TestStatus {
+		return WrapResult(NewResult("always-pass"))
+	})
+	registry.newClient = func() (*Client, error) {
+		return &Client{Namespace: "default"}, nil
+	}
+	return registry
+}
+
+func TestRegistry(t *testing.T) {
+	registry := newTestRegistry()
+	assert.Equal(t, []string{"count-crs", "always-pass"}, registry.Names())
+	assert.Panics(t, func() { registry.Register("count-crs", countCRs) })
+
+	_, ok := registry.Run(context.Background(), "missing", Env{})
+	assert.False(t, ok)
+}
+
+func TestExecute(t *testing.T) {
+	registry := newTestRegistry()
+
+	t.Run("known test", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, registry.Execute(context.Background(), []string{"count-crs"}, testBundleRoot, &out))
+		var status scapiv1alpha3.TestStatus
+		require.NoError(t, json.Unmarshal(out.Bytes(), &status))
+		require.Len(t, status.Results, 1)
+		assert.Equal(t, "count-crs", status.Results[0].Name)
+		assert.Equal(t, scapiv1alpha3.PassState, status.Results[0].State)
+	})
+
+	t.Run("unknown test", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, registry.Execute(context.Background(), []string{"missing"}, testBundleRoot, &out))
+		var status scapiv1alpha3.TestStatus
+		require.NoError(t, json.Unmarshal(out.Bytes(), &status))
+		require.Len(t, status.Results, 1)
+		assert.Equal(t, scapiv1alpha3.FailState, status.Results[0].State)
+		assert.Equal(t, []string{"Valid tests for this image include: count-crs, always-pass"}, status.Results[0].Errors)
+	})
+
+	t.Run("no test", func(t *testing.T) {
+		err := registry.Execute(context.Background(), nil, testBundleRoot, &bytes.Buffer{})
+		assert.EqualError(t, err, "test name argument is required")
+	})
+
+	t.Run("no bundle", func(t *testing.T) {
+		err := registry.Execute(context.Background(), []string{"count-crs"}, "testdata/missing", &bytes.Buffer{})
+		assert.Error(t, err)
+	})
+
+	t.Run("no client", func(t *testing.T) {
+		registry := newTestRegistry()
+		registry.newClient = func() (*Client, error) {
+			return nil, errors.New("not in a cluster")
+		}
+		var out bytes.Buffer
+		err := registry.Execute(context.Background(), []string{"count-crs"}, testBundleRoot, &out)
+		assert.EqualError(t, err, "not in a cluster")
+		assert.Zero(t, out.Len())
+	})
+}
+
+func TestGetCRsWithoutCSV(t *testing.T) {
+	_, err := GetCRs(&apimanifests.Bundle{})
+	assert.EqualError(t, err, "bundle has no ClusterServiceVersion")
+}
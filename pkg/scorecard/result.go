@@ -0,0 +1,45 @@
+// Copyright 2021 The Operator-SDK Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorecard
+
+import (
+	scapiv1alpha3 "github.com/operator-framework/api/pkg/apis/scorecard/v1alpha3"
+)
+
+// NewResult returns a passing result of the test named name, to which a test
+// adds errors and suggestions and whose state it sets if the test does not pass.
+func NewResult(name string) scapiv1alpha3.TestResult {
+	return scapiv1alpha3.TestResult{
+		Name:        name,
+		State:       scapiv1alpha3.PassState,
+		Errors:      make([]string, 0),
+		Suggestions: make([]string, 0),
+	}
+}
+
+// ErrorResult returns the result of the test named name that could not run because of err.
+func ErrorResult(name string, err error) scapiv1alpha3.TestResult {
+	r := NewResult(name)
+	r.State = scapiv1alpha3.ErrorState
+	r.Errors = append(r.Errors, err.Error())
+	return r
+}
+
+// WrapResult returns the status of a test with results.
+func WrapResult(results ...scapiv1alpha3.TestResult) scapiv1alpha3.TestStatus {
+	return scapiv1alpha3.TestStatus{
+		Results: results,
+	}
+}